@@ -0,0 +1,164 @@
+// Command dalgen generates the dal, the api package with its DAO interface and handlers, and their tests
+// from an annotated model struct.  It is run by go generate from the model file:
+//
+//	//go:generate go run github.com/g8rswimmer/go-data-access-example/cmd/dalgen -type Widget
+//
+//	//dalgen:entity table=widget path=widgets order=name
+//	type Widget struct {
+//		Name string `json:"name"`
+//	}
+//
+// The options of the annotation are optional.  Generating again writes only the files that changed
+// and a file that was not generated is never overwritten.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+func main() {
+	typeName := flag.String("type", "", "the model struct to generate from")
+	file := flag.String("file", os.Getenv("GOFILE"), "the model file, the file of the go:generate directive when empty")
+	root := flag.String("root", "", "the module root that the files are written under, found from the model file when empty")
+	flag.Parse()
+
+	written, err := run(*typeName, *file, *root)
+	for _, w := range written {
+		fmt.Println("dalgen: wrote", w)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "dalgen:", err)
+		os.Exit(1)
+	}
+}
+
+// output is a generated file
+type output struct {
+	path     string
+	template *template.Template
+}
+
+// run generates the files of the type and returns the files that were written
+func run(typeName, file, root string) ([]string, error) {
+	switch {
+	case len(typeName) == 0:
+		return nil, errors.New("-type is required")
+	case len(file) == 0:
+		return nil, errors.New("-file is required when not run by go generate")
+	}
+	file, err := filepath.Abs(file)
+	if err != nil {
+		return nil, err
+	}
+	if len(root) == 0 {
+		if root, err = moduleRoot(filepath.Dir(file)); err != nil {
+			return nil, err
+		}
+	}
+	module, err := modulePath(root)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := parse(file, typeName, module)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := []output{
+		{path: filepath.Join("pkg", "errorx", e.Snake+".go"), template: errorxTemplate},
+		{path: filepath.Join("pkg", "dal", e.Snake+".go"), template: dalTemplate},
+		{path: filepath.Join("pkg", "api", e.Package, "handlers.go"), template: handlersTemplate},
+		{path: filepath.Join("pkg", "api", e.Package, "mock_test.go"), template: mockTemplate},
+		{path: filepath.Join("pkg", "api", e.Package, "handlers_test.go"), template: testTemplate},
+	}
+	if e.HasEntity == false {
+		outputs = append([]output{{path: filepath.Join("pkg", "model", e.entityFile()), template: entityTemplate}}, outputs...)
+	}
+
+	written := []string{}
+	for _, o := range outputs {
+		changed, err := generate(filepath.Join(root, o.path), o.template, e)
+		if err != nil {
+			return written, fmt.Errorf("%s: %w", o.path, err)
+		}
+		if changed {
+			written = append(written, o.path)
+		}
+	}
+	return written, nil
+}
+
+// generate writes the formatted template to the path when it differs from the file, a file that
+// was not generated is not overwritten
+func generate(path string, t *template.Template, e *entity) (bool, error) {
+	buf := &bytes.Buffer{}
+	if err := t.Execute(buf, e); err != nil {
+		return false, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return false, fmt.Errorf("generated source is not valid: %w", err)
+	}
+
+	existing, err := ioutil.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+	case err != nil:
+		return false, err
+	case bytes.Equal(existing, src):
+		return false, nil
+	case bytes.HasPrefix(existing, []byte("// Code generated by dalgen")) == false:
+		return false, errors.New("the file exists and was not generated, it is not overwritten")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return false, err
+	}
+	return true, ioutil.WriteFile(path, src, 0644)
+}
+
+// moduleRoot returns the nearest directory with a go.mod
+func moduleRoot(dir string) (string, error) {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", errors.New("go.mod was not found above the model file")
+		}
+		dir = parent
+	}
+}
+
+// modulePath returns the module path of the go.mod in the root
+func modulePath(root string) (string, error) {
+	f, err := os.Open(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "module ") {
+			return strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "module")), `"`), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", errors.New("go.mod does not have a module path")
+}
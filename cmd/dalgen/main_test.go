@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func testRoot(t *testing.T) string {
+	t.Helper()
+	root, err := ioutil.TempDir("", "dalgen")
+	if err != nil {
+		t.Fatalf("temp dir error = %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/widgets\n\ngo 1.13\n"), 0644); err != nil {
+		t.Fatalf("go.mod error = %v", err)
+	}
+	return root
+}
+
+func TestRun(t *testing.T) {
+	root := testRoot(t)
+	defer os.RemoveAll(root)
+
+	written, err := run("Widget", filepath.Join("testdata", "model", "widget.go"), root)
+	if err != nil {
+		t.Errorf("run() error = %v", err)
+		return
+	}
+	want := []string{
+		"pkg/model/widget_entity.go",
+		"pkg/errorx/widget.go",
+		"pkg/dal/widget.go",
+		"pkg/api/widget/handlers.go",
+		"pkg/api/widget/mock_test.go",
+		"pkg/api/widget/handlers_test.go",
+	}
+	for i := range want {
+		want[i] = filepath.FromSlash(want[i])
+	}
+	if reflect.DeepEqual(written, want) == false {
+		t.Errorf("run() = %v, want %v", written, want)
+		return
+	}
+
+	for _, w := range written {
+		got, err := ioutil.ReadFile(filepath.Join(root, w))
+		if err != nil {
+			t.Errorf("run() read error = %v", err)
+			continue
+		}
+		golden := filepath.Join("testdata", "golden", strings.Replace(filepath.ToSlash(w), "/", "_", -1)+".golden")
+		if *update {
+			if err := ioutil.WriteFile(golden, got, 0644); err != nil {
+				t.Errorf("golden write error = %v", err)
+			}
+			continue
+		}
+		expected, err := ioutil.ReadFile(golden)
+		if err != nil {
+			t.Errorf("golden read error = %v", err)
+			continue
+		}
+		if bytes.Equal(got, expected) == false {
+			t.Errorf("run() %s differs from %s, run go test ./cmd/dalgen -update", w, golden)
+		}
+	}
+
+	written, err = run("Widget", filepath.Join("testdata", "model", "widget.go"), root)
+	if err != nil || len(written) != 0 {
+		t.Errorf("run() again = %v, %v want no files written", written, err)
+	}
+}
+
+// moduleCopy copies the go.mod and the packages, without their tests, of this module to a temporary root
+func moduleCopy(t *testing.T) string {
+	t.Helper()
+	root, err := ioutil.TempDir("", "dalgen")
+	if err != nil {
+		t.Fatalf("temp dir error = %v", err)
+	}
+	module := filepath.Join("..", "..")
+	copyFile := func(path string) error {
+		rel, err := filepath.Rel(module, path)
+		if err != nil {
+			return err
+		}
+		src, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Join(root, filepath.Dir(rel)), 0755); err != nil {
+			return err
+		}
+		return ioutil.WriteFile(filepath.Join(root, rel), src, 0644)
+	}
+	for _, f := range []string{"go.mod", "go.sum"} {
+		if err := copyFile(filepath.Join(module, f)); err != nil {
+			os.RemoveAll(root)
+			t.Fatalf("copy %s error = %v", f, err)
+		}
+	}
+	err = filepath.Walk(filepath.Join(module, "pkg"), func(path string, info os.FileInfo, err error) error {
+		switch {
+		case err != nil:
+			return err
+		case info.IsDir(), filepath.Ext(path) != ".go", strings.HasSuffix(path, "_test.go"):
+			return nil
+		}
+		return copyFile(path)
+	})
+	if err != nil {
+		os.RemoveAll(root)
+		t.Fatalf("copy pkg error = %v", err)
+	}
+	return root
+}
+
+func TestRun_Compiles(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a copy of the module")
+	}
+	gobin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go is not installed")
+	}
+	root := moduleCopy(t)
+	defer os.RemoveAll(root)
+
+	// the generated files are built and tested against the dal, errorx and model packages of this module
+	src, err := ioutil.ReadFile(filepath.Join("testdata", "model", "widget.go"))
+	if err != nil {
+		t.Fatalf("model read error = %v", err)
+	}
+	file := filepath.Join(root, "pkg", "model", "widget.go")
+	if err := ioutil.WriteFile(file, src, 0644); err != nil {
+		t.Fatalf("model write error = %v", err)
+	}
+	if _, err := run("Widget", file, root); err != nil {
+		t.Errorf("run() error = %v", err)
+		return
+	}
+
+	for _, args := range [][]string{{"vet", "./..."}, {"test", "./pkg/api/widget"}} {
+		cmd := exec.Command(gobin, args...)
+		cmd.Dir = root
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Errorf("go %s error = %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+}
+
+func TestRun_Errors(t *testing.T) {
+	root := testRoot(t)
+	defer os.RemoveAll(root)
+
+	handwritten := filepath.Join(root, "pkg", "api", "widget", "handlers.go")
+	if err := os.MkdirAll(filepath.Dir(handwritten), 0755); err != nil {
+		t.Fatalf("mkdir error = %v", err)
+	}
+	if err := ioutil.WriteFile(handwritten, []byte("package widget\n"), 0644); err != nil {
+		t.Fatalf("write error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		typeName string
+		file     string
+	}{
+		{name: "no type", file: filepath.Join("testdata", "model", "widget.go")},
+		{name: "not declared", typeName: "Gadget", file: filepath.Join("testdata", "model", "widget.go")},
+		{name: "not a struct", typeName: "WidgetSize", file: filepath.Join("testdata", "model", "widget.go")},
+		{name: "hand written file", typeName: "Widget", file: filepath.Join("testdata", "model", "widget.go")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := run(tt.typeName, tt.file, root); err == nil {
+				t.Errorf("run() error = nil, want an error")
+			}
+		})
+	}
+	if got, _ := ioutil.ReadFile(handwritten); string(got) != "package widget\n" {
+		t.Errorf("run() overwrote %s", handwritten)
+	}
+}
+
+func TestSnake(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "Widget", want: "widget"},
+		{name: "GiftCard", want: "gift_card"},
+		{name: "UserID", want: "user_id"},
+		{name: "HTTPServer", want: "http_server"},
+		{name: "Base64Key", want: "base64_key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := snake(tt.name); got != tt.want {
+				t.Errorf("snake() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
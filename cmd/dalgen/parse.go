@@ -0,0 +1,320 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// annotation marks the model struct, its options are space separated key=value pairs
+const annotation = "//dalgen:entity"
+
+// entity is the model that the files are generated from
+type entity struct {
+	// Module is the module path of the repository
+	Module string
+	// Source is the model file name
+	Source string
+	// Type is the model struct name, such as GiftCard
+	Type string
+	// Var is the model variable name, such as giftCard
+	Var string
+	// Receiver is the receiver name of the dal methods
+	Receiver string
+	// Snake is the file and label name, such as gift_card
+	Snake string
+	// Package is the api package name, such as giftcard
+	Package string
+	// Words is the name used in messages, such as gift card
+	Words string
+	// Table is the table name, the snake name unless annotated with table=
+	Table string
+	// Path is the route path, the plural kebab name such as gift-cards unless annotated with path=
+	Path string
+	// Order is the list order, the created at column unless annotated with order=
+	Order string
+	// Validate is true when the model has a Validate method
+	Validate bool
+	// HasEntity is true when the model package already declares the entity type
+	HasEntity bool
+	Fields    []field
+}
+
+// field is a stored field of the model
+type field struct {
+	Name      string
+	Column    string
+	JSON      string
+	Kind      string
+	NullEmpty bool
+}
+
+// Definition returns the column definition of the table
+func (f field) Definition() string {
+	switch {
+	case f.NullEmpty:
+		return "TEXT"
+	case f.Kind == "string":
+		return "TEXT NOT NULL DEFAULT ''"
+	case f.Kind == "int":
+		return "INTEGER NOT NULL DEFAULT 0"
+	case f.Kind == "float":
+		return "REAL NOT NULL DEFAULT 0"
+	case f.Kind == "bool":
+		return "BOOLEAN NOT NULL DEFAULT 0"
+	case f.Kind == "time":
+		return "DATETIME"
+	default:
+		return "TEXT"
+	}
+}
+
+// Sample returns a json value of the field for the generated tests, empty when there is none
+func (f field) Sample() string {
+	switch f.Kind {
+	case "string":
+		return strconv.Quote("test")
+	case "int":
+		return "1"
+	case "float":
+		return "1.5"
+	case "bool":
+		return "true"
+	default:
+		return ""
+	}
+}
+
+// SampleBody returns a json body of the sample values
+func (e *entity) SampleBody() string {
+	pairs := []string{}
+	for _, f := range e.Fields {
+		if s := f.Sample(); len(s) > 0 && len(f.JSON) > 0 {
+			pairs = append(pairs, strconv.Quote(f.JSON)+":"+s)
+		}
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// parse returns the entity of the annotated model struct in the file
+func parse(file, typeName, module string) (*entity, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, filepath.Dir(file), nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &entity{
+		Module: module,
+		Source: filepath.Base(file),
+		Type:   typeName,
+	}
+	named := map[string]ast.Expr{}
+	var model *ast.StructType
+	var options string
+	for _, pkg := range pkgs {
+		for name, f := range pkg.Files {
+			for _, decl := range f.Decls {
+				switch d := decl.(type) {
+				case *ast.GenDecl:
+					for _, spec := range d.Specs {
+						ts, ok := spec.(*ast.TypeSpec)
+						if ok == false {
+							continue
+						}
+						named[ts.Name.Name] = ts.Type
+						if ts.Name.Name == typeName+"Entity" && filepath.Base(name) != e.entityFile() {
+							e.HasEntity = true
+						}
+						if ts.Name.Name != typeName || filepath.Base(name) != e.Source {
+							continue
+						}
+						st, ok := ts.Type.(*ast.StructType)
+						if ok == false {
+							return nil, fmt.Errorf("%s is not a struct", typeName)
+						}
+						model = st
+						options, ok = annotated(d.Doc, ts.Doc)
+						if ok == false {
+							return nil, fmt.Errorf("%s is not annotated with %s", typeName, annotation)
+						}
+					}
+				case *ast.FuncDecl:
+					if d.Name.Name == "Validate" && receiverType(d) == typeName {
+						e.Validate = true
+					}
+				}
+			}
+		}
+	}
+	if model == nil {
+		return nil, fmt.Errorf("%s is not declared in %s", typeName, file)
+	}
+
+	e.Snake = snake(typeName)
+	e.Var = string(unicode.ToLower(rune(typeName[0]))) + typeName[1:]
+	e.Receiver = string(unicode.ToLower(rune(typeName[0])))
+	e.Package = strings.Replace(e.Snake, "_", "", -1)
+	e.Words = strings.Replace(e.Snake, "_", " ", -1)
+	e.Table = e.Snake
+	e.Path = strings.Replace(e.Snake, "_", "-", -1) + "s"
+	e.Order = "created_at"
+	for _, opt := range strings.Fields(options) {
+		kv := strings.SplitN(opt, "=", 2)
+		if len(kv) != 2 || len(kv[1]) == 0 {
+			return nil, fmt.Errorf("%s option %q is not key=value", annotation, opt)
+		}
+		switch kv[0] {
+		case "table":
+			e.Table = kv[1]
+		case "path":
+			e.Path = kv[1]
+		case "order":
+			e.Order = kv[1]
+		default:
+			return nil, fmt.Errorf("%s option %q is not one of table, path or order", annotation, kv[0])
+		}
+	}
+
+	for _, f := range model.Fields.List {
+		if len(f.Names) == 0 {
+			return nil, fmt.Errorf("%s embeds a field, only named fields are generated", typeName)
+		}
+		tag := reflect.StructTag("")
+		if f.Tag != nil {
+			tag = reflect.StructTag(strings.Trim(f.Tag.Value, "`"))
+		}
+		for _, n := range f.Names {
+			if n.IsExported() == false {
+				continue
+			}
+			fd, ok := columnOf(n.Name, tag)
+			if ok == false {
+				continue
+			}
+			fd.Kind = kindOf(f.Type, named)
+			if fd.NullEmpty && fd.Kind != "string" {
+				return nil, fmt.Errorf("%s.%s is nullempty but not a string", typeName, n.Name)
+			}
+			e.Fields = append(e.Fields, fd)
+		}
+	}
+	if len(e.Fields) == 0 {
+		return nil, fmt.Errorf("%s has no stored fields", typeName)
+	}
+	return e, nil
+}
+
+// annotated returns the options of the annotation in the comments
+func annotated(docs ...*ast.CommentGroup) (string, bool) {
+	for _, doc := range docs {
+		if doc == nil {
+			continue
+		}
+		for _, c := range doc.List {
+			if c.Text == annotation || strings.HasPrefix(c.Text, annotation+" ") {
+				return strings.TrimPrefix(c.Text, annotation), true
+			}
+		}
+	}
+	return "", false
+}
+
+func receiverType(d *ast.FuncDecl) string {
+	if d.Recv == nil || len(d.Recv.List) != 1 {
+		return ""
+	}
+	t := d.Recv.List[0].Type
+	if star, ok := t.(*ast.StarExpr); ok {
+		t = star.X
+	}
+	if ident, ok := t.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+// columnOf returns the column of the field with the same rules as dal.Repository
+func columnOf(name string, tag reflect.StructTag) (field, bool) {
+	json := strings.Split(tag.Get("json"), ",")[0]
+	db := tag.Get("db")
+	column, opts := db, ""
+	if comma := strings.Index(db, ","); comma >= 0 {
+		column, opts = db[:comma], db[comma+1:]
+	}
+	if len(column) == 0 {
+		column = json
+	}
+	if column == "-" || len(column) == 0 {
+		return field{}, false
+	}
+	if json == "-" {
+		json = ""
+	}
+	return field{
+		Name:      name,
+		Column:    column,
+		JSON:      json,
+		NullEmpty: opts == "nullempty",
+	}, true
+}
+
+// kindOf returns the kind of the field type, named types of the package are resolved to their underlying type
+func kindOf(expr ast.Expr, named map[string]ast.Expr) string {
+	for i := 0; i < 8; i++ {
+		switch t := expr.(type) {
+		case *ast.Ident:
+			switch t.Name {
+			case "string":
+				return "string"
+			case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+				return "int"
+			case "float32", "float64":
+				return "float"
+			case "bool":
+				return "bool"
+			}
+			underlying, ok := named[t.Name]
+			if ok == false {
+				return "other"
+			}
+			expr = underlying
+		case *ast.SelectorExpr:
+			if pkg, ok := t.X.(*ast.Ident); ok && pkg.Name == "time" && t.Sel.Name == "Time" {
+				return "time"
+			}
+			return "other"
+		default:
+			return "other"
+		}
+	}
+	return "other"
+}
+
+// snake returns the snake case of the camel case name, GiftCard is gift_card and UserID is user_id
+func snake(name string) string {
+	runes := []rune(name)
+	b := strings.Builder{}
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteRune('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// entityFile is the generated file of the entity type
+func (e *entity) entityFile() string {
+	return snake(e.Type) + "_entity.go"
+}
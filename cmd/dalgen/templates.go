@@ -0,0 +1,541 @@
+package main
+
+import "text/template"
+
+// header marks a generated file, a file without it is never overwritten
+const header = "// Code generated by dalgen from {{.Source}}. DO NOT EDIT.\n\n"
+
+var entityTemplate = template.Must(template.New("entity").Parse(header + `package model
+
+// {{.Type}}Entity is the {{.Words}} entity for the database
+type {{.Type}}Entity struct {
+	Entity
+	{{.Type}}
+}
+`))
+
+var errorxTemplate = template.Must(template.New("errorx").Parse(header + `package errorx
+
+import "errors"
+
+var (
+	// ErrNo{{.Type}} when no {{.Words}} entity is found
+	ErrNo{{.Type}} = New(ErrNotFound, "{{.Words}} is not present")
+	// ErrDelete{{.Type}} when the {{.Words}} has been deleted
+	ErrDelete{{.Type}} = errors.New("{{.Words}} has been deleted")
+)
+`))
+
+var dalTemplate = template.Must(template.New("dal").Parse(header + `package dal
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"{{.Module}}/pkg/errorx"
+	"{{.Module}}/pkg/metrics"
+	"{{.Module}}/pkg/model"
+)
+
+// {{.Type}}Table defines the {{.Words}} table, add it to Tables to migrate it
+const {{.Type}}Table = ` + "`" + `
+CREATE TABLE IF NOT EXISTS {{.Table}} (
+	id CHAR(36) NOT NULL,
+{{- range .Fields}}
+	{{.Column}} {{.Definition}},
+{{- end}}
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	deleted_at DATETIME,
+	PRIMARY KEY (id)
+);
+` + "`" + `
+
+// {{.Type}} handles all of the {{.Words}} database actions
+type {{.Type}} struct {
+	DB            *sql.DB
+	GenerateUUID  GenerateUUID
+	QueryDuration *metrics.HistogramVec
+}
+
+// repository handles the {{.Words}} entity actions
+func ({{.Receiver}} *{{.Type}}) repository() *Repository {
+	return &Repository{
+		DB:            {{.Receiver}}.DB,
+		GenerateUUID:  {{.Receiver}}.GenerateUUID,
+		QueryDuration: {{.Receiver}}.QueryDuration,
+		Name:          "{{.Snake}}",
+		Table:         "{{.Table}}",
+		Order:         "{{.Order}}",
+		NoEntity:      errorx.ErrNo{{.Type}},
+		DeletedEntity: errorx.ErrDelete{{.Type}},
+	}
+}
+
+// Create will insert a {{.Words}} into the database
+func ({{.Receiver}} *{{.Type}}) Create(ctx context.Context, {{.Var}} *model.{{.Type}}) (*model.{{.Type}}Entity, error) {
+	if {{.Var}} == nil {
+		return nil, errors.New("{{.Words}} can not be nil")
+	}
+
+	e := &model.{{.Type}}Entity{ {{- .Type}}: *{{.Var}}}
+	if err := {{.Receiver}}.repository().Create(ctx, e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// FetchByID returns a {{.Words}} by the id
+func ({{.Receiver}} *{{.Type}}) FetchByID(ctx context.Context, id string) (*model.{{.Type}}Entity, error) {
+	e := &model.{{.Type}}Entity{}
+	if err := {{.Receiver}}.repository().FetchByID(ctx, id, e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// FetchAll returns all of the {{.Words}} entities that have not been deleted
+func ({{.Receiver}} *{{.Type}}) FetchAll(ctx context.Context) ([]*model.{{.Type}}Entity, error) {
+	entities := []*model.{{.Type}}Entity{}
+	if err := {{.Receiver}}.repository().List(ctx, &entities); err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+// Update will update a {{.Words}} with new information
+func ({{.Receiver}} *{{.Type}}) Update(ctx context.Context, id string, {{.Var}} *model.{{.Type}}) (*model.{{.Type}}Entity, error) {
+	if {{.Var}} == nil {
+		return nil, errors.New("{{.Words}} can not be nil")
+	}
+
+	e := &model.{{.Type}}Entity{}
+	if err := {{.Receiver}}.repository().Update(ctx, id, {{.Var}}, e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Delete will soft delete a {{.Words}}
+func ({{.Receiver}} *{{.Type}}) Delete(ctx context.Context, id string) error {
+	return {{.Receiver}}.repository().SoftDelete(ctx, id)
+}
+`))
+
+var handlersTemplate = template.Must(template.New("handlers").Parse(header + `package {{.Package}}
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"{{.Module}}/pkg/api/request"
+	"{{.Module}}/pkg/api/response"
+	"{{.Module}}/pkg/errorx"
+	"{{.Module}}/pkg/logx"
+	"{{.Module}}/pkg/model"
+	"github.com/gorilla/mux"
+)
+
+// DAO is the {{.Words}} data access object
+type DAO interface {
+	Create(ctx context.Context, {{.Var}} *model.{{.Type}}) (*model.{{.Type}}Entity, error)
+	FetchByID(ctx context.Context, id string) (*model.{{.Type}}Entity, error)
+	FetchAll(ctx context.Context) ([]*model.{{.Type}}Entity, error)
+	Update(ctx context.Context, id string, {{.Var}} *model.{{.Type}}) (*model.{{.Type}}Entity, error)
+	Delete(ctx context.Context, id string) error
+}
+
+const {{.Var}}ID = "id"
+
+type errorMessage struct {
+	ID      string ` + "`json:\"id,omitempty\"`" + `
+	Error   string ` + "`json:\"error,omitempty\"`" + `
+	Message string ` + "`json:\"message,omitempty\"`" + `
+}
+
+// Handler provides all of the {{.Words}} handlers
+type Handler struct {
+	{{.Type}}DAO DAO
+}
+
+// decodeError responds to a body that could not be decoded
+func decodeError(w http.ResponseWriter, r *http.Request, err error) {
+	logx.FromContext(r.Context()).Debug("{{.Words}} decode error", logx.Fields{"error": err})
+	msg := &errorMessage{
+		Error:   err.Error(),
+		Message: "{{.Words}} decode error",
+	}
+	response.Write(w, r, request.Status(err), msg)
+}
+
+// datastoreError responds with the status of the error class, the {{.Words}} errors name the {{.Words}}
+func datastoreError(w http.ResponseWriter, r *http.Request, err error) {
+	id := mux.Vars(r)[{{.Var}}ID]
+	status := response.Status(err)
+	switch {
+	case errors.Is(err, errorx.ErrNo{{.Type}}):
+		response.Write(w, r, status, &errorMessage{ID: id, Message: fmt.Sprintf("{{.Words}} %s does not exist", id)})
+		return
+	case errors.Is(err, errorx.ErrDelete{{.Type}}):
+		response.Write(w, r, http.StatusGone, &errorMessage{ID: id, Message: fmt.Sprintf("{{.Words}} %s has been deleted", id)})
+		return
+	}
+
+	fields := logx.Fields{"error": err, "status": status}
+	if status >= http.StatusInternalServerError {
+		logx.FromContext(r.Context()).Error("{{.Words}} datastore error", fields)
+	} else {
+		logx.FromContext(r.Context()).Debug("{{.Words}} datastore error", fields)
+	}
+	if status == http.StatusServiceUnavailable {
+		w.Header().Set("Retry-After", "1")
+	}
+	msg := &errorMessage{
+		Error:   err.Error(),
+		Message: "{{.Words}} datastore error",
+	}
+	response.Write(w, r, status, msg)
+}
+
+// create handles the {{.Words}} create request
+func (h *Handler) create() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		{{.Var}} := &model.{{.Type}}{}
+		if err := request.Decode(r, {{.Var}}); err != nil {
+			decodeError(w, r, err)
+			return
+		}
+{{- if .Validate}}
+		if err := {{.Var}}.Validate(); err != nil {
+			msg := &errorMessage{
+				Error:   err.Error(),
+				Message: "{{.Words}} validation error",
+			}
+			response.Write(w, r, http.StatusBadRequest, msg)
+			return
+		}
+{{- end}}
+
+		entity, err := h.{{.Type}}DAO.Create(r.Context(), {{.Var}})
+		if err != nil {
+			datastoreError(w, r, err)
+			return
+		}
+		response.Write(w, r, http.StatusCreated, entity)
+	}
+}
+
+// fetchByID will return a {{.Words}} by its id
+func (h *Handler) fetchByID() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		entity, err := h.{{.Type}}DAO.FetchByID(r.Context(), mux.Vars(r)[{{.Var}}ID])
+		if err != nil {
+			datastoreError(w, r, err)
+			return
+		}
+		response.Write(w, r, http.StatusOK, entity)
+	}
+}
+
+// list will return all of the {{.Words}} entities
+func (h *Handler) list() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		entities, err := h.{{.Type}}DAO.FetchAll(r.Context())
+		if err != nil {
+			datastoreError(w, r, err)
+			return
+		}
+		response.Write(w, r, http.StatusOK, entities)
+	}
+}
+
+// update will return the updated {{.Words}}
+func (h *Handler) update() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		{{.Var}} := &model.{{.Type}}{}
+		if err := request.Decode(r, {{.Var}}); err != nil {
+			decodeError(w, r, err)
+			return
+		}
+		if reflect.DeepEqual({{.Var}}, &model.{{.Type}}{}) {
+			msg := &errorMessage{
+				Message: "{{.Words}} must have fields to update",
+			}
+			response.Write(w, r, http.StatusBadRequest, msg)
+			return
+		}
+{{- if .Validate}}
+		if err := {{.Var}}.Validate(); err != nil {
+			msg := &errorMessage{
+				Error:   err.Error(),
+				Message: "{{.Words}} validation error",
+			}
+			response.Write(w, r, http.StatusBadRequest, msg)
+			return
+		}
+{{- end}}
+
+		entity, err := h.{{.Type}}DAO.Update(r.Context(), mux.Vars(r)[{{.Var}}ID], {{.Var}})
+		if err != nil {
+			datastoreError(w, r, err)
+			return
+		}
+		response.Write(w, r, http.StatusOK, entity)
+	}
+}
+
+// delete will remove the {{.Words}}
+func (h *Handler) delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := h.{{.Type}}DAO.Delete(r.Context(), mux.Vars(r)[{{.Var}}ID]); err != nil {
+			datastoreError(w, r, err)
+			return
+		}
+		response.Write(w, r, http.StatusNoContent, nil)
+	}
+}
+
+// Add will configure the routes for {{.Words}} operations
+func (h *Handler) Add(router *mux.Router) {
+	router.Methods(http.MethodPost).Path("/{{.Path}}").Handler(h.create()).Name("{{.Path}}-create")
+	router.Methods(http.MethodGet).Path("/{{.Path}}").Handler(h.list()).Name("{{.Path}}-fetch-all")
+	router.Methods(http.MethodGet).Path(fmt.Sprintf("/{{.Path}}/{%s}", {{.Var}}ID)).Handler(h.fetchByID()).Name("{{.Path}}-fetch")
+	router.Methods(http.MethodPatch).Path(fmt.Sprintf("/{{.Path}}/{%s}", {{.Var}}ID)).Handler(h.update()).Name("{{.Path}}-update")
+	router.Methods(http.MethodDelete).Path(fmt.Sprintf("/{{.Path}}/{%s}", {{.Var}}ID)).Handler(h.delete()).Name("{{.Path}}-delete")
+}
+`))
+
+var mockTemplate = template.Must(template.New("mock").Parse(header + `package {{.Package}}
+
+import (
+	"context"
+
+	"{{.Module}}/pkg/model"
+)
+
+type mock{{.Type}}DAO struct {
+	entity   *model.{{.Type}}Entity
+	entities []*model.{{.Type}}Entity
+	err      error
+}
+
+func (m *mock{{.Type}}DAO) Create(ctx context.Context, {{.Var}} *model.{{.Type}}) (*model.{{.Type}}Entity, error) {
+	return m.entity, m.err
+}
+
+func (m *mock{{.Type}}DAO) FetchByID(ctx context.Context, id string) (*model.{{.Type}}Entity, error) {
+	return m.entity, m.err
+}
+
+func (m *mock{{.Type}}DAO) FetchAll(ctx context.Context) ([]*model.{{.Type}}Entity, error) {
+	return m.entities, m.err
+}
+
+func (m *mock{{.Type}}DAO) Update(ctx context.Context, id string, {{.Var}} *model.{{.Type}}) (*model.{{.Type}}Entity, error) {
+	return m.entity, m.err
+}
+
+func (m *mock{{.Type}}DAO) Delete(ctx context.Context, id string) error {
+	return m.err
+}
+`))
+
+var testTemplate = template.Must(template.New("test").Parse(header + `package {{.Package}}
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"{{.Module}}/pkg/errorx"
+	"{{.Module}}/pkg/model"
+	"github.com/gorilla/mux"
+)
+
+func TestHandler(t *testing.T) {
+	type fields struct {
+		{{.Type}}DAO DAO
+	}
+	type args struct {
+		req *http.Request
+	}
+	entity := &model.{{.Type}}Entity{
+		Entity: model.Entity{ID: "1234", CreatedAt: time.Date(2020, time.July, 23, 0, 0, 0, 0, time.UTC)},
+	}
+	const sample = ` + "`{{.SampleBody}}`" + `
+	_ = json.Unmarshal([]byte(sample), &entity.{{.Type}})
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		status int
+		body   interface{}
+	}{
+		{
+			name: "create",
+			fields: fields{
+				{{.Type}}DAO: &mock{{.Type}}DAO{entity: entity},
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodPost, "http://localhost:8080/{{.Path}}", strings.NewReader(sample)),
+			},
+			status: http.StatusCreated,
+			body:   entity,
+		},
+		{
+			name: "create decode",
+			fields: fields{
+				{{.Type}}DAO: &mock{{.Type}}DAO{},
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodPost, "http://localhost:8080/{{.Path}}", strings.NewReader(` + "`{`" + `)),
+			},
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "fetch",
+			fields: fields{
+				{{.Type}}DAO: &mock{{.Type}}DAO{entity: entity},
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodGet, "http://localhost:8080/{{.Path}}/1234", nil),
+			},
+			status: http.StatusOK,
+			body:   entity,
+		},
+		{
+			name: "fetch missing",
+			fields: fields{
+				{{.Type}}DAO: &mock{{.Type}}DAO{err: errorx.ErrNo{{.Type}}},
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodGet, "http://localhost:8080/{{.Path}}/1234", nil),
+			},
+			status: http.StatusNotFound,
+			body: errorMessage{
+				ID:      "1234",
+				Message: "{{.Words}} 1234 does not exist",
+			},
+		},
+		{
+			name: "fetch deleted",
+			fields: fields{
+				{{.Type}}DAO: &mock{{.Type}}DAO{err: errorx.ErrDelete{{.Type}}},
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodGet, "http://localhost:8080/{{.Path}}/1234", nil),
+			},
+			status: http.StatusGone,
+			body: errorMessage{
+				ID:      "1234",
+				Message: "{{.Words}} 1234 has been deleted",
+			},
+		},
+		{
+			name: "list",
+			fields: fields{
+				{{.Type}}DAO: &mock{{.Type}}DAO{entities: []*model.{{.Type}}Entity{entity}},
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodGet, "http://localhost:8080/{{.Path}}", nil),
+			},
+			status: http.StatusOK,
+			body:   []*model.{{.Type}}Entity{entity},
+		},
+		{
+			name: "update without fields",
+			fields: fields{
+				{{.Type}}DAO: &mock{{.Type}}DAO{},
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodPatch, "http://localhost:8080/{{.Path}}/1234", strings.NewReader(` + "`{}`" + `)),
+			},
+			status: http.StatusBadRequest,
+			body: errorMessage{
+				Message: "{{.Words}} must have fields to update",
+			},
+		},
+		{
+			name: "delete",
+			fields: fields{
+				{{.Type}}DAO: &mock{{.Type}}DAO{},
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodDelete, "http://localhost:8080/{{.Path}}/1234", nil),
+			},
+			status: http.StatusNoContent,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Handler{
+				{{.Type}}DAO: tt.fields.{{.Type}}DAO,
+			}
+			router := mux.NewRouter()
+			h.Add(router)
+			writer := httptest.NewRecorder()
+			router.ServeHTTP(writer, tt.args.req)
+
+			if writer.Result().StatusCode != tt.status {
+				t.Errorf("Handler = %v, want %v", writer.Result().StatusCode, tt.status)
+				return
+			}
+			if tt.body == nil {
+				return
+			}
+
+			var body interface{}
+			if err := json.NewDecoder(writer.Body).Decode(&body); err != nil {
+				t.Errorf("Handler = json body decode error %v", err)
+				return
+			}
+
+			var wantBody interface{}
+			if enc, err := json.Marshal(tt.body); err == nil {
+				_ = json.Unmarshal(enc, &wantBody)
+			}
+
+			if !reflect.DeepEqual(body, wantBody) {
+				t.Errorf("Handler = %v, want %v", body, wantBody)
+			}
+		})
+	}
+}
+
+func TestHandler_Add(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		want   bool
+	}{
+		{name: "create", method: http.MethodPost, path: "/{{.Path}}", want: true},
+		{name: "list", method: http.MethodGet, path: "/{{.Path}}", want: true},
+		{name: "fetch", method: http.MethodGet, path: "/{{.Path}}/1234", want: true},
+		{name: "update", method: http.MethodPatch, path: "/{{.Path}}/1234", want: true},
+		{name: "delete", method: http.MethodDelete, path: "/{{.Path}}/1234", want: true},
+		{name: "nope", method: http.MethodPut, path: "/{{.Path}}", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Handler{}
+			r := mux.NewRouter()
+
+			h.Add(r)
+
+			var match mux.RouteMatch
+			if ok := r.Match(httptest.NewRequest(tt.method, "http://localhost:8080"+tt.path, nil), &match); ok != tt.want {
+				t.Errorf("Handler.Add() %v", tt.want)
+			}
+		})
+	}
+}
+`))
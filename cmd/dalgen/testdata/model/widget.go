@@ -0,0 +1,22 @@
+package model
+
+//go:generate go run github.com/g8rswimmer/go-data-access-example/cmd/dalgen -type Widget
+
+// WidgetSize is the size of a widget
+type WidgetSize string
+
+// Widget is the fixture of the generator tests
+//
+//dalgen:entity path=widgets order=name
+type Widget struct {
+	Name   string     `json:"name"`
+	Serial string     `json:"serial" db:"serial_number,nullempty"`
+	Size   WidgetSize `json:"size"`
+	Weight float64    `json:"weight"`
+	Note   string     `json:"note" db:"-"`
+}
+
+// Validate returns an error when the widget is not valid
+func (w *Widget) Validate() error {
+	return nil
+}
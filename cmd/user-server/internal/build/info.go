@@ -0,0 +1,68 @@
+package build
+
+import (
+	"runtime"
+	"runtime/debug"
+	"sort"
+)
+
+// These are set at link time, for example
+// go build -ldflags "-X .../internal/build.Version=v0.2.0 -X .../internal/build.Commit=abc123"
+var (
+	Version = ""
+	Commit  = ""
+	Time    = ""
+)
+
+const develVersion = "(devel)"
+
+// Dependency is a module the binary was built with
+type Dependency struct {
+	Path    string `json:"path"`
+	Version string `json:"version"`
+}
+
+// Info contains the build metadata of the binary
+type Info struct {
+	Version      string        `json:"version"`
+	Commit       string        `json:"commit,omitempty"`
+	Time         string        `json:"build_time,omitempty"`
+	Modified     bool          `json:"modified,omitempty"`
+	GoVersion    string        `json:"go_version"`
+	Dependencies []*Dependency `json:"dependencies,omitempty"`
+}
+
+// Read returns the link time values falling back to the embedded build information
+func Read(fallback string) *Info {
+	info := &Info{
+		Version:   Version,
+		Commit:    Commit,
+		Time:      Time,
+		GoVersion: runtime.Version(),
+	}
+
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		if len(info.Version) == 0 && bi.Main.Version != develVersion {
+			info.Version = bi.Main.Version
+		}
+		readVCS(bi, info)
+		for _, dep := range bi.Deps {
+			d := dep
+			if d.Replace != nil {
+				d = d.Replace
+			}
+			info.Dependencies = append(info.Dependencies, &Dependency{
+				Path:    d.Path,
+				Version: d.Version,
+			})
+		}
+		sort.Slice(info.Dependencies, func(i, j int) bool {
+			return info.Dependencies[i].Path < info.Dependencies[j].Path
+		})
+	}
+
+	if len(info.Version) == 0 {
+		info.Version = fallback
+	}
+	return info
+}
@@ -0,0 +1,25 @@
+//go:build go1.18
+// +build go1.18
+
+package build
+
+import "runtime/debug"
+
+// readVCS fills the commit, time and modified flag that the go command stamps from version control
+func readVCS(bi *debug.BuildInfo, info *Info) {
+	for _, setting := range bi.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if len(info.Commit) == 0 {
+				info.Commit = setting.Value
+			}
+		case "vcs.time":
+			if len(info.Time) == 0 {
+				info.Time = setting.Value
+			}
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		default:
+		}
+	}
+}
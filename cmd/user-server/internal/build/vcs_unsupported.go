@@ -0,0 +1,10 @@
+//go:build !go1.18
+// +build !go1.18
+
+package build
+
+import "runtime/debug"
+
+// readVCS does nothing as the go command only stamps version control settings from go1.18,
+// the commit and time are then only set at link time
+func readVCS(bi *debug.BuildInfo, info *Info) {}
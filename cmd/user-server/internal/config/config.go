@@ -0,0 +1,292 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// HTTP contains all of the http configuration
+type HTTP struct {
+	// Listen is the listener type, tcp on the port, a unix socket or a systemd activated socket
+	Listen       string
+	Port         string
+	Socket       string
+	SocketMode   os.FileMode
+	H2C          bool
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	DrainDelay   time.Duration
+	// MaxBodySize is the largest request body in bytes
+	MaxBodySize int64
+}
+
+// Lifecycle contains how the components of the service are stopped
+type Lifecycle struct {
+	// StopTimeout bounds draining and stopping every component
+	StopTimeout time.Duration
+}
+
+// Log contains the logging configuration
+type Log struct {
+	Level string
+}
+
+// Trace contains the tracing configuration
+type Trace struct {
+	Exporter string
+}
+
+// Database contains the database configuration
+type Database struct {
+	DSN string
+}
+
+// RateLimit contains the per client request rate limit, a zero rate disables it
+type RateLimit struct {
+	RequestsPerSecond float64
+	Burst             int
+}
+
+// CORS contains the cross origin configuration, no allowed origins disables it
+type CORS struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+// Compression contains the response compression and the compressed request limits
+type Compression struct {
+	// Encodings are the response content codings, none disables response compression
+	Encodings    []string
+	MinSize      int
+	ContentTypes []string
+	// MaxRequestSize is the largest a compressed request body may decompress to
+	MaxRequestSize int64
+}
+
+// Features contains the enabled feature flags
+type Features struct {
+	Enabled []string
+}
+
+// IsEnabled returns if the feature flag is enabled
+func (f *Features) IsEnabled(name string) bool {
+	for _, e := range f.Enabled {
+		if e == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Config contains all of the configuration
+type Config struct {
+	HTTP        *HTTP
+	Lifecycle   *Lifecycle
+	Log         *Log
+	Trace       *Trace
+	Database    *Database
+	RateLimit   *RateLimit
+	CORS        *CORS
+	Compression *Compression
+	Features    *Features
+	TLS         *TLS
+	// File is the configuration file that was read, if any
+	File string
+}
+
+// Errors contains every problem found while loading the configuration
+type Errors []error
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("configuration has %d errors: %s", len(e), strings.Join(msgs, "; "))
+}
+
+const (
+	configFileEnv  = "CONFIG_FILE"
+	configFileFlag = "config"
+	secretMask     = "********"
+)
+
+// Default returns the configuration before any source is applied
+func Default() *Config {
+	return &Config{
+		HTTP: &HTTP{
+			Listen:       "tcp",
+			Port:         "8080",
+			SocketMode:   0660,
+			MaxBodySize:  1 << 20,
+			ReadTimeout:  10 * time.Second,
+			WriteTimeout: 10 * time.Second,
+		},
+		Lifecycle: &Lifecycle{
+			StopTimeout: 15 * time.Second,
+		},
+		Log: &Log{
+			Level: "info",
+		},
+		Trace: &Trace{},
+		Database: &Database{
+			DSN: "file::memory:?mode=memory",
+		},
+		RateLimit: &RateLimit{
+			Burst: 1,
+		},
+		CORS: &CORS{
+			AllowedMethods: []string{"GET", "POST", "PATCH", "DELETE"},
+			AllowedHeaders: []string{"Content-Type", "Authorization", "X-Request-ID"},
+			MaxAge:         10 * time.Minute,
+		},
+		Compression: &Compression{
+			Encodings:      []string{"gzip", "deflate"},
+			MinSize:        1024,
+			ContentTypes:   []string{"application/json"},
+			MaxRequestSize: 10 << 20,
+		},
+		Features: &Features{},
+		TLS: &TLS{
+			MinVersion:   "1.2",
+			CipherPolicy: "default",
+		},
+	}
+}
+
+// Load will merge the configuration file, the environment variables and the flags in that order of precedence
+func Load(args []string) (*Config, error) {
+	return load(args, os.LookupEnv)
+}
+
+func load(args []string, lookupEnv func(string) (string, bool)) (*Config, error) {
+	fs := flag.NewFlagSet("user-server", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	file := fs.String(configFileFlag, "", "configuration file (json, yaml or toml)")
+	for _, f := range fields {
+		fs.String(f.flag, "", f.usage)
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, fmt.Errorf("configuration flags %w", err)
+	}
+
+	sources := []map[string]string{}
+
+	path := *file
+	if len(path) == 0 {
+		path, _ = lookupEnv(configFileEnv)
+	}
+	if len(path) > 0 {
+		values, err := readFile(path)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, values)
+	}
+
+	env := map[string]string{}
+	for _, f := range fields {
+		if v, ok := lookupEnv(f.env); ok {
+			env[f.key] = v
+		}
+	}
+	sources = append(sources, env)
+
+	flags := map[string]string{}
+	fs.Visit(func(fl *flag.Flag) {
+		if f, has := fieldByFlag(fl.Name); has {
+			flags[f.key] = fl.Value.String()
+		}
+	})
+	sources = append(sources, flags)
+
+	c := Default()
+	c.File = path
+	errs := Errors{}
+	for _, source := range sources {
+		errs = append(errs, c.apply(source)...)
+	}
+	errs = append(errs, c.Validate()...)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return c, nil
+}
+
+// apply will set every known key, reporting unknown keys and bad values
+func (c *Config) apply(values map[string]string) Errors {
+	errs := Errors{}
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		f, has := fieldByKey(k)
+		if has == false {
+			errs = append(errs, fmt.Errorf("%s is not a configuration key", k))
+			continue
+		}
+		if err := f.set(c, values[k]); err != nil {
+			errs = append(errs, fmt.Errorf("%s %w", k, err))
+		}
+	}
+	return errs
+}
+
+// clone returns a deep copy of the configuration
+func (c *Config) clone() *Config {
+	cp := Default()
+	cp.File = c.File
+	for _, f := range fields {
+		_ = f.set(cp, f.get(c))
+	}
+	return cp
+}
+
+// Values returns the active configuration by key with the secrets masked
+func (c *Config) Values() map[string]string {
+	values := make(map[string]string, len(fields))
+	for _, f := range fields {
+		v := f.get(c)
+		if f.secret && len(v) > 0 {
+			v = secretMask
+		}
+		values[f.key] = v
+	}
+	return values
+}
+
+// Print will write the active configuration with the secrets masked
+func (c *Config) Print(w io.Writer) {
+	values := c.Values()
+	for _, f := range fields {
+		fmt.Fprintf(w, "%s=%s\t(env %s, flag -%s)\n", f.key, values[f.key], f.env, f.flag)
+	}
+}
+
+// ErrDuration is returned when a duration can not be parsed
+var ErrDuration = errors.New("invalid duration")
+
+// parseDuration accepts go durations (10s, 1m) and for compatibility a bare number of seconds
+func parseDuration(v string) (time.Duration, error) {
+	v = strings.TrimSpace(v)
+	if d, err := time.ParseDuration(v); err == nil {
+		return d, nil
+	}
+	if d, err := time.ParseDuration(v + "s"); err == nil {
+		return d, nil
+	}
+	return 0, fmt.Errorf("%w %q", ErrDuration, v)
+}
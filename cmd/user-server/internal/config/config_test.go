@@ -0,0 +1,363 @@
+package config
+
+import (
+	"crypto/tls"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	type args struct {
+		file  func(t *testing.T, dir string) string
+		env   map[string]string
+		flags []string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    map[string]string
+		wantErr int
+	}{
+		{
+			name: "defaults",
+			args: args{},
+			want: map[string]string{
+				"http.port":         "8080",
+				"http.read_timeout": "10s",
+				"log.level":         "info",
+				"database.dsn":      secretMask,
+			},
+		},
+		{
+			name: "precedence file env flags",
+			args: args{
+				file: func(t *testing.T, dir string) string {
+					return writeFile(t, dir, "config.yaml", `
+http:
+  port: 9000 # comment
+  read_timeout: 1m
+  write_timeout: "30s"
+log:
+  level: debug
+`)
+				},
+				env: map[string]string{
+					"HTTP_PORT":     "9001",
+					"HTTP_WRITE_TO": "20",
+				},
+				flags: []string{"-http-port", "9002"},
+			},
+			want: map[string]string{
+				"http.port":          "9002",
+				"http.read_timeout":  "1m0s",
+				"http.write_timeout": "20s",
+				"log.level":          "debug",
+			},
+		},
+		{
+			name: "yaml block list",
+			args: args{
+				file: func(t *testing.T, dir string) string {
+					return writeFile(t, dir, "config.yml", `
+cors:
+  allowed_origins:
+    - https://a.example
+    - "https://b.example"
+`)
+				},
+			},
+			want: map[string]string{
+				"cors.allowed_origins": "https://a.example,https://b.example",
+			},
+		},
+		{
+			name: "json file",
+			args: args{
+				file: func(t *testing.T, dir string) string {
+					return writeFile(t, dir, "config.json", `{"http": {"port": 7000, "drain_delay": "5s"}}`)
+				},
+			},
+			want: map[string]string{
+				"http.port":        "7000",
+				"http.drain_delay": "5s",
+			},
+		},
+		{
+			name: "toml file",
+			args: args{
+				file: func(t *testing.T, dir string) string {
+					return writeFile(t, dir, "config.toml", `
+# user server
+[http]
+port = "7001"
+read_timeout = "2s"
+
+[trace]
+exporter = "stdout"
+`)
+				},
+			},
+			want: map[string]string{
+				"http.port":         "7001",
+				"http.read_timeout": "2s",
+				"trace.exporter":    "stdout",
+			},
+		},
+		{
+			name: "all errors reported",
+			args: args{
+				env: map[string]string{
+					"HTTP_PORT":     "abc",
+					"HTTP_READ_TO":  "soon",
+					"LOG_LEVEL":     "loud",
+					"HTTP_WRITE_TO": "-1s",
+				},
+			},
+			wantErr: 4,
+		},
+		{
+			name: "unix socket",
+			args: args{
+				flags: []string{"-http-listen", "unix", "-http-socket", "/run/user.sock", "-http-socket-mode", "0600", "-http-h2c", "true"},
+			},
+			want: map[string]string{
+				"http.listen":      "unix",
+				"http.socket":      "/run/user.sock",
+				"http.socket_mode": "0600",
+				"http.h2c":         "true",
+			},
+		},
+		{
+			name: "listener errors",
+			args: args{
+				env: map[string]string{
+					"HTTP_LISTEN":      "unix",
+					"HTTP_SOCKET_MODE": "rw",
+					"HTTP_H2C":         "true",
+					"TLS_CERT_FILE":    "server.crt",
+					"TLS_KEY_FILE":     "server.key",
+				},
+			},
+			wantErr: 3,
+		},
+		{
+			name: "stop timeout within drain delay",
+			args: args{
+				flags: []string{"-http-drain-delay", "20s", "-stop-timeout", "10s"},
+			},
+			wantErr: 1,
+		},
+		{
+			name: "cors credentials with any origin",
+			args: args{
+				env: map[string]string{
+					"CORS_ALLOWED_ORIGINS":   "*",
+					"CORS_ALLOW_CREDENTIALS": "true",
+				},
+			},
+			wantErr: 1,
+		},
+		{
+			name: "client ca implies required client certificates",
+			args: args{
+				flags: []string{"-tls-cert-file", "server.crt", "-tls-key-file", "server.key", "-tls-client-ca-file", "ca.crt"},
+			},
+			want: map[string]string{
+				"tls.client_ca_file": "ca.crt",
+				"tls.client_auth":    "",
+			},
+		},
+		{
+			name: "client auth none with client ca",
+			args: args{
+				flags: []string{"-tls-cert-file", "server.crt", "-tls-key-file", "server.key", "-tls-client-ca-file", "ca.crt", "-tls-client-auth", "none"},
+			},
+			wantErr: 1,
+		},
+		{
+			name: "brotli and zstd are not offered",
+			args: args{
+				flags: []string{"-compression-encodings", "br,zstd,gzip"},
+			},
+			wantErr: 2,
+		},
+		{
+			name: "unknown file key",
+			args: args{
+				file: func(t *testing.T, dir string) string {
+					return writeFile(t, dir, "config.json", `{"http": {"prt": "7000"}}`)
+				},
+			},
+			wantErr: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "config")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+
+			flags := tt.args.flags
+			if tt.args.file != nil {
+				flags = append([]string{"-config", tt.args.file(t, dir)}, flags...)
+			}
+			lookup := func(key string) (string, bool) {
+				v, ok := tt.args.env[key]
+				return v, ok
+			}
+
+			got, err := load(flags, lookup)
+			if (err != nil) != (tt.wantErr > 0) {
+				t.Errorf("Load() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr > 0 {
+				var errs Errors
+				if errors.As(err, &errs) == false || len(errs) != tt.wantErr {
+					t.Errorf("Load() error = %v, want %d errors", err, tt.wantErr)
+				}
+				return
+			}
+
+			values := got.Values()
+			for k, v := range tt.want {
+				if values[k] != v {
+					t.Errorf("Load() %s = %v, want %v", k, values[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{
+			name:  "duration",
+			value: "1m30s",
+			want:  90 * time.Second,
+		},
+		{
+			name:  "seconds",
+			value: "10",
+			want:  10 * time.Second,
+		},
+		{
+			name:    "invalid",
+			value:   "ten",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDuration(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseDuration() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("parseDuration() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseYAML_Lists(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "flow",
+			yaml: "cors:\n  origins: [\"https://a.example\", https://b.example]\n",
+			want: map[string]string{"cors.origins": "https://a.example,https://b.example"},
+		},
+		{
+			name: "flow quoted commas",
+			yaml: "cors:\n  headers: [\"a, b\", 'c, d']\n",
+			want: map[string]string{"cors.headers": "a, b,c, d"},
+		},
+		{
+			name: "block",
+			yaml: "cors:\n  origins:\n    - https://a.example\n    - \"https://b.example\" # comment\n  max_age: 60\n",
+			want: map[string]string{"cors.origins": "https://a.example,https://b.example", "cors.max_age": "60"},
+		},
+		{
+			name: "block at the key indent",
+			yaml: "cors:\n  origins:\n  - https://a.example\n  - 'https://b.example'\nlog:\n  level: debug\n",
+			want: map[string]string{"cors.origins": "https://a.example,https://b.example", "log.level": "debug"},
+		},
+		{
+			name: "quoted scalars",
+			yaml: "\"http\":\n  socket: '/run/it''s.sock'\n  host: \"a: b\"\n  url: http://localhost:8080\n",
+			want: map[string]string{"http.socket": "/run/it's.sock", "http.host": "a: b", "http.url": "http://localhost:8080"},
+		},
+		{
+			name:    "list of mappings",
+			yaml:    "cors:\n  origins:\n    - host: a\n",
+			wantErr: true,
+		},
+		{
+			name:    "keys and items",
+			yaml:    "cors:\n  origins:\n    - a\n    b: c\n",
+			wantErr: true,
+		},
+		{
+			name:    "item without a key",
+			yaml:    "- a\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseYAML([]byte(tt.yaml))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseYAML() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr == false && reflect.DeepEqual(got, tt.want) == false {
+				t.Errorf("parseYAML() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTLS_ClientAuthType(t *testing.T) {
+	tests := []struct {
+		name string
+		tls  TLS
+		want tls.ClientAuthType
+	}{
+		{name: "no client ca", tls: TLS{}, want: tls.NoClientCert},
+		{name: "implied by client ca", tls: TLS{ClientCAFile: "ca.crt"}, want: tls.RequireAndVerifyClientCert},
+		{name: "request", tls: TLS{ClientCAFile: "ca.crt", ClientAuth: "request"}, want: tls.VerifyClientCertIfGiven},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.tls.ClientAuthType(); got != tt.want {
+				t.Errorf("TLS.ClientAuthType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,451 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// field maps a configuration key to its environment variable, flag and value
+type field struct {
+	key        string
+	env        string
+	flag       string
+	usage      string
+	secret     bool
+	reloadable bool
+	get        func(c *Config) string
+	set        func(c *Config, v string) error
+}
+
+var fields = []*field{
+	{
+		key:   "http.port",
+		env:   "HTTP_PORT",
+		flag:  "http-port",
+		usage: "http listen port",
+		get:   func(c *Config) string { return c.HTTP.Port },
+		set: func(c *Config, v string) error {
+			c.HTTP.Port = strings.TrimSpace(v)
+			return nil
+		},
+	},
+	{
+		key:   "http.listen",
+		env:   "HTTP_LISTEN",
+		flag:  "http-listen",
+		usage: "listener type (tcp, unix, systemd)",
+		get:   func(c *Config) string { return c.HTTP.Listen },
+		set: func(c *Config, v string) error {
+			c.HTTP.Listen = strings.ToLower(strings.TrimSpace(v))
+			return nil
+		},
+	},
+	{
+		key:   "http.socket",
+		env:   "HTTP_SOCKET",
+		flag:  "http-socket",
+		usage: "unix socket path when listening on unix",
+		get:   func(c *Config) string { return c.HTTP.Socket },
+		set: func(c *Config, v string) error {
+			c.HTTP.Socket = strings.TrimSpace(v)
+			return nil
+		},
+	},
+	{
+		key:   "http.socket_mode",
+		env:   "HTTP_SOCKET_MODE",
+		flag:  "http-socket-mode",
+		usage: "unix socket file permissions (0660)",
+		get:   func(c *Config) string { return fmt.Sprintf("%#o", uint32(c.HTTP.SocketMode)) },
+		set: func(c *Config, v string) error {
+			mode, err := strconv.ParseUint(strings.TrimSpace(v), 8, 32)
+			if err != nil || mode > 0777 {
+				return fmt.Errorf("invalid file mode %q", v)
+			}
+			c.HTTP.SocketMode = os.FileMode(mode)
+			return nil
+		},
+	},
+	{
+		key:   "http.h2c",
+		env:   "HTTP_H2C",
+		flag:  "http-h2c",
+		usage: "serve http/2 without tls (true, false)",
+		get:   func(c *Config) string { return strconv.FormatBool(c.HTTP.H2C) },
+		set: func(c *Config, v string) error {
+			h2c, err := strconv.ParseBool(strings.TrimSpace(v))
+			if err != nil {
+				return fmt.Errorf("invalid boolean %q", v)
+			}
+			c.HTTP.H2C = h2c
+			return nil
+		},
+	},
+	{
+		key:   "http.read_timeout",
+		env:   "HTTP_READ_TO",
+		flag:  "http-read-timeout",
+		usage: "http read timeout (10s, 1m)",
+		get:   func(c *Config) string { return c.HTTP.ReadTimeout.String() },
+		set: func(c *Config, v string) error {
+			return setDuration(&c.HTTP.ReadTimeout, v)
+		},
+	},
+	{
+		key:   "http.write_timeout",
+		env:   "HTTP_WRITE_TO",
+		flag:  "http-write-timeout",
+		usage: "http write timeout (10s, 1m)",
+		get:   func(c *Config) string { return c.HTTP.WriteTimeout.String() },
+		set: func(c *Config, v string) error {
+			return setDuration(&c.HTTP.WriteTimeout, v)
+		},
+	},
+	{
+		key:   "http.drain_delay",
+		env:   "HTTP_DRAIN_DELAY",
+		flag:  "http-drain-delay",
+		usage: "how long readiness fails before shutdown (5s)",
+		get:   func(c *Config) string { return c.HTTP.DrainDelay.String() },
+		set: func(c *Config, v string) error {
+			return setDuration(&c.HTTP.DrainDelay, v)
+		},
+	},
+	{
+		key:   "http.max_body_size",
+		env:   "HTTP_MAX_BODY_SIZE",
+		flag:  "http-max-body-size",
+		usage: "largest request body in bytes",
+		get:   func(c *Config) string { return strconv.FormatInt(c.HTTP.MaxBodySize, 10) },
+		set: func(c *Config, v string) error {
+			size, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid integer %q", v)
+			}
+			c.HTTP.MaxBodySize = size
+			return nil
+		},
+	},
+	{
+		key:   "lifecycle.stop_timeout",
+		env:   "STOP_TIMEOUT",
+		flag:  "stop-timeout",
+		usage: "time allowed to drain and stop every component (15s)",
+		get:   func(c *Config) string { return c.Lifecycle.StopTimeout.String() },
+		set: func(c *Config, v string) error {
+			return setDuration(&c.Lifecycle.StopTimeout, v)
+		},
+	},
+	{
+		key:        "log.level",
+		env:        "LOG_LEVEL",
+		flag:       "log-level",
+		usage:      "log level (debug, info, warn, error)",
+		reloadable: true,
+		get:        func(c *Config) string { return c.Log.Level },
+		set: func(c *Config, v string) error {
+			c.Log.Level = strings.TrimSpace(v)
+			return nil
+		},
+	},
+	{
+		key:   "trace.exporter",
+		env:   "TRACE_EXPORTER",
+		flag:  "trace-exporter",
+		usage: "trace exporter (stdout) or empty for none",
+		get:   func(c *Config) string { return c.Trace.Exporter },
+		set: func(c *Config, v string) error {
+			c.Trace.Exporter = strings.TrimSpace(v)
+			return nil
+		},
+	},
+	{
+		key:    "database.dsn",
+		env:    "DATABASE_DSN",
+		flag:   "database-dsn",
+		usage:  "database data source name",
+		secret: true,
+		get:    func(c *Config) string { return c.Database.DSN },
+		set: func(c *Config, v string) error {
+			c.Database.DSN = v
+			return nil
+		},
+	},
+	{
+		key:        "ratelimit.requests_per_second",
+		env:        "RATELIMIT_RPS",
+		flag:       "ratelimit-rps",
+		usage:      "requests per second allowed for each client, 0 disables the limit",
+		reloadable: true,
+		get:        func(c *Config) string { return strconv.FormatFloat(c.RateLimit.RequestsPerSecond, 'f', -1, 64) },
+		set: func(c *Config, v string) error {
+			rps, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return fmt.Errorf("invalid number %q", v)
+			}
+			c.RateLimit.RequestsPerSecond = rps
+			return nil
+		},
+	},
+	{
+		key:        "ratelimit.burst",
+		env:        "RATELIMIT_BURST",
+		flag:       "ratelimit-burst",
+		usage:      "requests a client may make at once above the rate",
+		reloadable: true,
+		get:        func(c *Config) string { return strconv.Itoa(c.RateLimit.Burst) },
+		set: func(c *Config, v string) error {
+			burst, err := strconv.Atoi(strings.TrimSpace(v))
+			if err != nil {
+				return fmt.Errorf("invalid integer %q", v)
+			}
+			c.RateLimit.Burst = burst
+			return nil
+		},
+	},
+	{
+		key:        "cors.allowed_origins",
+		env:        "CORS_ALLOWED_ORIGINS",
+		flag:       "cors-allowed-origins",
+		usage:      "comma separated origins allowed to make cross origin requests",
+		reloadable: true,
+		get:        func(c *Config) string { return strings.Join(c.CORS.AllowedOrigins, ",") },
+		set: func(c *Config, v string) error {
+			c.CORS.AllowedOrigins = splitList(v)
+			return nil
+		},
+	},
+	{
+		key:        "cors.allowed_methods",
+		env:        "CORS_ALLOWED_METHODS",
+		flag:       "cors-allowed-methods",
+		usage:      "comma separated methods allowed in cross origin requests",
+		reloadable: true,
+		get:        func(c *Config) string { return strings.Join(c.CORS.AllowedMethods, ",") },
+		set: func(c *Config, v string) error {
+			c.CORS.AllowedMethods = splitList(strings.ToUpper(v))
+			return nil
+		},
+	},
+	{
+		key:        "cors.allowed_headers",
+		env:        "CORS_ALLOWED_HEADERS",
+		flag:       "cors-allowed-headers",
+		usage:      "comma separated request headers allowed in cross origin requests",
+		reloadable: true,
+		get:        func(c *Config) string { return strings.Join(c.CORS.AllowedHeaders, ",") },
+		set: func(c *Config, v string) error {
+			c.CORS.AllowedHeaders = splitList(v)
+			return nil
+		},
+	},
+	{
+		key:        "cors.allow_credentials",
+		env:        "CORS_ALLOW_CREDENTIALS",
+		flag:       "cors-allow-credentials",
+		usage:      "allow cookies and authorization in cross origin requests (true, false)",
+		reloadable: true,
+		get:        func(c *Config) string { return strconv.FormatBool(c.CORS.AllowCredentials) },
+		set: func(c *Config, v string) error {
+			credentials, err := strconv.ParseBool(strings.TrimSpace(v))
+			if err != nil {
+				return fmt.Errorf("invalid boolean %q", v)
+			}
+			c.CORS.AllowCredentials = credentials
+			return nil
+		},
+	},
+	{
+		key:        "cors.max_age",
+		env:        "CORS_MAX_AGE",
+		flag:       "cors-max-age",
+		usage:      "how long browsers may cache a preflight response (10m)",
+		reloadable: true,
+		get:        func(c *Config) string { return c.CORS.MaxAge.String() },
+		set: func(c *Config, v string) error {
+			return setDuration(&c.CORS.MaxAge, v)
+		},
+	},
+	{
+		key:   "compression.encodings",
+		env:   "COMPRESSION_ENCODINGS",
+		flag:  "compression-encodings",
+		usage: "comma separated response encodings, gzip or deflate (br and zstd are not supported), empty disables response compression",
+		get:   func(c *Config) string { return strings.Join(c.Compression.Encodings, ",") },
+		set: func(c *Config, v string) error {
+			c.Compression.Encodings = splitList(strings.ToLower(v))
+			return nil
+		},
+	},
+	{
+		key:   "compression.min_size",
+		env:   "COMPRESSION_MIN_SIZE",
+		flag:  "compression-min-size",
+		usage: "smallest response body in bytes that is compressed",
+		get:   func(c *Config) string { return strconv.Itoa(c.Compression.MinSize) },
+		set: func(c *Config, v string) error {
+			size, err := strconv.Atoi(strings.TrimSpace(v))
+			if err != nil {
+				return fmt.Errorf("invalid integer %q", v)
+			}
+			c.Compression.MinSize = size
+			return nil
+		},
+	},
+	{
+		key:   "compression.content_types",
+		env:   "COMPRESSION_CONTENT_TYPES",
+		flag:  "compression-content-types",
+		usage: "comma separated media types that are compressed",
+		get:   func(c *Config) string { return strings.Join(c.Compression.ContentTypes, ",") },
+		set: func(c *Config, v string) error {
+			c.Compression.ContentTypes = splitList(v)
+			return nil
+		},
+	},
+	{
+		key:   "compression.max_request_size",
+		env:   "COMPRESSION_MAX_REQUEST_SIZE",
+		flag:  "compression-max-request-size",
+		usage: "largest size in bytes a compressed request body may decompress to",
+		get:   func(c *Config) string { return strconv.FormatInt(c.Compression.MaxRequestSize, 10) },
+		set: func(c *Config, v string) error {
+			size, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid integer %q", v)
+			}
+			c.Compression.MaxRequestSize = size
+			return nil
+		},
+	},
+	{
+		key:        "features.enabled",
+		env:        "FEATURES_ENABLED",
+		flag:       "features-enabled",
+		usage:      "comma separated feature flags to enable",
+		reloadable: true,
+		get:        func(c *Config) string { return strings.Join(c.Features.Enabled, ",") },
+		set: func(c *Config, v string) error {
+			c.Features.Enabled = splitList(v)
+			return nil
+		},
+	},
+	{
+		key:   "tls.cert_file",
+		env:   "TLS_CERT_FILE",
+		flag:  "tls-cert-file",
+		usage: "server certificate file, enables https",
+		get:   func(c *Config) string { return c.TLS.CertFile },
+		set: func(c *Config, v string) error {
+			c.TLS.CertFile = strings.TrimSpace(v)
+			return nil
+		},
+	},
+	{
+		key:   "tls.key_file",
+		env:   "TLS_KEY_FILE",
+		flag:  "tls-key-file",
+		usage: "server private key file",
+		get:   func(c *Config) string { return c.TLS.KeyFile },
+		set: func(c *Config, v string) error {
+			c.TLS.KeyFile = strings.TrimSpace(v)
+			return nil
+		},
+	},
+	{
+		key:   "tls.min_version",
+		env:   "TLS_MIN_VERSION",
+		flag:  "tls-min-version",
+		usage: "minimum tls version (1.2, 1.3)",
+		get:   func(c *Config) string { return c.TLS.MinVersion },
+		set: func(c *Config, v string) error {
+			c.TLS.MinVersion = strings.TrimSpace(v)
+			return nil
+		},
+	},
+	{
+		key:   "tls.cipher_policy",
+		env:   "TLS_CIPHER_POLICY",
+		flag:  "tls-cipher-policy",
+		usage: "cipher policy (default, intermediate, modern)",
+		get:   func(c *Config) string { return c.TLS.CipherPolicy },
+		set: func(c *Config, v string) error {
+			c.TLS.CipherPolicy = strings.TrimSpace(v)
+			return nil
+		},
+	},
+	{
+		key:   "tls.client_ca_file",
+		env:   "TLS_CLIENT_CA_FILE",
+		flag:  "tls-client-ca-file",
+		usage: "ca bundle used to verify client certificates",
+		get:   func(c *Config) string { return c.TLS.ClientCAFile },
+		set: func(c *Config, v string) error {
+			c.TLS.ClientCAFile = strings.TrimSpace(v)
+			return nil
+		},
+	},
+	{
+		key:   "tls.client_auth",
+		env:   "TLS_CLIENT_AUTH",
+		flag:  "tls-client-auth",
+		usage: "client certificate verification (none, request, require), required when empty and tls.client_ca_file is given",
+		get:   func(c *Config) string { return c.TLS.ClientAuth },
+		set: func(c *Config, v string) error {
+			c.TLS.ClientAuth = strings.TrimSpace(v)
+			return nil
+		},
+	},
+}
+
+// splitList splits a comma separated value dropping the empty items
+func splitList(v string) []string {
+	items := []string{}
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); len(item) > 0 {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// setDuration only changes the duration when the value can be parsed
+func setDuration(d *time.Duration, v string) error {
+	parsed, err := parseDuration(v)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+func fieldByKey(key string) (*field, bool) {
+	for _, f := range fields {
+		if f.key == key {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+func fieldByFlag(name string) (*field, bool) {
+	for _, f := range fields {
+		if f.flag == name {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+// Usage returns the description of every configuration key
+func Usage() string {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "  -%s\n\t%s (env %s)\n", configFileFlag, "configuration file (json, yaml or toml)", configFileEnv)
+	for _, f := range fields {
+		fmt.Fprintf(b, "  -%s\n\t%s (env %s, key %s)\n", f.flag, f.usage, f.env, f.key)
+	}
+	return b.String()
+}
@@ -0,0 +1,332 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// readFile will read the configuration file into dotted keys, the format is chosen by the extension
+func readFile(path string) (map[string]string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("configuration file %w", err)
+	}
+
+	var values map[string]string
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		values, err = parseJSON(data)
+	case ".yaml", ".yml":
+		values, err = parseYAML(data)
+	case ".toml":
+		values, err = parseTOML(data)
+	default:
+		return nil, fmt.Errorf("configuration file %s extension %q is not supported", path, ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("configuration file %s %w", path, err)
+	}
+	return values, nil
+}
+
+func parseJSON(data []byte) (map[string]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var doc map[string]interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	values := map[string]string{}
+	if err := flatten("", doc, values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func flatten(prefix string, v interface{}, values map[string]string) error {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, child := range t {
+			if err := flatten(join(prefix, k), child, values); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		items := make([]string, len(t))
+		for i, item := range t {
+			switch item.(type) {
+			case map[string]interface{}, []interface{}:
+				return fmt.Errorf("%s can only be a list of values", prefix)
+			default:
+			}
+			items[i] = fmt.Sprint(item)
+		}
+		values[prefix] = strings.Join(items, ",")
+	case nil:
+		values[prefix] = ""
+	default:
+		values[prefix] = fmt.Sprint(t)
+	}
+	return nil
+}
+
+// parseYAML supports the subset used for configuration: nested mappings, quoted and plain scalars,
+// block and flow lists of values, and comments
+func parseYAML(data []byte) (map[string]string, error) {
+	type level struct {
+		indent int
+		prefix string
+		// keys and items are set once the level has a nested key or a list item
+		keys  bool
+		items []string
+	}
+	values := map[string]string{}
+	stack := []*level{{indent: -1}}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		raw := stripComment(scanner.Text())
+		if len(strings.TrimSpace(raw)) == 0 || strings.TrimSpace(raw) == "---" {
+			continue
+		}
+		if strings.Contains(raw, "\t") {
+			return nil, fmt.Errorf("line %d tabs are not allowed for indentation", n)
+		}
+		indent := len(raw) - len(strings.TrimLeft(raw, " "))
+		line := strings.TrimSpace(raw)
+
+		if line == "-" || strings.HasPrefix(line, "- ") {
+			// a list item belongs to the key above it, which may be at the same indent
+			for len(stack) > 1 && indent < stack[len(stack)-1].indent {
+				stack = stack[:len(stack)-1]
+			}
+			top := stack[len(stack)-1]
+			switch {
+			case len(stack) == 1:
+				return nil, fmt.Errorf("line %d a list must be the value of a key", n)
+			case top.keys:
+				return nil, fmt.Errorf("line %d %s can not have both keys and list items", n, top.prefix)
+			}
+			item := strings.TrimSpace(strings.TrimPrefix(line, "-"))
+			if strings.HasPrefix(item, "-") || strings.HasPrefix(item, "[") || yamlKey(item) {
+				return nil, fmt.Errorf("line %d %s can only be a list of values", n, top.prefix)
+			}
+			v, err := scalar(item)
+			if err != nil {
+				return nil, fmt.Errorf("line %d %w", n, err)
+			}
+			top.items = append(top.items, v)
+			values[top.prefix] = strings.Join(top.items, ",")
+			continue
+		}
+
+		key, value, ok := splitYAML(line)
+		if ok == false {
+			return nil, fmt.Errorf("line %d expected key: value", n)
+		}
+
+		for len(stack) > 1 && indent <= stack[len(stack)-1].indent {
+			stack = stack[:len(stack)-1]
+		}
+		parent := stack[len(stack)-1]
+		if len(parent.items) > 0 {
+			return nil, fmt.Errorf("line %d %s can not have both keys and list items", n, parent.prefix)
+		}
+		parent.keys = true
+		full := join(parent.prefix, key)
+
+		if len(value) == 0 {
+			stack = append(stack, &level{indent: indent, prefix: full})
+			continue
+		}
+		v, err := scalar(value)
+		if err != nil {
+			return nil, fmt.Errorf("line %d %w", n, err)
+		}
+		values[full] = v
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// splitYAML splits a mapping line at the first colon that is followed by a space or ends the line
+// and is not inside a quoted key, a quoted key is unquoted
+func splitYAML(line string) (string, string, bool) {
+	quote := rune(0)
+	for i, c := range line {
+		switch {
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote == 0 && i == 0 && (c == '"' || c == '\''):
+			quote = c
+		case quote == 0 && c == ':' && (i == len(line)-1 || line[i+1] == ' '):
+			key := strings.TrimSpace(line[:i])
+			if strings.HasPrefix(key, `"`) || strings.HasPrefix(key, "'") {
+				unquoted, err := scalar(key)
+				if err != nil {
+					return "", "", false
+				}
+				key = unquoted
+			}
+			if len(key) == 0 {
+				return "", "", false
+			}
+			return key, strings.TrimSpace(line[i+1:]), true
+		default:
+		}
+	}
+	return "", "", false
+}
+
+// yamlKey returns true when the text is a key, such as a list item that is a mapping
+func yamlKey(s string) bool {
+	if strings.HasPrefix(s, `"`) || strings.HasPrefix(s, "'") {
+		if _, err := scalar(s); err == nil {
+			return false
+		}
+	}
+	_, _, ok := splitYAML(s)
+	return ok
+}
+
+// parseTOML supports the subset used for configuration: tables, key values, arrays and comments
+func parseTOML(data []byte) (map[string]string, error) {
+	values := map[string]string{}
+	prefix := ""
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(stripComment(scanner.Text()))
+		switch {
+		case len(line) == 0:
+			continue
+		case strings.HasPrefix(line, "["):
+			if strings.HasSuffix(line, "]") == false || strings.HasPrefix(line, "[[") {
+				return nil, fmt.Errorf("line %d invalid table %s", n, line)
+			}
+			prefix = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		default:
+		}
+
+		idx := strings.Index(line, "=")
+		if idx <= 0 {
+			return nil, fmt.Errorf("line %d expected key = value", n)
+		}
+		key := strings.TrimSpace(line[:idx])
+		v, err := scalar(strings.TrimSpace(line[idx+1:]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d %w", n, err)
+		}
+		values[join(prefix, key)] = v
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// scalar returns the value of a quoted or bare scalar, lists are joined with commas
+func scalar(v string) (string, error) {
+	switch {
+	case strings.HasPrefix(v, "["):
+		if strings.HasSuffix(v, "]") == false {
+			return "", fmt.Errorf("unterminated list %s", v)
+		}
+		inner := strings.TrimSpace(v[1 : len(v)-1])
+		if len(inner) == 0 {
+			return "", nil
+		}
+		parts := splitFlow(inner)
+		items := make([]string, 0, len(parts))
+		for _, p := range parts {
+			if len(strings.TrimSpace(p)) == 0 {
+				continue
+			}
+			item, err := scalar(strings.TrimSpace(p))
+			if err != nil {
+				return "", err
+			}
+			items = append(items, item)
+		}
+		return strings.Join(items, ","), nil
+	case strings.HasPrefix(v, `"`):
+		s, err := strconv.Unquote(v)
+		if err != nil {
+			return "", fmt.Errorf("invalid string %s", v)
+		}
+		return s, nil
+	case strings.HasPrefix(v, "'"):
+		// a single quote is escaped by doubling it
+		inner := v[1:]
+		if len(inner) == 0 || strings.HasSuffix(inner, "'") == false {
+			return "", fmt.Errorf("invalid string %s", v)
+		}
+		inner = inner[:len(inner)-1]
+		if strings.Contains(strings.Replace(inner, "''", "", -1), "'") {
+			return "", fmt.Errorf("invalid string %s", v)
+		}
+		return strings.Replace(inner, "''", "'", -1), nil
+	default:
+		return v, nil
+	}
+}
+
+// splitFlow splits the items of a flow list at the commas that are not inside a quoted string
+func splitFlow(s string) []string {
+	parts := []string{}
+	quote := rune(0)
+	start := 0
+	for i, c := range s {
+		switch {
+		case quote != 0 && c == quote && (quote != '"' || escaped(s[:i]) == false):
+			quote = 0
+		case quote == 0 && (c == '"' || c == '\''):
+			quote = c
+		case quote == 0 && c == ',':
+			parts = append(parts, s[start:i])
+			start = i + 1
+		default:
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// escaped returns true when the text ends with an odd number of backslashes
+func escaped(s string) bool {
+	n := len(s) - len(strings.TrimRight(s, `\`))
+	return n%2 == 1
+}
+
+// stripComment removes a # comment that is not inside a quoted string
+func stripComment(line string) string {
+	quote := rune(0)
+	for i, c := range line {
+		switch {
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote == 0 && (c == '"' || c == '\''):
+			quote = c
+		case quote == 0 && c == '#':
+			return line[:i]
+		default:
+		}
+	}
+	return line
+}
+
+func join(prefix, key string) string {
+	if len(prefix) == 0 {
+		return key
+	}
+	return prefix + "." + key
+}
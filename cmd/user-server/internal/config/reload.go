@@ -0,0 +1,148 @@
+package config
+
+import (
+	"context"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ReloadStatus reports the reloads since the server started
+type ReloadStatus struct {
+	Reloads    int       `json:"reloads"`
+	Failures   int       `json:"failures"`
+	LastReload time.Time `json:"last_reload,omitempty"`
+	LastError  string    `json:"last_error,omitempty"`
+	// Ignored are the changed keys that need a restart to take effect
+	Ignored []string `json:"ignored,omitempty"`
+}
+
+// Reloader holds the active configuration and swaps in the reloadable settings
+type Reloader struct {
+	args    []string
+	lookup  func(string) (string, bool)
+	current atomic.Value
+
+	mu        sync.Mutex
+	status    ReloadStatus
+	listeners []func(*Config)
+}
+
+// NewReloader creates a reloader starting from the loaded configuration, the args are re-parsed on every reload
+func NewReloader(args []string, c *Config) *Reloader {
+	r := &Reloader{
+		args:   args,
+		lookup: os.LookupEnv,
+	}
+	r.current.Store(c)
+	return r
+}
+
+// Current returns the active configuration, it must not be modified
+func (r *Reloader) Current() *Config {
+	return r.current.Load().(*Config)
+}
+
+// OnReload registers a function called with the new configuration after every successful reload
+func (r *Reloader) OnReload(fn func(*Config)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.listeners = append(r.listeners, fn)
+}
+
+// Status returns a copy of the reload status
+func (r *Reloader) Status() ReloadStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	status := r.status
+	status.Ignored = append([]string{}, r.status.Ignored...)
+	return status
+}
+
+// Reload will load the configuration again and apply only the reloadable settings
+func (r *Reloader) Reload() error {
+	next, err := r.swap()
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	listeners := append([]func(*Config){}, r.listeners...)
+	r.mu.Unlock()
+
+	for _, fn := range listeners {
+		fn(next)
+	}
+	return nil
+}
+
+// swap will store the next configuration and update the status
+func (r *Reloader) swap() (*Config, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	loaded, err := load(r.args, r.lookup)
+	if err != nil {
+		r.status.Failures++
+		r.status.LastError = err.Error()
+		return nil, err
+	}
+
+	current := r.Current()
+	next := current.clone()
+	ignored := []string{}
+	for _, f := range fields {
+		v := f.get(loaded)
+		switch {
+		case v == f.get(current):
+		case f.reloadable:
+			_ = f.set(next, v)
+		default:
+			ignored = append(ignored, f.key)
+		}
+	}
+	r.current.Store(next)
+
+	r.status.Reloads++
+	r.status.LastReload = time.Now().UTC()
+	r.status.LastError = ""
+	r.status.Ignored = ignored
+	return next, nil
+}
+
+// Watch will reload when the configuration file changes, checking every interval until the context is done
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration, onError func(error)) {
+	path := r.Current().File
+	if len(path) == 0 {
+		return
+	}
+
+	modified := modTime(path)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		m := modTime(path)
+		if m.Equal(modified) {
+			continue
+		}
+		modified = m
+		if err := r.Reload(); err != nil && onError != nil {
+			onError(err)
+		}
+	}
+}
+
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestReloader_Reload(t *testing.T) {
+	tests := []struct {
+		name     string
+		initial  string
+		reloaded string
+		wantErr  bool
+		want     map[string]string
+		ignored  []string
+	}{
+		{
+			name:     "reloadable settings",
+			initial:  `{"log": {"level": "info"}, "cors": {"allowed_origins": ["https://a.example"]}}`,
+			reloaded: `{"log": {"level": "debug"}, "cors": {"allowed_origins": ["https://b.example"]}, "ratelimit": {"requests_per_second": 5, "burst": 10}}`,
+			want: map[string]string{
+				"log.level":                     "debug",
+				"cors.allowed_origins":          "https://b.example",
+				"ratelimit.requests_per_second": "5",
+				"ratelimit.burst":               "10",
+			},
+			ignored: []string{},
+		},
+		{
+			name:     "restart required",
+			initial:  `{"http": {"port": "8080"}}`,
+			reloaded: `{"http": {"port": "9090"}, "features": {"enabled": ["search"]}}`,
+			want: map[string]string{
+				"http.port":        "8080",
+				"features.enabled": "search",
+			},
+			ignored: []string{"http.port"},
+		},
+		{
+			name:     "invalid keeps current",
+			initial:  `{"log": {"level": "warn"}}`,
+			reloaded: `{"log": {"level": "loud"}}`,
+			wantErr:  true,
+			want: map[string]string{
+				"log.level": "warn",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "config")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+
+			path := writeFile(t, dir, "config.json", tt.initial)
+			args := []string{"-config", path}
+			noEnv := func(string) (string, bool) { return "", false }
+
+			c, err := load(args, noEnv)
+			if err != nil {
+				t.Errorf("load() error = %v", err)
+				return
+			}
+			r := NewReloader(args, c)
+			r.lookup = noEnv
+			var notified *Config
+			r.OnReload(func(c *Config) {
+				notified = c
+				_ = r.Status()
+			})
+
+			writeFile(t, dir, "config.json", tt.reloaded)
+			if err := r.Reload(); (err != nil) != tt.wantErr {
+				t.Errorf("Reloader.Reload() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			values := r.Current().Values()
+			for k, v := range tt.want {
+				if values[k] != v {
+					t.Errorf("Reloader.Reload() %s = %v, want %v", k, values[k], v)
+				}
+			}
+
+			status := r.Status()
+			if tt.wantErr {
+				if status.Failures != 1 || len(status.LastError) == 0 || notified != nil {
+					t.Errorf("Reloader.Reload() status = %+v", status)
+				}
+				return
+			}
+			if status.Reloads != 1 || notified != r.Current() {
+				t.Errorf("Reloader.Reload() status = %+v", status)
+			}
+			if !reflect.DeepEqual(status.Ignored, tt.ignored) {
+				t.Errorf("Reloader.Reload() ignored = %v, want %v", status.Ignored, tt.ignored)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"crypto/tls"
+	"fmt"
+)
+
+// TLS contains the https configuration, the server uses plain http without a certificate
+type TLS struct {
+	CertFile     string
+	KeyFile      string
+	MinVersion   string
+	CipherPolicy string
+	ClientCAFile string
+	ClientAuth   string
+}
+
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// the intermediate policy only allows forward secret AEAD cipher suites for TLS 1.2
+var tlsCipherPolicies = map[string][]uint16{
+	"default": nil,
+	"modern":  nil,
+	"intermediate": {
+		tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+		tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+		tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+		tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+		tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305,
+		tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305,
+	},
+}
+
+// the empty client auth is implied by the client ca file, certificates are required when it is given
+var tlsClientAuth = map[string]tls.ClientAuthType{
+	"":        tls.NoClientCert,
+	"none":    tls.NoClientCert,
+	"request": tls.VerifyClientCertIfGiven,
+	"require": tls.RequireAndVerifyClientCert,
+}
+
+// Enabled returns if a certificate has been configured
+func (t *TLS) Enabled() bool {
+	return len(t.CertFile) > 0 || len(t.KeyFile) > 0
+}
+
+// Version returns the minimum tls version, the modern policy requires TLS 1.3
+func (t *TLS) Version() uint16 {
+	if t.CipherPolicy == "modern" {
+		return tls.VersionTLS13
+	}
+	return tlsVersions[t.MinVersion]
+}
+
+// CipherSuites returns the TLS 1.2 cipher suites of the policy, nil uses the go defaults
+func (t *TLS) CipherSuites() []uint16 {
+	return tlsCipherPolicies[t.CipherPolicy]
+}
+
+// ClientAuthType returns how client certificates are verified
+func (t *TLS) ClientAuthType() tls.ClientAuthType {
+	if len(t.ClientAuth) == 0 && len(t.ClientCAFile) > 0 {
+		return tls.RequireAndVerifyClientCert
+	}
+	return tlsClientAuth[t.ClientAuth]
+}
+
+func (t *TLS) validate() Errors {
+	errs := Errors{}
+	if _, has := tlsVersions[t.MinVersion]; has == false {
+		errs = append(errs, fmt.Errorf("tls.min_version %q must be 1.2 or 1.3", t.MinVersion))
+	}
+	if _, has := tlsCipherPolicies[t.CipherPolicy]; has == false {
+		errs = append(errs, fmt.Errorf("tls.cipher_policy %q must be default, intermediate or modern", t.CipherPolicy))
+	}
+	if _, has := tlsClientAuth[t.ClientAuth]; has == false {
+		errs = append(errs, fmt.Errorf("tls.client_auth %q must be empty, none, request or require", t.ClientAuth))
+	}
+	if (len(t.CertFile) > 0) != (len(t.KeyFile) > 0) {
+		errs = append(errs, fmt.Errorf("tls.cert_file and tls.key_file must be given together"))
+	}
+	if len(t.ClientCAFile) > 0 && t.Enabled() == false {
+		errs = append(errs, fmt.Errorf("tls.client_ca_file requires tls.cert_file and tls.key_file"))
+	}
+	switch {
+	case (t.ClientAuth == "request" || t.ClientAuth == "require") && len(t.ClientCAFile) == 0:
+		errs = append(errs, fmt.Errorf("tls.client_auth %q requires tls.client_ca_file", t.ClientAuth))
+	case t.ClientAuth == "none" && len(t.ClientCAFile) > 0:
+		errs = append(errs, fmt.Errorf("tls.client_auth \"none\" can not be used with tls.client_ca_file, leave it empty to require client certificates"))
+	}
+	return errs
+}
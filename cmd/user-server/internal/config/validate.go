@@ -0,0 +1,96 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	listeners      = []string{"tcp", "unix", "systemd"}
+	logLevels      = []string{"debug", "info", "warn", "error"}
+	traceExporters = []string{"", "stdout"}
+	// brotli and zstd are not in the standard library so the server does not offer them
+	encodings = []string{"gzip", "deflate"}
+)
+
+// Validate returns every problem with the configuration
+func (c *Config) Validate() Errors {
+	errs := Errors{}
+
+	if oneOf(c.HTTP.Listen, listeners) == false {
+		errs = append(errs, fmt.Errorf("http.listen %q must be one of %s", c.HTTP.Listen, strings.Join(listeners, ", ")))
+	}
+	if port, err := strconv.Atoi(c.HTTP.Port); err != nil || port < 0 || port > 65535 {
+		errs = append(errs, fmt.Errorf("http.port %q must be a number between 0 and 65535", c.HTTP.Port))
+	}
+	if c.HTTP.Listen == "unix" && len(c.HTTP.Socket) == 0 {
+		errs = append(errs, fmt.Errorf("http.socket is required to listen on unix"))
+	}
+	if c.HTTP.H2C && c.TLS.Enabled() {
+		errs = append(errs, fmt.Errorf("http.h2c can not be used with tls, https negotiates http/2"))
+	}
+	if c.HTTP.ReadTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("http.read_timeout %s must be positive", c.HTTP.ReadTimeout))
+	}
+	if c.HTTP.WriteTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("http.write_timeout %s must be positive", c.HTTP.WriteTimeout))
+	}
+	if c.HTTP.DrainDelay < 0 {
+		errs = append(errs, fmt.Errorf("http.drain_delay %s can not be negative", c.HTTP.DrainDelay))
+	}
+	if c.HTTP.MaxBodySize < 1 {
+		errs = append(errs, fmt.Errorf("http.max_body_size %d must be positive", c.HTTP.MaxBodySize))
+	}
+	if c.Lifecycle.StopTimeout <= c.HTTP.DrainDelay {
+		errs = append(errs, fmt.Errorf("lifecycle.stop_timeout %s must be longer than http.drain_delay %s", c.Lifecycle.StopTimeout, c.HTTP.DrainDelay))
+	}
+	if oneOf(strings.ToLower(c.Log.Level), logLevels) == false {
+		errs = append(errs, fmt.Errorf("log.level %q must be one of %s", c.Log.Level, strings.Join(logLevels, ", ")))
+	}
+	if oneOf(c.Trace.Exporter, traceExporters) == false {
+		errs = append(errs, fmt.Errorf("trace.exporter %q must be empty or stdout", c.Trace.Exporter))
+	}
+	if c.RateLimit.RequestsPerSecond < 0 {
+		errs = append(errs, fmt.Errorf("ratelimit.requests_per_second %v can not be negative", c.RateLimit.RequestsPerSecond))
+	}
+	if c.RateLimit.Burst < 1 {
+		errs = append(errs, fmt.Errorf("ratelimit.burst %d must be at least 1", c.RateLimit.Burst))
+	}
+	for _, origin := range c.CORS.AllowedOrigins {
+		if origin != "*" && strings.HasPrefix(origin, "http://") == false && strings.HasPrefix(origin, "https://") == false {
+			errs = append(errs, fmt.Errorf("cors.allowed_origins %q must be * or an http(s) origin", origin))
+		}
+		if origin == "*" && c.CORS.AllowCredentials {
+			errs = append(errs, fmt.Errorf("cors.allowed_origins * can not be used with cors.allow_credentials"))
+		}
+	}
+	if c.CORS.MaxAge < 0 {
+		errs = append(errs, fmt.Errorf("cors.max_age %s can not be negative", c.CORS.MaxAge))
+	}
+	for _, e := range c.Compression.Encodings {
+		if oneOf(e, encodings) == false {
+			errs = append(errs, fmt.Errorf("compression.encodings %q must be one of %s", e, strings.Join(encodings, ", ")))
+		}
+	}
+	if c.Compression.MinSize < 0 {
+		errs = append(errs, fmt.Errorf("compression.min_size %d can not be negative", c.Compression.MinSize))
+	}
+	if c.Compression.MaxRequestSize < 1 {
+		errs = append(errs, fmt.Errorf("compression.max_request_size %d must be positive", c.Compression.MaxRequestSize))
+	}
+	errs = append(errs, c.TLS.validate()...)
+	if len(c.Database.DSN) == 0 {
+		errs = append(errs, fmt.Errorf("database.dsn is required"))
+	}
+	return errs
+}
+
+func oneOf(v string, allowed []string) bool {
+	for _, a := range allowed {
+		if v == a {
+			return true
+		}
+	}
+	return false
+}
@@ -2,24 +2,153 @@ package database
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"fmt"
+	"strings"
 
 	_ "github.com/mattn/go-sqlite3" // placing the database import within the package that it initialized
 )
 
-// Open will open a database and execute a series of statments
-func Open(ctx context.Context, stmts []string) (*sql.DB, error) {
-	db, err := sql.Open("sqlite3", "file::memory:?mode=memory")
+const migrationTable = `
+CREATE TABLE IF NOT EXISTS schema_migration (
+	version INTEGER NOT NULL,
+	checksum CHAR(64) NOT NULL,
+	applied_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (version)
+)
+`
+
+// Driver is the name of the database driver
+const Driver = "sqlite3"
+
+// Open will open a database and execute the series of statments that have not been applied,
+// an applied statement that has since changed is an error as it is not applied again
+func Open(ctx context.Context, dsn string, stmts []string) (*sql.DB, error) {
+	db, err := sql.Open(Driver, dsn)
 	if err != nil {
 		return nil, fmt.Errorf("sqlite database open error %w", err)
 	}
+	// every connection to an in memory database is a new database
+	if strings.Contains(dsn, ":memory:") || strings.Contains(dsn, "mode=memory") {
+		db.SetMaxOpenConns(1)
+	}
+
+	if _, err := db.ExecContext(ctx, migrationTable); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("sqlite database migration table error %w", err)
+	}
 
-	for _, stmt := range stmts {
-		if _, err := db.ExecContext(ctx, stmt); err != nil {
+	applied, err := appliedMigrations(ctx, db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	if err := changedMigration(applied, stmts); err != nil {
+		db.Close()
+		return nil, err
+	}
+	for i, stmt := range stmts {
+		if _, ok := applied[i+1]; ok {
+			continue
+		}
+		if err := migrate(ctx, db, i+1, stmt); err != nil {
 			db.Close()
-			return nil, fmt.Errorf("sqlite database statment (%s) error %w", stmt, err)
+			return nil, err
 		}
 	}
 	return db, nil
 }
+
+// Migrated will return an error when any of the statements have not been applied
+func Migrated(ctx context.Context, db *sql.DB, stmts []string) error {
+	applied, err := appliedMigrations(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	if err := changedMigration(applied, stmts); err != nil {
+		return err
+	}
+	pending := 0
+	for i := range stmts {
+		if _, ok := applied[i+1]; ok == false {
+			pending++
+		}
+	}
+	if pending > 0 {
+		return fmt.Errorf("sqlite database has %d pending migrations", pending)
+	}
+	return nil
+}
+
+// changedMigration returns an error when an applied statement is not the statement of its version,
+// a shipped migration is changed by appending a new one
+func changedMigration(applied map[int]string, stmts []string) error {
+	for i, stmt := range stmts {
+		if sum, ok := applied[i+1]; ok && sum != checksum(stmt) {
+			return fmt.Errorf("sqlite database migration %d has changed since it was applied", i+1)
+		}
+	}
+	return nil
+}
+
+// migrate applies the statement and records its version in a transaction, a failed migration leaves no changes
+func migrate(ctx context.Context, db *sql.DB, version int, stmt string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("sqlite database migration begin error %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, stmt); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("sqlite database statment (%s) error %w", stmt, err)
+	}
+	const record = `INSERT INTO schema_migration (version, checksum) VALUES (?, ?)`
+	if _, err := tx.ExecContext(ctx, record, version, checksum(stmt)); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("sqlite database migration record error %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("sqlite database migration commit error %w", err)
+	}
+	return nil
+}
+
+// appliedMigrations returns the checksum of the applied migrations by version
+func appliedMigrations(ctx context.Context, db *sql.DB) (map[int]string, error) {
+	const query = `SELECT version, checksum FROM schema_migration`
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite database migration query error %w", err)
+	}
+	defer rows.Close()
+
+	applied := map[int]string{}
+	for rows.Next() {
+		var version int
+		var sum string
+		if err := rows.Scan(&version, &sum); err != nil {
+			return nil, fmt.Errorf("sqlite database migration scan error %w", err)
+		}
+		applied[version] = sum
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("sqlite database migration rows error %w", err)
+	}
+	return applied, nil
+}
+
+// Version returns the version reported by the database
+func Version(ctx context.Context, db *sql.DB) (string, error) {
+	var version string
+	if err := db.QueryRowContext(ctx, `SELECT sqlite_version()`).Scan(&version); err != nil {
+		return "", fmt.Errorf("sqlite database version error %w", err)
+	}
+	return version, nil
+}
+
+func checksum(stmt string) string {
+	sum := sha256.Sum256([]byte(stmt))
+	return hex.EncodeToString(sum[:])
+}
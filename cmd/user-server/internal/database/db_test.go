@@ -0,0 +1,87 @@
+package database
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOpen_Reopen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "database")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	dsn := "file:" + filepath.Join(dir, "test.db")
+
+	stmts := []string{
+		`CREATE TABLE item (id INTEGER NOT NULL PRIMARY KEY)`,
+		`ALTER TABLE item ADD COLUMN name TEXT; CREATE UNIQUE INDEX item_name ON item (name);`,
+	}
+	for i := 0; i < 2; i++ {
+		db, err := Open(context.Background(), dsn, stmts)
+		if err != nil {
+			t.Errorf("Open() %d error = %v", i, err)
+			return
+		}
+		if err := Migrated(context.Background(), db, stmts); err != nil {
+			t.Errorf("Migrated() %d error = %v", i, err)
+		}
+		db.Close()
+	}
+
+	// a failed migration is rolled back and is retried on the next open
+	failing := append(stmts, `ALTER TABLE item ADD COLUMN size INTEGER; ALTER TABLE missing ADD COLUMN size INTEGER;`)
+	if _, err := Open(context.Background(), dsn, failing); err == nil {
+		t.Errorf("Open() expected a migration error")
+		return
+	}
+	fixed := append(stmts, `ALTER TABLE item ADD COLUMN size INTEGER;`)
+	db, err := Open(context.Background(), dsn, fixed)
+	if err != nil {
+		t.Errorf("Open() error = %v", err)
+		return
+	}
+	defer db.Close()
+	if err := Migrated(context.Background(), db, fixed); err != nil {
+		t.Errorf("Migrated() error = %v", err)
+	}
+}
+
+func TestOpen_ChangedMigration(t *testing.T) {
+	dir, err := ioutil.TempDir("", "database")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	dsn := "file:" + filepath.Join(dir, "test.db")
+
+	stmts := []string{
+		`CREATE TABLE item (id INTEGER NOT NULL PRIMARY KEY, name TEXT)`,
+		`CREATE UNIQUE INDEX item_name ON item (name)`,
+	}
+	db, err := Open(context.Background(), dsn, stmts)
+	if err != nil {
+		t.Errorf("Open() error = %v", err)
+		return
+	}
+	db.Close()
+
+	changed := []string{stmts[0], `CREATE UNIQUE INDEX item_name ON item (name) WHERE id > 0`}
+	if db, err := Open(context.Background(), dsn, changed); err == nil {
+		db.Close()
+		t.Errorf("Open() expected a changed migration error")
+	}
+
+	db, err = Open(context.Background(), dsn, stmts)
+	if err != nil {
+		t.Errorf("Open() error = %v", err)
+		return
+	}
+	defer db.Close()
+	if err := Migrated(context.Background(), db, changed); err == nil {
+		t.Errorf("Migrated() expected a changed migration error")
+	}
+}
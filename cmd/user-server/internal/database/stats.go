@@ -0,0 +1,35 @@
+package database
+
+import (
+	"database/sql"
+
+	"github.com/g8rswimmer/go-data-access-example/pkg/metrics"
+)
+
+// RegisterStats will expose the connection pool statistics as metrics
+func RegisterStats(reg *metrics.Registry, db *sql.DB) {
+	reg.NewGaugeFunc("db_max_open_connections", "Maximum number of open connections to the database.", func() float64 {
+		return float64(db.Stats().MaxOpenConnections)
+	})
+	reg.NewGaugeFunc("db_open_connections", "The number of established connections both in use and idle.", func() float64 {
+		return float64(db.Stats().OpenConnections)
+	})
+	reg.NewGaugeFunc("db_in_use_connections", "The number of connections currently in use.", func() float64 {
+		return float64(db.Stats().InUse)
+	})
+	reg.NewGaugeFunc("db_idle_connections", "The number of idle connections.", func() float64 {
+		return float64(db.Stats().Idle)
+	})
+	reg.NewCounterFunc("db_wait_count_total", "The total number of connections waited for.", func() float64 {
+		return float64(db.Stats().WaitCount)
+	})
+	reg.NewCounterFunc("db_wait_duration_seconds_total", "The total time blocked waiting for a new connection.", func() float64 {
+		return db.Stats().WaitDuration.Seconds()
+	})
+	reg.NewCounterFunc("db_max_idle_closed_total", "The total number of connections closed due to max idle connections.", func() float64 {
+		return float64(db.Stats().MaxIdleClosed)
+	})
+	reg.NewCounterFunc("db_max_lifetime_closed_total", "The total number of connections closed due to max connection lifetime.", func() float64 {
+		return float64(db.Stats().MaxLifetimeClosed)
+	})
+}
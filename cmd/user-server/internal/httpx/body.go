@@ -0,0 +1,35 @@
+package httpx
+
+import (
+	"net/http"
+
+	"github.com/g8rswimmer/go-data-access-example/pkg/api/response"
+	"github.com/g8rswimmer/go-data-access-example/pkg/errorx"
+)
+
+// bodyLimit will refuse request bodies larger than max bytes, zero leaves them unlimited
+func bodyLimit(max int64) Middleware {
+	return func(next http.Handler) http.Handler {
+		if max <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > max {
+				msg := &problem{
+					ID:      RequestID(r.Context()),
+					Error:   errorx.ErrBodyTooLarge.Error(),
+					Message: "request body is larger than the limit",
+				}
+				response.JSON(w, http.StatusRequestEntityTooLarge, msg)
+				return
+			}
+			// a body without a length is stopped once it passes the limit
+			r.Body = &limitedBody{
+				ReadCloser: r.Body,
+				remaining:  max,
+				limited:    true,
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
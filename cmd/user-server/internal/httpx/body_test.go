@@ -0,0 +1,64 @@
+package httpx
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/g8rswimmer/go-data-access-example/pkg/errorx"
+)
+
+func TestBodyLimit(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		contentLength int64
+		status        int
+		wantErr       error
+	}{
+		{
+			name:          "within limit",
+			body:          strings.Repeat("a", 16),
+			contentLength: 16,
+			status:        http.StatusOK,
+		},
+		{
+			name:          "content length over limit",
+			body:          strings.Repeat("a", 32),
+			contentLength: 32,
+			status:        http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:          "unknown length over limit",
+			body:          strings.Repeat("a", 32),
+			contentLength: -1,
+			status:        http.StatusOK,
+			wantErr:       errorx.ErrBodyTooLarge,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var readErr error
+			handler := bodyLimit(16)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, readErr = ioutil.ReadAll(r.Body)
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "http://localhost:8080/v1/user", strings.NewReader(tt.body))
+			req.ContentLength = tt.contentLength
+			writer := httptest.NewRecorder()
+			handler.ServeHTTP(writer, req)
+
+			if writer.Result().StatusCode != tt.status {
+				t.Errorf("bodyLimit() = %v, want %v", writer.Result().StatusCode, tt.status)
+				return
+			}
+			if errors.Is(readErr, tt.wantErr) == false || (tt.wantErr == nil && readErr != nil) {
+				t.Errorf("bodyLimit() body read error = %v, want %v", readErr, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,345 @@
+package httpx
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/g8rswimmer/go-data-access-example/pkg/api/response"
+	"github.com/g8rswimmer/go-data-access-example/pkg/errorx"
+)
+
+// Encoder creates a writer that compresses to w with a content coding
+type Encoder func(w io.Writer) (io.WriteCloser, error)
+
+// Compression contains the response compression settings
+type Compression struct {
+	// MinSize is the smallest response body that is compressed
+	MinSize int
+	// ContentTypes are the media types that are compressed
+	ContentTypes []string
+	// Encoders are the content codings that can be used, gzip and deflate when nil
+	Encoders map[string]Encoder
+	// MaxRequestSize limits the decompressed size of a request body, zero leaves it unlimited
+	MaxRequestSize int64
+}
+
+// encodingPreference orders the codings when the client accepts them equally, codings without an encoder are skipped
+var encodingPreference = []string{"br", "zstd", "gzip", "deflate"}
+
+// DefaultEncoders returns the codings available from the standard library
+func DefaultEncoders() map[string]Encoder {
+	return map[string]Encoder{
+		"gzip": func(w io.Writer) (io.WriteCloser, error) {
+			return gzip.NewWriterLevel(w, gzip.DefaultCompression)
+		},
+		"deflate": func(w io.Writer) (io.WriteCloser, error) {
+			return flate.NewWriter(w, flate.DefaultCompression)
+		},
+	}
+}
+
+func (c *Compression) encoders() map[string]Encoder {
+	if c.Encoders == nil {
+		return DefaultEncoders()
+	}
+	return c.Encoders
+}
+
+// negotiate returns the content coding to use from the Accept-Encoding header, empty for identity
+func (c *Compression) negotiate(acceptEncoding string) string {
+	if len(acceptEncoding) == 0 {
+		return ""
+	}
+	accepted := map[string]float64{}
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		params := strings.Split(part, ";")
+		coding := strings.ToLower(strings.TrimSpace(params[0]))
+		if len(coding) == 0 {
+			continue
+		}
+		q := 1.0
+		for _, p := range params[1:] {
+			p = strings.TrimSpace(p)
+			if strings.HasPrefix(p, "q=") {
+				if v, err := strconv.ParseFloat(p[2:], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		accepted[coding] = q
+	}
+
+	encoders := c.encoders()
+	codings := []string{}
+	for _, coding := range encodingPreference {
+		if _, has := encoders[coding]; has {
+			codings = append(codings, coding)
+		}
+	}
+	// codings that were registered without a preference are tried last in name order
+	others := []string{}
+	for coding := range encoders {
+		if indexOf(codings, coding) < 0 {
+			others = append(others, coding)
+		}
+	}
+	sort.Strings(others)
+	codings = append(codings, others...)
+
+	best, bestQ := "", 0.0
+	for _, coding := range codings {
+		q, has := accepted[coding]
+		if has == false {
+			q, has = accepted["*"]
+		}
+		if has && q > bestQ {
+			best, bestQ = coding, q
+		}
+	}
+	return best
+}
+
+func (c *Compression) compressible(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, t := range c.ContentTypes {
+		if strings.EqualFold(t, mediaType) {
+			return true
+		}
+	}
+	return false
+}
+
+func indexOf(items []string, item string) int {
+	for i, it := range items {
+		if it == item {
+			return i
+		}
+	}
+	return -1
+}
+
+// compress will compress the responses the client accepts and decompress the request bodies
+func compress(c *Compression) Middleware {
+	return func(next http.Handler) http.Handler {
+		if c == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := decompressBody(r, c.MaxRequestSize); err != nil {
+				msg := &problem{
+					ID:      RequestID(r.Context()),
+					Error:   err.Error(),
+					Message: "request body can not be decompressed",
+				}
+				status := http.StatusBadRequest
+				if errors.Is(err, errUnsupportedEncoding) {
+					w.Header().Set("Accept-Encoding", "gzip, deflate")
+					status = http.StatusUnsupportedMediaType
+				}
+				response.JSON(w, status, msg)
+				return
+			}
+
+			w.Header().Add("Vary", "Accept-Encoding")
+			coding := c.negotiate(r.Header.Get("Accept-Encoding"))
+			if len(coding) == 0 || r.Method == http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cw := &compressWriter{
+				ResponseWriter: w,
+				compression:    c,
+				coding:         coding,
+				status:         http.StatusOK,
+			}
+			defer cw.Close()
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+// compressWriter buffers the response until it is known to be large enough to compress
+type compressWriter struct {
+	http.ResponseWriter
+	compression *Compression
+	coding      string
+	status      int
+	wroteHeader bool
+	buf         []byte
+	decided     bool
+	zw          io.WriteCloser
+}
+
+func (cw *compressWriter) WriteHeader(status int) {
+	if cw.wroteHeader {
+		return
+	}
+	cw.wroteHeader = true
+	cw.status = status
+	// responses without a body are sent as is
+	if status == http.StatusNoContent || status == http.StatusNotModified {
+		_ = cw.decide(false)
+	}
+}
+
+func (cw *compressWriter) Write(b []byte) (int, error) {
+	cw.wroteHeader = true
+	if cw.decided == false {
+		cw.buf = append(cw.buf, b...)
+		if len(cw.buf) < cw.compression.MinSize {
+			return len(b), nil
+		}
+		if err := cw.decide(true); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+	if cw.zw != nil {
+		return cw.zw.Write(b)
+	}
+	return cw.ResponseWriter.Write(b)
+}
+
+// decide will send the header, compressing when the body is large enough and the response allows it
+func (cw *compressWriter) decide(large bool) error {
+	if cw.decided {
+		return nil
+	}
+	cw.decided = true
+
+	h := cw.Header()
+	if large && len(h.Get("Content-Encoding")) == 0 && cw.compression.compressible(h.Get("Content-Type")) {
+		zw, err := cw.compression.encoders()[cw.coding](cw.ResponseWriter)
+		if err != nil {
+			return fmt.Errorf("compress %s %w", cw.coding, err)
+		}
+		cw.zw = zw
+		h.Set("Content-Encoding", cw.coding)
+		h.Del("Content-Length")
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+
+	buf := cw.buf
+	cw.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	var err error
+	if cw.zw != nil {
+		_, err = cw.zw.Write(buf)
+	} else {
+		_, err = cw.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+// Close will send a small buffered response and finish the compressed stream
+func (cw *compressWriter) Close() error {
+	if cw.decided == false {
+		if cw.wroteHeader == false {
+			return nil
+		}
+		if err := cw.decide(len(cw.buf) > 0 && len(cw.buf) >= cw.compression.MinSize); err != nil {
+			return err
+		}
+	}
+	if cw.zw != nil {
+		return cw.zw.Close()
+	}
+	return nil
+}
+
+// Flush sends the buffered response, compressing it when it can be
+func (cw *compressWriter) Flush() {
+	if err := cw.decide(true); err != nil {
+		return
+	}
+	if f, ok := cw.zw.(interface{ Flush() error }); ok {
+		_ = f.Flush()
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// errUnsupportedEncoding is returned for a request body in a coding that can not be decompressed
+var errUnsupportedEncoding = errors.New("unsupported content encoding")
+
+// decompressBody will replace a gzip or deflate request body with one that decompresses up to the limit
+func decompressBody(r *http.Request, limit int64) error {
+	coding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding")))
+	var body io.ReadCloser
+	switch coding {
+	case "", "identity":
+		return nil
+	case "gzip":
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return fmt.Errorf("gzip request body %w", err)
+		}
+		body = zr
+	case "deflate":
+		body = flate.NewReader(r.Body)
+	default:
+		return fmt.Errorf("%w %s", errUnsupportedEncoding, coding)
+	}
+
+	r.Body = &limitedBody{
+		ReadCloser: body,
+		source:     r.Body,
+		remaining:  limit,
+		limited:    limit > 0,
+	}
+	r.Header.Del("Content-Encoding")
+	r.Header.Del("Content-Length")
+	r.ContentLength = -1
+	return nil
+}
+
+// limitedBody fails the read once more than the limit has been read
+type limitedBody struct {
+	io.ReadCloser
+	// source is the compressed body closed along with the reader, if any
+	source    io.Closer
+	remaining int64
+	limited   bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.limited == false {
+		return b.ReadCloser.Read(p)
+	}
+	if b.remaining < 0 {
+		return 0, errorx.ErrBodyTooLarge
+	}
+	// read one byte past the limit to tell a body of exactly the limit from a larger one
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	if b.remaining < 0 {
+		return n + int(b.remaining), errorx.ErrBodyTooLarge
+	}
+	return n, err
+}
+
+func (b *limitedBody) Close() error {
+	err := b.ReadCloser.Close()
+	if b.source != nil {
+		return b.source.Close()
+	}
+	return err
+}
@@ -0,0 +1,259 @@
+package httpx
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/g8rswimmer/go-data-access-example/pkg/errorx"
+)
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
+
+func TestCompression_Negotiate(t *testing.T) {
+	withBrotli := DefaultEncoders()
+	withBrotli["br"] = func(w io.Writer) (io.WriteCloser, error) { return nopCloser{w}, nil }
+	tests := []struct {
+		name     string
+		encoders map[string]Encoder
+		accept   string
+		want     string
+	}{
+		{
+			name:   "none",
+			accept: "",
+			want:   "",
+		},
+		{
+			name:   "gzip",
+			accept: "gzip",
+			want:   "gzip",
+		},
+		{
+			name:   "quality",
+			accept: "gzip;q=0.5, deflate;q=0.8",
+			want:   "deflate",
+		},
+		{
+			name:   "server preference",
+			accept: "deflate, gzip",
+			want:   "gzip",
+		},
+		{
+			name:   "refused",
+			accept: "gzip;q=0, deflate;q=0",
+			want:   "",
+		},
+		{
+			name:   "wildcard",
+			accept: "*",
+			want:   "gzip",
+		},
+		{
+			name:   "unavailable coding",
+			accept: "br, zstd",
+			want:   "",
+		},
+		{
+			name:     "registered coding",
+			encoders: withBrotli,
+			accept:   "gzip, br",
+			want:     "br",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Compression{Encoders: tt.encoders}
+			if got := c.negotiate(tt.accept); got != tt.want {
+				t.Errorf("Compression.negotiate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompress_Response(t *testing.T) {
+	large := `{"users":"` + strings.Repeat("a", 2048) + `"}`
+	type args struct {
+		accept      string
+		contentType string
+		body        string
+	}
+	tests := []struct {
+		name     string
+		args     args
+		encoding string
+	}{
+		{
+			name: "compressed",
+			args: args{
+				accept:      "gzip",
+				contentType: "application/json",
+				body:        large,
+			},
+			encoding: "gzip",
+		},
+		{
+			name: "below minimum size",
+			args: args{
+				accept:      "gzip",
+				contentType: "application/json",
+				body:        `{"id":"1"}`,
+			},
+		},
+		{
+			name: "content type not allowed",
+			args: args{
+				accept:      "gzip",
+				contentType: "image/png",
+				body:        large,
+			},
+		},
+		{
+			name: "not accepted",
+			args: args{
+				contentType: "application/json",
+				body:        large,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Compression{
+				MinSize:      1024,
+				ContentTypes: []string{"application/json"},
+			}
+			handler := compress(c)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.args.contentType+"; charset=utf-8")
+				w.WriteHeader(http.StatusOK)
+				// written in parts to buffer across the minimum size
+				_, _ = w.Write([]byte(tt.args.body[:len(tt.args.body)/2]))
+				_, _ = w.Write([]byte(tt.args.body[len(tt.args.body)/2:]))
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "http://localhost:8080/v1/users", nil)
+			if len(tt.args.accept) > 0 {
+				req.Header.Set("Accept-Encoding", tt.args.accept)
+			}
+			writer := httptest.NewRecorder()
+			handler.ServeHTTP(writer, req)
+
+			if got := writer.Header().Get("Content-Encoding"); got != tt.encoding {
+				t.Errorf("compress() Content-Encoding = %v, want %v", got, tt.encoding)
+				return
+			}
+			if writer.Header().Get("Vary") != "Accept-Encoding" {
+				t.Errorf("compress() Vary = %v, want Accept-Encoding", writer.Header().Get("Vary"))
+			}
+			var body io.Reader = writer.Body
+			if len(tt.encoding) > 0 {
+				zr, err := gzip.NewReader(writer.Body)
+				if err != nil {
+					t.Errorf("compress() gzip error = %v", err)
+					return
+				}
+				body = zr
+			}
+			got, _ := ioutil.ReadAll(body)
+			if string(got) != tt.args.body {
+				t.Errorf("compress() body length = %v, want %v", len(got), len(tt.args.body))
+			}
+		})
+	}
+}
+
+func gzipped(data string) []byte {
+	buf := &bytes.Buffer{}
+	zw := gzip.NewWriter(buf)
+	_, _ = zw.Write([]byte(data))
+	_ = zw.Close()
+	return buf.Bytes()
+}
+
+func TestCompress_Request(t *testing.T) {
+	type args struct {
+		encoding string
+		body     []byte
+	}
+	tests := []struct {
+		name    string
+		args    args
+		status  int
+		want    string
+		wantErr error
+	}{
+		{
+			name: "gzip body",
+			args: args{
+				encoding: "gzip",
+				body:     gzipped(`{"first_name":"test"}`),
+			},
+			status: http.StatusOK,
+			want:   `{"first_name":"test"}`,
+		},
+		{
+			name: "decompression bomb",
+			args: args{
+				encoding: "gzip",
+				body:     gzipped(strings.Repeat("0", 1<<20)),
+			},
+			status:  http.StatusOK,
+			want:    strings.Repeat("0", 64),
+			wantErr: errorx.ErrBodyTooLarge,
+		},
+		{
+			name: "corrupt gzip",
+			args: args{
+				encoding: "gzip",
+				body:     []byte("not gzip"),
+			},
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "unsupported encoding",
+			args: args{
+				encoding: "compress",
+				body:     []byte("data"),
+			},
+			status: http.StatusUnsupportedMediaType,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []byte
+			var readErr error
+			handler := compress(&Compression{MaxRequestSize: 64})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got, readErr = ioutil.ReadAll(r.Body)
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "http://localhost:8080/v1/user", bytes.NewReader(tt.args.body))
+			req.Header.Set("Content-Encoding", tt.args.encoding)
+			writer := httptest.NewRecorder()
+			handler.ServeHTTP(writer, req)
+
+			if writer.Result().StatusCode != tt.status {
+				t.Errorf("compress() = %v, want %v", writer.Result().StatusCode, tt.status)
+				return
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			if errors.Is(readErr, tt.wantErr) == false || (tt.wantErr == nil && readErr != nil) {
+				t.Errorf("compress() body read error = %v, want %v", readErr, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("compress() body length = %v, want %v", len(got), len(tt.want))
+			}
+		})
+	}
+}
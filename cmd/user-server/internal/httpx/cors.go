@@ -0,0 +1,148 @@
+package httpx
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CORSPolicy contains the cross origin requests that are allowed
+type CORSPolicy struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+// CORS holds the cross origin policy, it can be changed while the server runs
+type CORS struct {
+	mu     sync.RWMutex
+	policy CORSPolicy
+}
+
+// headers a browser script may read from a cross origin response
+var corsExposedHeaders = []string{RequestIDHeader, "Retry-After"}
+
+// NewCORS creates the cross origin policy, no allowed origins disables it
+func NewCORS(p CORSPolicy) *CORS {
+	return &CORS{
+		policy: p,
+	}
+}
+
+// SetPolicy will change the policy for the following requests
+func (c *CORS) SetPolicy(p CORSPolicy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.policy = p
+}
+
+// Policy returns the current policy
+func (c *CORS) Policy() CORSPolicy {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.policy
+}
+
+// allowOrigin returns the allow origin header value, empty when the origin is not allowed
+func (p CORSPolicy) allowOrigin(origin string) string {
+	for _, o := range p.AllowedOrigins {
+		switch {
+		case o == "*" && p.AllowCredentials == false:
+			return "*"
+		case strings.EqualFold(o, origin):
+			return origin
+		}
+	}
+	return ""
+}
+
+func (p CORSPolicy) allowMethod(method string) bool {
+	for _, m := range p.AllowedMethods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+func (p CORSPolicy) allowHeaders(requested string) bool {
+	for _, h := range strings.Split(requested, ",") {
+		if h = strings.TrimSpace(h); len(h) == 0 {
+			continue
+		}
+		allowed := false
+		for _, a := range p.AllowedHeaders {
+			if strings.EqualFold(a, h) {
+				allowed = true
+				break
+			}
+		}
+		if allowed == false {
+			return false
+		}
+	}
+	return true
+}
+
+// cors will answer the preflight requests and add the cross origin headers to the requests under the prefix
+func cors(c *CORS, prefix string) Middleware {
+	return func(next http.Handler) http.Handler {
+		if c == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if len(origin) == 0 || strings.HasPrefix(r.URL.Path, prefix) == false {
+				next.ServeHTTP(w, r)
+				return
+			}
+			p := c.Policy()
+			if len(p.AllowedOrigins) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h := w.Header()
+			h.Add("Vary", "Origin")
+			allowOrigin := p.allowOrigin(origin)
+			preflight := r.Method == http.MethodOptions && len(r.Header.Get("Access-Control-Request-Method")) > 0
+
+			if preflight == false {
+				if len(allowOrigin) > 0 {
+					h.Set("Access-Control-Allow-Origin", allowOrigin)
+					h.Set("Access-Control-Expose-Headers", strings.Join(corsExposedHeaders, ", "))
+					if p.AllowCredentials {
+						h.Set("Access-Control-Allow-Credentials", "true")
+					}
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h.Add("Vary", "Access-Control-Request-Method")
+			h.Add("Vary", "Access-Control-Request-Headers")
+			requestHeaders := r.Header.Get("Access-Control-Request-Headers")
+			if len(allowOrigin) == 0 || p.allowMethod(r.Header.Get("Access-Control-Request-Method")) == false || p.allowHeaders(requestHeaders) == false {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			h.Set("Access-Control-Allow-Origin", allowOrigin)
+			h.Set("Access-Control-Allow-Methods", strings.Join(p.AllowedMethods, ", "))
+			if len(requestHeaders) > 0 {
+				h.Set("Access-Control-Allow-Headers", strings.Join(p.AllowedHeaders, ", "))
+			}
+			if p.AllowCredentials {
+				h.Set("Access-Control-Allow-Credentials", "true")
+			}
+			if p.MaxAge > 0 {
+				h.Set("Access-Control-Max-Age", strconv.Itoa(int(p.MaxAge.Seconds())))
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
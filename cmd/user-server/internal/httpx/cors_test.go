@@ -0,0 +1,233 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func TestCORS(t *testing.T) {
+	policy := CORSPolicy{
+		AllowedOrigins:   []string{"https://admin.example.com"},
+		AllowedMethods:   []string{http.MethodGet, http.MethodPatch},
+		AllowedHeaders:   []string{"Content-Type", "X-Request-ID"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	}
+	type args struct {
+		method  string
+		target  string
+		headers map[string]string
+	}
+	tests := []struct {
+		name    string
+		policy  CORSPolicy
+		args    args
+		status  int
+		headers map[string]string
+	}{
+		{
+			name:   "same origin",
+			policy: policy,
+			args: args{
+				method: http.MethodGet,
+				target: "/v1/users",
+			},
+			status: http.StatusOK,
+			headers: map[string]string{
+				"Access-Control-Allow-Origin": "",
+			},
+		},
+		{
+			name:   "preflight",
+			policy: policy,
+			args: args{
+				method: http.MethodOptions,
+				target: "/v1/users/123",
+				headers: map[string]string{
+					"Origin":                         "https://admin.example.com",
+					"Access-Control-Request-Method":  http.MethodPatch,
+					"Access-Control-Request-Headers": "content-type",
+				},
+			},
+			status: http.StatusNoContent,
+			headers: map[string]string{
+				"Access-Control-Allow-Origin":      "https://admin.example.com",
+				"Access-Control-Allow-Methods":     "GET, PATCH",
+				"Access-Control-Allow-Headers":     "Content-Type, X-Request-ID",
+				"Access-Control-Allow-Credentials": "true",
+				"Access-Control-Max-Age":           "600",
+			},
+		},
+		{
+			name:   "preflight origin not allowed",
+			policy: policy,
+			args: args{
+				method: http.MethodOptions,
+				target: "/v1/users",
+				headers: map[string]string{
+					"Origin":                        "https://evil.example.com",
+					"Access-Control-Request-Method": http.MethodGet,
+				},
+			},
+			status: http.StatusForbidden,
+			headers: map[string]string{
+				"Access-Control-Allow-Origin": "",
+			},
+		},
+		{
+			name:   "preflight method not allowed",
+			policy: policy,
+			args: args{
+				method: http.MethodOptions,
+				target: "/v1/users/123",
+				headers: map[string]string{
+					"Origin":                        "https://admin.example.com",
+					"Access-Control-Request-Method": http.MethodDelete,
+				},
+			},
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "preflight header not allowed",
+			policy: policy,
+			args: args{
+				method: http.MethodOptions,
+				target: "/v1/users",
+				headers: map[string]string{
+					"Origin":                         "https://admin.example.com",
+					"Access-Control-Request-Method":  http.MethodGet,
+					"Access-Control-Request-Headers": "X-Debug",
+				},
+			},
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "cross origin request",
+			policy: policy,
+			args: args{
+				method: http.MethodGet,
+				target: "/v1/users",
+				headers: map[string]string{
+					"Origin": "https://ADMIN.example.com",
+				},
+			},
+			status: http.StatusOK,
+			headers: map[string]string{
+				"Access-Control-Allow-Origin":      "https://ADMIN.example.com",
+				"Access-Control-Allow-Credentials": "true",
+				"Access-Control-Expose-Headers":    "X-Request-ID, Retry-After",
+				"Vary":                             "Origin",
+			},
+		},
+		{
+			name: "any origin",
+			policy: CORSPolicy{
+				AllowedOrigins: []string{"*"},
+			},
+			args: args{
+				method: http.MethodGet,
+				target: "/v1/users",
+				headers: map[string]string{
+					"Origin": "https://admin.example.com",
+				},
+			},
+			status: http.StatusOK,
+			headers: map[string]string{
+				"Access-Control-Allow-Origin":      "*",
+				"Access-Control-Allow-Credentials": "",
+			},
+		},
+		{
+			name:   "outside the api",
+			policy: policy,
+			args: args{
+				method: http.MethodGet,
+				target: "/metrics",
+				headers: map[string]string{
+					"Origin": "https://admin.example.com",
+				},
+			},
+			status: http.StatusOK,
+			headers: map[string]string{
+				"Access-Control-Allow-Origin": "",
+			},
+		},
+		{
+			name: "disabled",
+			args: args{
+				method: http.MethodOptions,
+				target: "/v1/users",
+				headers: map[string]string{
+					"Origin":                        "https://admin.example.com",
+					"Access-Control-Request-Method": http.MethodGet,
+				},
+			},
+			status: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := cors(NewCORS(tt.policy), "/v1/")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest(tt.args.method, "http://localhost:8080"+tt.args.target, nil)
+			for k, v := range tt.args.headers {
+				req.Header.Set(k, v)
+			}
+			writer := httptest.NewRecorder()
+			handler.ServeHTTP(writer, req)
+
+			if writer.Result().StatusCode != tt.status {
+				t.Errorf("cors() = %v, want %v", writer.Result().StatusCode, tt.status)
+			}
+			for k, v := range tt.headers {
+				if got := writer.Header().Get(k); got != v {
+					t.Errorf("cors() header %s = %v, want %v", k, got, v)
+				}
+			}
+		})
+	}
+}
+
+type pingRouter struct{}
+
+func (pingRouter) Add(r *mux.Router) {
+	r.Methods(http.MethodGet).Path("/ping").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func TestServer_Preflight(t *testing.T) {
+	cors := NewCORS(CORSPolicy{
+		AllowedOrigins: []string{"https://admin.example.com"},
+		AllowedMethods: []string{http.MethodGet},
+	})
+	s, err := NewServer(Info{}, Config{Port: "0"}, Telemetry{}, Health{}, Policy{CORS: cors})
+	if err != nil {
+		t.Errorf("NewServer() error = %v", err)
+		return
+	}
+
+	req := httptest.NewRequest(http.MethodOptions, "http://localhost:8080/v1/ping", nil)
+	req.Header.Set("Origin", "https://admin.example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	writer := httptest.NewRecorder()
+	s.handler([]Router{pingRouter{}}).ServeHTTP(writer, req)
+
+	if writer.Result().StatusCode != http.StatusNoContent {
+		t.Errorf("Server.handler() preflight = %v, want %v", writer.Result().StatusCode, http.StatusNoContent)
+	}
+
+	cors.SetPolicy(CORSPolicy{})
+	writer = httptest.NewRecorder()
+	s.handler([]Router{pingRouter{}}).ServeHTTP(writer, req)
+
+	if writer.Result().StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Server.handler() disabled preflight = %v, want %v", writer.Result().StatusCode, http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,15 @@
+//go:build go1.24
+// +build go1.24
+
+package httpx
+
+import "net/http"
+
+// enableH2C will serve http/2 with prior knowledge on the cleartext listener along with http/1.1
+func enableH2C(svr *http.Server) error {
+	p := &http.Protocols{}
+	p.SetHTTP1(true)
+	p.SetUnencryptedHTTP2(true)
+	svr.Protocols = p
+	return nil
+}
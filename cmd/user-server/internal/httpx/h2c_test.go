@@ -0,0 +1,61 @@
+//go:build go1.24
+// +build go1.24
+
+package httpx
+
+import (
+	"net"
+	"net/http"
+	"testing"
+)
+
+func TestEnableH2C(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	svr := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(r.Proto))
+		}),
+	}
+	if err := enableH2C(svr); err != nil {
+		t.Errorf("enableH2C() error = %v", err)
+		return
+	}
+	go func() { _ = svr.Serve(ln) }()
+	defer svr.Close()
+
+	tests := []struct {
+		name  string
+		proto func(p *http.Protocols)
+		want  string
+	}{
+		{
+			name:  "prior knowledge",
+			proto: func(p *http.Protocols) { p.SetUnencryptedHTTP2(true) },
+			want:  "HTTP/2.0",
+		},
+		{
+			name:  "http/1.1",
+			proto: func(p *http.Protocols) { p.SetHTTP1(true) },
+			want:  "HTTP/1.1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &http.Protocols{}
+			tt.proto(p)
+			c := &http.Client{Transport: &http.Transport{Protocols: p}}
+			resp, err := c.Get("http://" + ln.Addr().String())
+			if err != nil {
+				t.Errorf("h2c request error = %v", err)
+				return
+			}
+			defer resp.Body.Close()
+			if resp.Proto != tt.want {
+				t.Errorf("h2c proto = %v, want %v", resp.Proto, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !go1.24
+// +build !go1.24
+
+package httpx
+
+import "net/http"
+
+// enableH2C fails as the standard library can only serve h2c from go1.24
+func enableH2C(svr *http.Server) error {
+	return ErrH2CUnsupported
+}
@@ -0,0 +1,106 @@
+package httpx
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/g8rswimmer/go-data-access-example/pkg/api/response"
+)
+
+// Check is a named dependency check used for readiness
+type Check struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// Health contains the readiness checks and how long readiness fails before the server shuts down
+type Health struct {
+	Checks     []Check
+	DrainDelay time.Duration
+}
+
+const (
+	statusOK       = "ok"
+	statusFail     = "fail"
+	statusDraining = "draining"
+	checkTO        = time.Second * 2
+)
+
+type checkResult struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type healthStatus struct {
+	Status string         `json:"status"`
+	Checks []*checkResult `json:"checks,omitempty"`
+}
+
+// liveness reports that the process is able to serve requests
+func (s *Server) liveness() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response.JSON(w, http.StatusOK, &healthStatus{Status: statusOK})
+	}
+}
+
+// readiness runs all of the checks and fails while the server is draining
+func (s *Server) readiness() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), checkTO)
+		defer cancel()
+
+		results := make([]*checkResult, len(s.health.Checks))
+		wg := sync.WaitGroup{}
+		for i, c := range s.health.Checks {
+			wg.Add(1)
+			go func(i int, c Check) {
+				defer wg.Done()
+				results[i] = runCheck(ctx, c)
+			}(i, c)
+		}
+		wg.Wait()
+
+		status := &healthStatus{
+			Status: statusOK,
+			Checks: results,
+		}
+		for _, result := range results {
+			if result.Status != statusOK {
+				status.Status = statusFail
+			}
+		}
+		if s.isDraining() {
+			status.Status = statusDraining
+		}
+
+		code := http.StatusOK
+		if status.Status != statusOK {
+			code = http.StatusServiceUnavailable
+		}
+		response.JSON(w, code, status)
+	}
+}
+
+func runCheck(ctx context.Context, c Check) *checkResult {
+	start := time.Now()
+	err := c.Check(ctx)
+	result := &checkResult{
+		Name:      c.Name,
+		Status:    statusOK,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = statusFail
+		result.Error = err.Error()
+	}
+	return result
+}
+
+func (s *Server) isDraining() bool {
+	return atomic.LoadInt32(&s.draining) == 1
+}
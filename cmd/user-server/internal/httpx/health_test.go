@@ -0,0 +1,98 @@
+package httpx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestServer_Readiness(t *testing.T) {
+	ok := Check{
+		Name:  "ok",
+		Check: func(ctx context.Context) error { return nil },
+	}
+	fail := Check{
+		Name:  "fail",
+		Check: func(ctx context.Context) error { return errors.New("down") },
+	}
+	tests := []struct {
+		name     string
+		checks   []Check
+		shutdown bool
+		status   int
+		want     string
+	}{
+		{
+			name:   "ready",
+			checks: []Check{ok},
+			status: http.StatusOK,
+			want:   statusOK,
+		},
+		{
+			name:   "failing check",
+			checks: []Check{ok, fail},
+			status: http.StatusServiceUnavailable,
+			want:   statusFail,
+		},
+		{
+			name:     "draining",
+			checks:   []Check{ok},
+			shutdown: true,
+			status:   http.StatusServiceUnavailable,
+			want:     statusDraining,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewServer(Info{}, Config{Port: "0"}, Telemetry{}, Health{Checks: tt.checks}, Policy{})
+			if err != nil {
+				t.Errorf("NewServer() error = %v", err)
+				return
+			}
+			if tt.shutdown {
+				if err := s.Shutdown(context.Background()); err != nil {
+					t.Errorf("Server.Shutdown() error = %v", err)
+					return
+				}
+			}
+
+			writer := httptest.NewRecorder()
+			s.readiness().ServeHTTP(writer, httptest.NewRequest(http.MethodGet, "http://localhost:8080/readyz", nil))
+
+			if writer.Result().StatusCode != tt.status {
+				t.Errorf("Server.readiness() = %v, want %v", writer.Result().StatusCode, tt.status)
+				return
+			}
+
+			got := &healthStatus{}
+			if err := json.NewDecoder(writer.Body).Decode(got); err != nil {
+				t.Errorf("Server.readiness() json body decode error %v", err)
+				return
+			}
+			if got.Status != tt.want {
+				t.Errorf("Server.readiness() status = %v, want %v", got.Status, tt.want)
+			}
+			if len(got.Checks) != len(tt.checks) {
+				t.Errorf("Server.readiness() checks = %v, want %v", len(got.Checks), len(tt.checks))
+			}
+		})
+	}
+}
+
+func TestServer_Liveness(t *testing.T) {
+	s, err := NewServer(Info{}, Config{Port: "0"}, Telemetry{}, Health{}, Policy{})
+	if err != nil {
+		t.Errorf("NewServer() error = %v", err)
+		return
+	}
+
+	writer := httptest.NewRecorder()
+	s.liveness().ServeHTTP(writer, httptest.NewRequest(http.MethodGet, "http://localhost:8080/healthz", nil))
+
+	if writer.Result().StatusCode != http.StatusOK {
+		t.Errorf("Server.liveness() = %v, want %v", writer.Result().StatusCode, http.StatusOK)
+	}
+}
@@ -0,0 +1,96 @@
+package httpx
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+)
+
+// Listener types
+const (
+	ListenTCP     = "tcp"
+	ListenUnix    = "unix"
+	ListenSystemd = "systemd"
+)
+
+// systemd passes the activated sockets starting at this descriptor
+const listenFDsStart = 3
+
+var (
+	// ErrNoSocketActivation is returned when a systemd listener is requested without an inherited socket
+	ErrNoSocketActivation = errors.New("no socket activation")
+	// ErrH2CUnsupported is returned when h2c is requested from a go release that can not serve it
+	ErrH2CUnsupported = errors.New("h2c requires go1.24 or later")
+)
+
+// Address returns where the server listens
+func (c Config) Address() string {
+	switch c.Network {
+	case ListenUnix:
+		return c.SocketPath
+	case ListenSystemd:
+		return ListenSystemd
+	default:
+		return fmt.Sprintf(":%s", c.Port)
+	}
+}
+
+// listen creates the listener, tcp is used when the network is not given
+func (c Config) listen() (net.Listener, error) {
+	switch c.Network {
+	case ListenUnix:
+		return listenUnix(c.SocketPath, c.SocketMode)
+	case ListenSystemd:
+		return activatedListener(os.Getenv, listenFDsStart)
+	case "", ListenTCP:
+		return net.Listen("tcp", c.Address())
+	default:
+		return nil, fmt.Errorf("unknown listener %q", c.Network)
+	}
+}
+
+// listenUnix will listen on the socket path, replacing a stale socket left by a previous run
+func listenUnix(path string, mode os.FileMode) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("unix socket %s exists and is not a socket", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("unix socket remove stale %w", err)
+		}
+	}
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if mode != 0 {
+		if err := os.Chmod(path, mode); err != nil {
+			ln.Close()
+			return nil, fmt.Errorf("unix socket mode %w", err)
+		}
+	}
+	return ln, nil
+}
+
+// activatedListener returns the first socket passed with the systemd LISTEN_PID and LISTEN_FDS protocol
+func activatedListener(getenv func(string) string, firstFD int) (net.Listener, error) {
+	pid, err := strconv.Atoi(getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, fmt.Errorf("%w LISTEN_PID %q", ErrNoSocketActivation, getenv("LISTEN_PID"))
+	}
+	fds, err := strconv.Atoi(getenv("LISTEN_FDS"))
+	if err != nil || fds < 1 {
+		return nil, fmt.Errorf("%w LISTEN_FDS %q", ErrNoSocketActivation, getenv("LISTEN_FDS"))
+	}
+	// the sockets are not passed on to child processes
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+
+	f := os.NewFile(uintptr(firstFD), "LISTEN_FD_"+strconv.Itoa(firstFD))
+	defer f.Close()
+	return net.FileListener(f)
+}
@@ -0,0 +1,143 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+)
+
+func TestListenUnix(t *testing.T) {
+	dir, err := ioutil.TempDir("", "httpx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "user.sock")
+	// a socket left behind by a process that did not shutdown
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	ln, err := Config{Network: ListenUnix, SocketPath: path, SocketMode: 0600}.listen()
+	if err != nil {
+		t.Errorf("Config.listen() error = %v", err)
+		return
+	}
+	svr := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}),
+	}
+	go func() { _ = svr.Serve(ln) }()
+	defer svr.Close()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Errorf("unix socket stat error = %v", err)
+		return
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("unix socket mode = %v, want %v", info.Mode().Perm(), os.FileMode(0600))
+	}
+
+	c := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", path)
+			},
+		},
+	}
+	resp, err := c.Get("http://unix/")
+	if err != nil {
+		t.Errorf("unix socket request error = %v", err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("unix socket status = %v, want %v", resp.StatusCode, http.StatusNoContent)
+	}
+}
+
+func TestListenUnix_NotSocket(t *testing.T) {
+	dir, err := ioutil.TempDir("", "httpx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "user.sock")
+	if err := ioutil.WriteFile(path, []byte("data"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := listenUnix(path, 0); err == nil {
+		t.Errorf("listenUnix() expected an error for a regular file")
+	}
+}
+
+func TestActivatedListener(t *testing.T) {
+	inherited, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer inherited.Close()
+	f, err := inherited.(*net.TCPListener).File()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	// the listener takes ownership of the descriptor, as it would of one passed by systemd
+	fd, err := syscall.Dup(int(f.Fd()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pid := strconv.Itoa(os.Getpid())
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr error
+	}{
+		{
+			name: "activated",
+			env:  map[string]string{"LISTEN_PID": pid, "LISTEN_FDS": "1"},
+		},
+		{
+			name:    "other process",
+			env:     map[string]string{"LISTEN_PID": "1", "LISTEN_FDS": "1"},
+			wantErr: ErrNoSocketActivation,
+		},
+		{
+			name:    "no sockets",
+			env:     map[string]string{"LISTEN_PID": pid, "LISTEN_FDS": "0"},
+			wantErr: ErrNoSocketActivation,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(key string) string { return tt.env[key] }
+			ln, err := activatedListener(getenv, fd)
+			if errors.Is(err, tt.wantErr) == false || (tt.wantErr == nil && err != nil) {
+				t.Errorf("activatedListener() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			defer ln.Close()
+			if ln.Addr().String() != inherited.Addr().String() {
+				t.Errorf("activatedListener() addr = %v, want %v", ln.Addr(), inherited.Addr())
+			}
+		})
+	}
+}
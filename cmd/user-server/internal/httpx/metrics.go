@@ -0,0 +1,39 @@
+package httpx
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/g8rswimmer/go-data-access-example/pkg/metrics"
+)
+
+const unmatchedRoute = "unmatched"
+
+// instrument will count and time every request labeled by the mux route name
+func instrument(reg *metrics.Registry) Middleware {
+	if reg == nil {
+		return func(next http.Handler) http.Handler {
+			return next
+		}
+	}
+	requests := reg.NewCounterVec("http_requests_total", "Total number of http requests.", "route", "method", "status")
+	latency := reg.NewHistogramVec("http_request_duration_seconds", "Latency of http requests.", metrics.DefBuckets, "route", "method")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			r, route := withRoute(r)
+			sw := &statusWriter{ResponseWriter: w}
+
+			next.ServeHTTP(sw, r)
+
+			name := route.name
+			if len(name) == 0 {
+				name = unmatchedRoute
+			}
+			requests.Inc(name, r.Method, strconv.Itoa(sw.Status()))
+			latency.Observe(time.Since(start).Seconds(), name, r.Method)
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package httpx
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/g8rswimmer/go-data-access-example/pkg/api/response"
+	"github.com/g8rswimmer/go-data-access-example/pkg/logx"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// Middleware wraps a handler with additional behavior
+type Middleware func(http.Handler) http.Handler
+
+// RequestIDHeader is the header used to propagate the request id
+const RequestIDHeader = "X-Request-ID"
+
+const maxRequestIDLength = 128
+
+type requestIDKey struct{}
+
+type routeKey struct{}
+
+type routeInfo struct {
+	name string
+}
+
+type problem struct {
+	ID      string `json:"id,omitempty"`
+	Error   string `json:"error,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// RequestID returns the request id from the context
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// chain will wrap the handler so the first middleware is the outer most
+func chain(h http.Handler, middleware ...Middleware) http.Handler {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		h = middleware[i](h)
+	}
+	return h
+}
+
+// requestID will assign or propagate the request id and place a request logger into the context
+func requestID(logger *logx.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(RequestIDHeader)
+			if validRequestID(id) == false {
+				id = uuid.New().String()
+			}
+			w.Header().Set(RequestIDHeader, id)
+
+			ctx := context.WithValue(r.Context(), requestIDKey{}, id)
+			ctx = logx.NewContext(ctx, logger.With(logx.Fields{"request_id": id}))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func validRequestID(id string) bool {
+	if len(id) == 0 || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		if c < '!' || c > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// accessLog will write an entry for every request once it has been served
+func accessLog() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			r, route := withRoute(r)
+			sw := &statusWriter{ResponseWriter: w}
+
+			next.ServeHTTP(sw, r)
+
+			logx.FromContext(r.Context()).Info("http request", logx.Fields{
+				"method":     r.Method,
+				"path":       r.URL.Path,
+				"route":      route.name,
+				"status":     sw.Status(),
+				"latency_ms": float64(time.Since(start).Microseconds()) / 1000,
+				"bytes":      sw.bytes,
+			})
+		})
+	}
+}
+
+// withRoute returns the request with a route holder that is filled in once the route is matched
+func withRoute(r *http.Request) (*http.Request, *routeInfo) {
+	if info, ok := r.Context().Value(routeKey{}).(*routeInfo); ok {
+		return r, info
+	}
+	info := &routeInfo{}
+	return r.WithContext(context.WithValue(r.Context(), routeKey{}, info)), info
+}
+
+// routeName records the matched mux route name for the access log and metrics
+func routeName(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if info, ok := r.Context().Value(routeKey{}).(*routeInfo); ok {
+			if route := mux.CurrentRoute(r); route != nil {
+				info.name = route.GetName()
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// recoverer will turn a handler panic into an internal server error
+func recoverer() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				rec := recover()
+				switch {
+				case rec == nil:
+					return
+				case rec == http.ErrAbortHandler:
+					panic(rec)
+				default:
+				}
+
+				logx.FromContext(r.Context()).Error("http handler panic", logx.Fields{
+					"panic": fmt.Sprint(rec),
+					"stack": string(debug.Stack()),
+				})
+
+				if written(w) {
+					return
+				}
+				msg := &problem{
+					ID:      RequestID(r.Context()),
+					Error:   http.StatusText(http.StatusInternalServerError),
+					Message: "internal server error",
+				}
+				response.JSON(w, http.StatusInternalServerError, msg)
+			}()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// written returns if the response has already been started
+func written(w http.ResponseWriter) bool {
+	switch rw := w.(type) {
+	case *statusWriter:
+		return rw.status != 0
+	case *compressWriter:
+		return rw.wroteHeader
+	default:
+		return false
+	}
+}
+
+// statusWriter records the status and the number of bytes written
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (sw *statusWriter) WriteHeader(status int) {
+	if sw.status == 0 {
+		sw.status = status
+	}
+	sw.ResponseWriter.WriteHeader(status)
+}
+
+func (sw *statusWriter) Write(b []byte) (int, error) {
+	if sw.status == 0 {
+		sw.status = http.StatusOK
+	}
+	n, err := sw.ResponseWriter.Write(b)
+	sw.bytes += n
+	return n, err
+}
+
+// Flush will flush the underlying writer when it is supported
+func (sw *statusWriter) Flush() {
+	if f, ok := sw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Status returns the response status, defaulting to ok when nothing was written
+func (sw *statusWriter) Status() int {
+	if sw.status == 0 {
+		return http.StatusOK
+	}
+	return sw.status
+}
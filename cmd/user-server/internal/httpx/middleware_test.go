@@ -0,0 +1,95 @@
+package httpx
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/g8rswimmer/go-data-access-example/pkg/logx"
+	"github.com/gorilla/mux"
+)
+
+func TestMiddleware_Chain(t *testing.T) {
+	type args struct {
+		req *http.Request
+	}
+	tests := []struct {
+		name      string
+		args      args
+		status    int
+		route     string
+		requestID string
+	}{
+		{
+			name: "propagated request id",
+			args: args{
+				req: func() *http.Request {
+					req := httptest.NewRequest(http.MethodGet, "http://localhost:8080/ok", nil)
+					req.Header.Set(RequestIDHeader, "abc-123")
+					return req
+				}(),
+			},
+			status:    http.StatusOK,
+			route:     "ok",
+			requestID: "abc-123",
+		},
+		{
+			name: "recovered panic",
+			args: args{
+				req: httptest.NewRequest(http.MethodGet, "http://localhost:8080/panic", nil),
+			},
+			status: http.StatusInternalServerError,
+			route:  "panic",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			logger := logx.New(buf, logx.InfoLevel)
+
+			r := mux.NewRouter()
+			r.Use(routeName)
+			r.Path("/ok").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte("ok"))
+			}).Name("ok")
+			r.Path("/panic").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				panic("boom")
+			}).Name("panic")
+
+			handler := chain(r, requestID(logger), accessLog(), recoverer())
+			writer := httptest.NewRecorder()
+			handler.ServeHTTP(writer, tt.args.req)
+
+			if writer.Result().StatusCode != tt.status {
+				t.Errorf("middleware status = %v, want %v", writer.Result().StatusCode, tt.status)
+				return
+			}
+
+			id := writer.Header().Get(RequestIDHeader)
+			switch {
+			case len(id) == 0:
+				t.Errorf("middleware request id not set")
+			case len(tt.requestID) > 0 && id != tt.requestID:
+				t.Errorf("middleware request id = %v, want %v", id, tt.requestID)
+			}
+
+			var entry map[string]interface{}
+			lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+			if err := json.Unmarshal(lines[len(lines)-1], &entry); err != nil {
+				t.Errorf("middleware access log decode error %v", err)
+				return
+			}
+			if entry["route"] != tt.route {
+				t.Errorf("middleware access log route = %v, want %v", entry["route"], tt.route)
+			}
+			if int(entry["status"].(float64)) != tt.status {
+				t.Errorf("middleware access log status = %v, want %v", entry["status"], tt.status)
+			}
+			if entry["request_id"] != id {
+				t.Errorf("middleware access log request id = %v, want %v", entry["request_id"], id)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package httpx
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/g8rswimmer/go-data-access-example/pkg/api/response"
+)
+
+const clientIdleTO = time.Minute * 10
+
+// RateLimiter limits the requests of each client with a token bucket
+type RateLimiter struct {
+	mu        sync.Mutex
+	rate      float64
+	burst     int
+	clients   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter creates a limiter allowing rps requests per second with a burst for each client
+func NewRateLimiter(rps float64, burst int) *RateLimiter {
+	return &RateLimiter{
+		rate:      rps,
+		burst:     burst,
+		clients:   map[string]*bucket{},
+		lastSweep: time.Now(),
+	}
+}
+
+// SetLimit will change the limit for all of the clients, a zero rate disables the limit
+func (l *RateLimiter) SetLimit(rps float64, burst int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rate = rps
+	l.burst = burst
+}
+
+// Allow returns if the client may make a request and if not how long until it may
+func (l *RateLimiter) Allow(client string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.rate <= 0 {
+		return true, 0
+	}
+
+	now := time.Now()
+	l.sweep(now)
+
+	b, has := l.clients[client]
+	if has == false {
+		b = &bucket{tokens: float64(l.burst), last: now}
+		l.clients[client] = b
+	}
+	b.tokens = math.Min(float64(l.burst), b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	return false, wait
+}
+
+// sweep removes the clients that have not been seen for a while
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < clientIdleTO {
+		return
+	}
+	l.lastSweep = now
+	for client, b := range l.clients {
+		if now.Sub(b.last) > clientIdleTO {
+			delete(l.clients, client)
+		}
+	}
+}
+
+// rateLimit will reject the requests of a client that is over the limit
+func rateLimit(l *RateLimiter) Middleware {
+	return func(next http.Handler) http.Handler {
+		if l == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			allowed, wait := l.Allow(clientIP(r))
+			if allowed == false {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				msg := &problem{
+					ID:      RequestID(r.Context()),
+					Message: "rate limit exceeded",
+				}
+				response.JSON(w, http.StatusTooManyRequests, msg)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,66 @@
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRateLimit(t *testing.T) {
+	tests := []struct {
+		name     string
+		rps      float64
+		burst    int
+		requests int
+		want     []int
+	}{
+		{
+			name:     "disabled",
+			rps:      0,
+			burst:    1,
+			requests: 3,
+			want:     []int{http.StatusOK, http.StatusOK, http.StatusOK},
+		},
+		{
+			name:     "burst exceeded",
+			rps:      0.001,
+			burst:    2,
+			requests: 3,
+			want:     []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := NewRateLimiter(tt.rps, tt.burst)
+			handler := rateLimit(limiter)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			for i := 0; i < tt.requests; i++ {
+				writer := httptest.NewRecorder()
+				handler.ServeHTTP(writer, httptest.NewRequest(http.MethodGet, "http://localhost:8080/v1/users", nil))
+				if writer.Result().StatusCode != tt.want[i] {
+					t.Errorf("rateLimit() request %d = %v, want %v", i, writer.Result().StatusCode, tt.want[i])
+				}
+				if tt.want[i] == http.StatusTooManyRequests && len(writer.Header().Get("Retry-After")) == 0 {
+					t.Errorf("rateLimit() request %d missing Retry-After", i)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimiter_SetLimit(t *testing.T) {
+	limiter := NewRateLimiter(0.001, 1)
+	if ok, _ := limiter.Allow("client"); ok == false {
+		t.Errorf("RateLimiter.Allow() first request should be allowed")
+	}
+	if ok, _ := limiter.Allow("client"); ok {
+		t.Errorf("RateLimiter.Allow() second request should be limited")
+	}
+
+	limiter.SetLimit(0, 1)
+	if ok, _ := limiter.Allow("client"); ok == false {
+		t.Errorf("RateLimiter.Allow() should be allowed once disabled")
+	}
+}
@@ -3,11 +3,18 @@ package httpx
 import (
 	"context"
 	"errors"
-	"fmt"
+	"net"
 	"net/http"
+	"os"
+	"runtime"
+	"sync/atomic"
 	"time"
 
+	"github.com/g8rswimmer/go-data-access-example/cmd/user-server/internal/build"
 	"github.com/g8rswimmer/go-data-access-example/pkg/api/response"
+	"github.com/g8rswimmer/go-data-access-example/pkg/logx"
+	"github.com/g8rswimmer/go-data-access-example/pkg/metrics"
+	"github.com/g8rswimmer/go-data-access-example/pkg/trace"
 	"github.com/gorilla/mux"
 )
 
@@ -18,62 +25,212 @@ type Router interface {
 
 // Info contains the information on the service
 type Info struct {
-	Name    string `json:"name"`
+	Name     string        `json:"name"`
+	Version  string        `json:"version"`
+	Build    *build.Info   `json:"build,omitempty"`
+	Database *DatabaseInfo `json:"database,omitempty"`
+	// ConfigValues returns the active non secret configuration
+	ConfigValues func() map[string]string `json:"-"`
+	// ReloadStatus returns the configuration reload status
+	ReloadStatus func() interface{} `json:"-"`
+}
+
+// DatabaseInfo contains the information on the database
+type DatabaseInfo struct {
+	Driver  string `json:"driver"`
 	Version string `json:"version"`
 }
 
+type runtimeInfo struct {
+	Info
+	Config        map[string]string `json:"config,omitempty"`
+	Reload        interface{}       `json:"reload,omitempty"`
+	StartedAt     time.Time         `json:"started_at"`
+	Uptime        string            `json:"uptime"`
+	UptimeSeconds float64           `json:"uptime_seconds"`
+	Goroutines    int               `json:"goroutines"`
+}
+
+// Telemetry contains the logging, metrics and tracing used by the server
+type Telemetry struct {
+	Logger   *logx.Logger
+	Registry *metrics.Registry
+	Tracer   *trace.Tracer
+}
+
+// Policy contains the request policies that can change while the server runs
+type Policy struct {
+	RateLimiter *RateLimiter
+	CORS        *CORS
+}
+
 // Server handles the http server for the service
 type Server struct {
-	svr  *http.Server
-	info Info
+	svr       *http.Server
+	info      Info
+	telemetry Telemetry
+	health    Health
+	policy    Policy
+	cfg       Config
+	ln        net.Listener
+	draining  int32
+	started   time.Time
 }
 
-const shutdownTO = time.Second * 10
+const (
+	shutdownTO = time.Second * 10
+	apiPrefix  = "/v1"
+)
+
+// Config contains the listener configuration of the server
+type Config struct {
+	// Network is the listener type, tcp on the port when empty
+	Network      string
+	Port         string
+	SocketPath   string
+	SocketMode   os.FileMode
+	H2C          bool
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	// MaxBodySize is the largest request body in bytes, zero leaves it unlimited
+	MaxBodySize int64
+	// TLS serves https when present
+	TLS *TLS
+	// Compression compresses the responses and decompresses the request bodies when present
+	Compression *Compression
+}
 
 // NewServer creates a new server
-func NewServer(info Info, port string, rto, wto time.Duration) *Server {
-	return &Server{
+func NewServer(info Info, cfg Config, telemetry Telemetry, health Health, policy Policy) (*Server, error) {
+	if telemetry.Logger == nil {
+		telemetry.Logger = logx.Default()
+	}
+	s := &Server{
 		svr: &http.Server{
-			Addr:         fmt.Sprintf(":%s", port),
-			ReadTimeout:  rto,
-			WriteTimeout: wto,
+			Addr:         cfg.Address(),
+			ReadTimeout:  cfg.ReadTimeout,
+			WriteTimeout: cfg.WriteTimeout,
 		},
-		info: info,
+		info:      info,
+		telemetry: telemetry,
+		health:    health,
+		policy:    policy,
+		cfg:       cfg,
+		started:   time.Now(),
+	}
+	if cfg.H2C {
+		if cfg.TLS != nil {
+			return nil, errors.New("h2c can not be used with tls")
+		}
+		if err := enableH2C(s.svr); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.TLS != nil {
+		tlsConfig, err := cfg.TLS.config()
+		if err != nil {
+			return nil, err
+		}
+		s.svr.TLSConfig = tlsConfig
 	}
+	return s, nil
 }
 
-// Start the server with the routes
-func (s *Server) Start(routers []Router) {
+// ErrNotStarted is returned when the server is served before it has been started
+var ErrNotStarted = errors.New("server not started")
 
+// Start will add the routes and listen, returning the error when the listener can not be created
+func (s *Server) Start(routers []Router) error {
 	s.svr.Handler = s.handler(routers)
 
-	go func() {
-		if err := s.svr.ListenAndServe(); err != nil && errors.Is(err, http.ErrServerClosed) == false {
-			panic(err)
-		}
-	}()
+	ln, err := s.cfg.listen()
+	if err != nil {
+		return err
+	}
+	s.ln = ln
+	return nil
+}
+
+// Serve will handle requests until the server is shutdown
+func (s *Server) Serve() error {
+	if s.ln == nil {
+		return ErrNotStarted
+	}
+	var err error
+	if s.svr.TLSConfig != nil {
+		err = s.svr.ServeTLS(s.ln, "", "")
+	} else {
+		err = s.svr.Serve(s.ln)
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
 }
 
-func (s Server) handler(routers []Router) http.Handler {
+func (s *Server) handler(routers []Router) http.Handler {
 	r := mux.NewRouter().StrictSlash(true)
-	r.Methods(http.MethodGet).Path("/").Handler(s.index()).Name("info")
+	r.Use(routeName)
+	r.Methods(http.MethodGet).Path("/").Handler(s.index()).Name("index")
+	r.Methods(http.MethodGet).Path("/info").Handler(s.index()).Name("info")
+	r.Methods(http.MethodGet).Path("/healthz").Handler(s.liveness()).Name("healthz")
+	r.Methods(http.MethodGet).Path("/readyz").Handler(s.readiness()).Name("readyz")
+	if s.telemetry.Registry != nil {
+		r.Methods(http.MethodGet).Path("/metrics").Handler(s.telemetry.Registry.Handler()).Name("metrics")
+	}
 
-	apis := r.PathPrefix("/v1").Subrouter()
+	apis := r.PathPrefix(apiPrefix).Subrouter()
+	apis.Use(mux.MiddlewareFunc(rateLimit(s.policy.RateLimiter)), response.Negotiate)
 	for _, router := range routers {
 		router.Add(apis)
 	}
-	return r
+	return chain(r,
+		requestID(s.telemetry.Logger),
+		tracing(s.telemetry.Tracer),
+		accessLog(),
+		instrument(s.telemetry.Registry),
+		bodyLimit(s.cfg.MaxBodySize),
+		compress(s.cfg.Compression),
+		recoverer(),
+		cors(s.policy.CORS, apiPrefix+"/"),
+		clientIdentity,
+	)
 }
 
-// Shutdown will gracefuly shutdown the server
+// Shutdown will fail readiness for the drain delay and then gracefuly shutdown the server before the context deadline
 func (s *Server) Shutdown(ctx context.Context) error {
-	ctxTO, cancel := context.WithTimeout(ctx, shutdownTO)
-	defer cancel()
-	return s.svr.Shutdown(ctxTO)
+	atomic.StoreInt32(&s.draining, 1)
+	if s.health.DrainDelay > 0 {
+		select {
+		case <-time.After(s.health.DrainDelay):
+		case <-ctx.Done():
+		}
+	}
+
+	if _, has := ctx.Deadline(); has == false {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, shutdownTO)
+		defer cancel()
+	}
+	return s.svr.Shutdown(ctx)
 }
 
 func (s *Server) index() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		response.JSON(w, http.StatusOK, s.info)
+		uptime := time.Since(s.started)
+		info := &runtimeInfo{
+			Info:          s.info,
+			StartedAt:     s.started.UTC(),
+			Uptime:        uptime.Round(time.Second).String(),
+			UptimeSeconds: uptime.Seconds(),
+			Goroutines:    runtime.NumGoroutine(),
+		}
+		if s.info.ConfigValues != nil {
+			info.Config = s.info.ConfigValues()
+		}
+		if s.info.ReloadStatus != nil {
+			info.Reload = s.info.ReloadStatus()
+		}
+		response.JSON(w, http.StatusOK, info)
 	}
 }
@@ -0,0 +1,142 @@
+package httpx
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/g8rswimmer/go-data-access-example/pkg/api/identity"
+)
+
+// TLS contains the server certificate and the optional client certificate verification,
+// the client auth is used as given and the configuration decides what a client ca implies
+type TLS struct {
+	CertFile     string
+	KeyFile      string
+	MinVersion   uint16
+	CipherSuites []uint16
+	ClientCAFile string
+	ClientAuth   tls.ClientAuthType
+}
+
+const certCheckInterval = time.Second
+
+// config builds the tls configuration with a certificate that is reloaded when the files change
+func (t *TLS) config() (*tls.Config, error) {
+	certs, err := newCertReloader(t.CertFile, t.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &tls.Config{
+		MinVersion:     t.MinVersion,
+		CipherSuites:   t.CipherSuites,
+		GetCertificate: certs.GetCertificate,
+		ClientAuth:     t.ClientAuth,
+		NextProtos:     []string{"h2", "http/1.1"},
+	}
+	if cfg.MinVersion == 0 {
+		cfg.MinVersion = tls.VersionTLS12
+	}
+
+	if len(t.ClientCAFile) > 0 {
+		pem, err := ioutil.ReadFile(t.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("tls client ca %w", err)
+		}
+		pool := x509.NewCertPool()
+		if pool.AppendCertsFromPEM(pem) == false {
+			return nil, fmt.Errorf("tls client ca %s has no certificates", t.ClientCAFile)
+		}
+		cfg.ClientCAs = pool
+	}
+	return cfg, nil
+}
+
+// certReloader serves the certificate and reloads it when the files are modified
+type certReloader struct {
+	certFile string
+	keyFile  string
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	certMod   time.Time
+	keyMod    time.Time
+	lastCheck time.Time
+}
+
+func newCertReloader(certFile, keyFile string) (*certReloader, error) {
+	c := &certReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *certReloader) load() error {
+	certMod, err := fileModTime(c.certFile)
+	if err != nil {
+		return fmt.Errorf("tls certificate %w", err)
+	}
+	keyMod, err := fileModTime(c.keyFile)
+	if err != nil {
+		return fmt.Errorf("tls key %w", err)
+	}
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return fmt.Errorf("tls key pair %w", err)
+	}
+	c.cert = &cert
+	c.certMod = certMod
+	c.keyMod = keyMod
+	return nil
+}
+
+// GetCertificate returns the current certificate, reloading it when the files have changed
+func (c *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(c.lastCheck) < certCheckInterval {
+		return c.cert, nil
+	}
+	c.lastCheck = now
+
+	certMod, certErr := fileModTime(c.certFile)
+	keyMod, keyErr := fileModTime(c.keyFile)
+	if certErr != nil || keyErr != nil || (certMod.Equal(c.certMod) && keyMod.Equal(c.keyMod)) {
+		return c.cert, nil
+	}
+	// a failed reload, such as a key pair mid rotation, keeps serving the current certificate
+	_ = c.load()
+	return c.cert, nil
+}
+
+func fileModTime(path string) (time.Time, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
+
+// clientIdentity places the verified client certificate identity into the context
+func clientIdentity(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		client := identity.FromCertificate(r.TLS.VerifiedChains[0][0])
+		next.ServeHTTP(w, r.WithContext(identity.NewContext(r.Context(), client)))
+	})
+}
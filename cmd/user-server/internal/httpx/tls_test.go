@@ -0,0 +1,205 @@
+package httpx
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/g8rswimmer/go-data-access-example/pkg/api/identity"
+)
+
+type testCert struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+func newTestCert(t *testing.T, cn string, serial int64, parent *testCert, ca bool) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		IsCA:         ca,
+
+		BasicConstraintsValid: true,
+	}
+	signer, signerKey := tmpl, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCert{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+func (c *testCert) write(t *testing.T, dir, name string) (string, string) {
+	certFile := filepath.Join(dir, name+".crt")
+	keyFile := filepath.Join(dir, name+".key")
+	if err := ioutil.WriteFile(certFile, c.certPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(keyFile, c.keyPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestTLS_ClientIdentity(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ca := newTestCert(t, "test ca", 1, nil, true)
+	server := newTestCert(t, "localhost", 2, ca, false)
+	client := newTestCert(t, "admin-app", 3, ca, false)
+
+	caFile, _ := ca.write(t, dir, "ca")
+	certFile, keyFile := server.write(t, dir, "server")
+
+	cfg, err := (&TLS{CertFile: certFile, KeyFile: keyFile, ClientCAFile: caFile, ClientAuth: tls.RequireAndVerifyClientCert}).config()
+	if err != nil {
+		t.Errorf("TLS.config() error = %v", err)
+		return
+	}
+
+	// the client auth is used as given even with a client ca
+	optional, err := (&TLS{CertFile: certFile, KeyFile: keyFile, ClientCAFile: caFile}).config()
+	if err != nil || optional.ClientAuth != tls.NoClientCert || optional.ClientCAs == nil {
+		t.Errorf("TLS.config() = %v, %v want no client certificate with the client ca pool", optional.ClientAuth, err)
+	}
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	svr := &http.Server{
+		Handler: clientIdentity(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			c, ok := identity.FromContext(r.Context())
+			if ok == false {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(c.CommonName))
+		})),
+	}
+	go func() { _ = svr.Serve(ln) }()
+	defer svr.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	clientPair, err := tls.X509KeyPair(client.certPEM, client.keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		certs   []tls.Certificate
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "verified client",
+			certs: []tls.Certificate{clientPair},
+			want:  "admin-app",
+		},
+		{
+			name:    "missing client certificate",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &http.Client{
+				Transport: &http.Transport{
+					TLSClientConfig: &tls.Config{
+						RootCAs:      roots,
+						Certificates: tt.certs,
+					},
+				},
+			}
+			resp, err := c.Get("https://" + ln.Addr().String())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("TLS client error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			defer resp.Body.Close()
+			body, _ := ioutil.ReadAll(resp.Body)
+			if string(body) != tt.want {
+				t.Errorf("TLS client identity = %v, want %v", string(body), tt.want)
+			}
+		})
+	}
+}
+
+func TestCertReloader_GetCertificate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	first := newTestCert(t, "first", 1, nil, false)
+	certFile, keyFile := first.write(t, dir, "server")
+
+	r, err := newCertReloader(certFile, keyFile)
+	if err != nil {
+		t.Errorf("newCertReloader() error = %v", err)
+		return
+	}
+
+	second := newTestCert(t, "second", 2, nil, false)
+	second.write(t, dir, "server")
+	later := time.Now().Add(time.Minute)
+	_ = os.Chtimes(certFile, later, later)
+	_ = os.Chtimes(keyFile, later, later)
+	r.lastCheck = time.Time{}
+
+	got, err := r.GetCertificate(nil)
+	if err != nil {
+		t.Errorf("certReloader.GetCertificate() error = %v", err)
+		return
+	}
+	leaf, _ := x509.ParseCertificate(got.Certificate[0])
+	if leaf.Subject.CommonName != "second" {
+		t.Errorf("certReloader.GetCertificate() = %v, want second", leaf.Subject.CommonName)
+	}
+}
@@ -0,0 +1,49 @@
+package httpx
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/g8rswimmer/go-data-access-example/pkg/logx"
+	"github.com/g8rswimmer/go-data-access-example/pkg/trace"
+)
+
+// tracing will continue the W3C trace context and create a span for every handler
+func tracing(tracer *trace.Tracer) Middleware {
+	return func(next http.Handler) http.Handler {
+		if tracer == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			if sc, err := trace.ParseTraceparent(r.Header.Get(trace.TraceparentHeader)); err == nil {
+				ctx = trace.ContextWithRemoteParent(ctx, sc)
+			}
+
+			ctx, span := tracer.Start(ctx, "http "+r.Method)
+			defer span.End()
+
+			sc := span.SpanContext()
+			ctx = logx.NewContext(ctx, logx.FromContext(ctx).With(logx.Fields{
+				"trace_id": sc.TraceID.String(),
+				"span_id":  sc.SpanID.String(),
+			}))
+			span.SetAttribute("http.method", r.Method)
+			span.SetAttribute("http.target", r.URL.Path)
+
+			r, route := withRoute(r.WithContext(ctx))
+			sw := &statusWriter{ResponseWriter: w}
+
+			next.ServeHTTP(sw, r)
+
+			if len(route.name) > 0 {
+				span.SetName(route.name)
+				span.SetAttribute("http.route", route.name)
+			}
+			span.SetAttribute("http.status_code", sw.Status())
+			if sw.Status() >= http.StatusInternalServerError {
+				span.SetError(errors.New(http.StatusText(sw.Status())))
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/g8rswimmer/go-data-access-example/pkg/logx"
+)
+
+// Component is a part of the service that is started and stopped by the manager
+type Component struct {
+	Name string
+	// Start will acquire the resources, a returned error aborts the start up
+	Start func(ctx context.Context) error
+	// Run is the long running work, it returns when it is stopped or its context is done and an error fails the service
+	Run func(ctx context.Context) error
+	// Stop will release the resources before the deadline of the context
+	Stop func(ctx context.Context) error
+}
+
+// Job returns a background component that runs until it is stopped
+func Job(name string, run func(ctx context.Context) error) Component {
+	return Component{
+		Name: name,
+		Run:  run,
+	}
+}
+
+// StartError is returned when a component fails to start
+type StartError struct {
+	Component string
+	Err       error
+}
+
+func (e *StartError) Error() string {
+	return fmt.Sprintf("%s start %s", e.Component, e.Err)
+}
+
+// Unwrap returns the start failure
+func (e *StartError) Unwrap() error {
+	return e.Err
+}
+
+// RunError is returned when a component stops running on its own
+type RunError struct {
+	Component string
+	Err       error
+}
+
+func (e *RunError) Error() string {
+	return fmt.Sprintf("%s run %s", e.Component, e.Err)
+}
+
+// Unwrap returns the run failure
+func (e *RunError) Unwrap() error {
+	return e.Err
+}
+
+// StopErrors contains every component that did not stop cleanly
+type StopErrors []error
+
+func (e StopErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("%d components did not stop: %s", len(e), strings.Join(msgs, "; "))
+}
+
+const defaultStopTimeout = time.Second * 15
+
+// Manager starts the components in order and stops them in reverse order
+type Manager struct {
+	Logger *logx.Logger
+	// StopTimeout bounds stopping every component
+	StopTimeout time.Duration
+
+	components []Component
+	running    []*running
+	failed     chan error
+	once       sync.Once
+}
+
+type running struct {
+	Component
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error
+	// failed is set when the run error has already been reported
+	failed bool
+}
+
+// Add will append the component, it is started after the components already added
+func (m *Manager) Add(c Component) {
+	m.components = append(m.components, c)
+}
+
+func (m *Manager) logger() *logx.Logger {
+	if m.Logger == nil {
+		return logx.Default()
+	}
+	return m.Logger
+}
+
+// Start will start every component in order, a failure stops the components already started
+func (m *Manager) Start(ctx context.Context) error {
+	m.once.Do(func() {
+		m.failed = make(chan error, len(m.components))
+	})
+
+	for _, c := range m.components {
+		begin := time.Now()
+		if c.Start != nil {
+			if err := c.Start(ctx); err != nil {
+				stopCtx, cancel := m.stopContext()
+				defer cancel()
+				_ = m.Stop(stopCtx)
+				return &StartError{Component: c.Name, Err: err}
+			}
+		}
+
+		r := &running{Component: c}
+		if c.Run != nil {
+			// the run context is not a child of ctx so that canceling ctx does not stop the components out of order
+			var runCtx context.Context
+			runCtx, r.cancel = context.WithCancel(logx.NewContext(context.Background(), m.logger()))
+			r.done = make(chan struct{})
+			go m.run(runCtx, r)
+		}
+		m.running = append(m.running, r)
+		m.logger().Info("component started", logx.Fields{"component": c.Name, "duration_ms": float64(time.Since(begin).Microseconds()) / 1000})
+	}
+	return nil
+}
+
+func (m *Manager) run(ctx context.Context, r *running) {
+	defer close(r.done)
+	r.err = r.Run(ctx)
+	if r.err == nil || ctx.Err() != nil {
+		return
+	}
+	r.failed = true
+	m.failed <- &RunError{Component: r.Name, Err: r.err}
+}
+
+// Wait will block until the context is done or a component stops running, returning the failure
+func (m *Manager) Wait(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return nil
+	case err := <-m.failed:
+		return err
+	}
+}
+
+// Stop will stop the started components in reverse order
+func (m *Manager) Stop(ctx context.Context) error {
+	errs := StopErrors{}
+	for i := len(m.running) - 1; i >= 0; i-- {
+		r := m.running[i]
+		begin := time.Now()
+		if err := m.stop(ctx, r); err != nil {
+			m.logger().Error("component stop", logx.Fields{"component": r.Name, "error": err})
+			errs = append(errs, fmt.Errorf("%s %w", r.Name, err))
+			continue
+		}
+		m.logger().Info("component stopped", logx.Fields{"component": r.Name, "duration_ms": float64(time.Since(begin).Microseconds()) / 1000})
+	}
+	m.running = nil
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (m *Manager) stop(ctx context.Context, r *running) error {
+	var err error
+	if r.Stop != nil {
+		err = r.Stop(ctx)
+	}
+	if r.Run == nil {
+		return err
+	}
+
+	r.cancel()
+	select {
+	case <-r.done:
+	case <-ctx.Done():
+		return fmt.Errorf("waiting to finish %w", ctx.Err())
+	}
+	if err == nil && r.failed == false && r.err != nil && errors.Is(r.err, context.Canceled) == false {
+		err = r.err
+	}
+	return err
+}
+
+// Run will start the components, wait for the context to be done or a component to fail and then stop them
+func (m *Manager) Run(ctx context.Context) error {
+	if err := m.Start(ctx); err != nil {
+		return err
+	}
+	runErr := m.Wait(ctx)
+	if runErr != nil {
+		m.logger().Error("component failed", logx.Fields{"error": runErr})
+	}
+
+	stopCtx, cancel := m.stopContext()
+	defer cancel()
+	stopErr := m.Stop(stopCtx)
+	if runErr != nil {
+		return runErr
+	}
+	return stopErr
+}
+
+func (m *Manager) stopContext() (context.Context, context.CancelFunc) {
+	timeout := m.StopTimeout
+	if timeout <= 0 {
+		timeout = defaultStopTimeout
+	}
+	return context.WithTimeout(logx.NewContext(context.Background(), m.logger()), timeout)
+}
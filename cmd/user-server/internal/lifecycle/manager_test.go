@@ -0,0 +1,168 @@
+package lifecycle
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/g8rswimmer/go-data-access-example/pkg/logx"
+)
+
+type recorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *recorder) add(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *recorder) component(name string, startErr error) Component {
+	return Component{
+		Name: name,
+		Start: func(ctx context.Context) error {
+			r.add("start " + name)
+			return startErr
+		},
+		Stop: func(ctx context.Context) error {
+			r.add("stop " + name)
+			return nil
+		},
+	}
+}
+
+func TestManager_Run(t *testing.T) {
+	errBind := errors.New("address in use")
+	errJob := errors.New("job failed")
+	tests := []struct {
+		name       string
+		components func(r *recorder) []Component
+		cancel     bool
+		want       []string
+		wantStart  bool
+		wantRunErr error
+	}{
+		{
+			name: "reverse stop order",
+			components: func(r *recorder) []Component {
+				return []Component{r.component("database", nil), r.component("http", nil)}
+			},
+			cancel: true,
+			want:   []string{"start database", "start http", "stop http", "stop database"},
+		},
+		{
+			name: "start failure stops started",
+			components: func(r *recorder) []Component {
+				return []Component{r.component("database", nil), r.component("http", errBind), r.component("jobs", nil)}
+			},
+			want:      []string{"start database", "start http", "stop database"},
+			wantStart: true,
+		},
+		{
+			name: "run failure stops all",
+			components: func(r *recorder) []Component {
+				return []Component{
+					r.component("database", nil),
+					Job("sweeper", func(ctx context.Context) error {
+						return errJob
+					}),
+				}
+			},
+			want:       []string{"start database", "stop database"},
+			wantRunErr: errJob,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &recorder{}
+			m := &Manager{
+				Logger:      logx.New(&bytes.Buffer{}, logx.ErrorLevel),
+				StopTimeout: time.Second,
+			}
+			for _, c := range tt.components(r) {
+				m.Add(c)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancel {
+				cancel()
+			}
+
+			err := m.Run(ctx)
+			var startErr *StartError
+			if errors.As(err, &startErr) != tt.wantStart {
+				t.Errorf("Manager.Run() error = %v, want start error %v", err, tt.wantStart)
+				return
+			}
+			if tt.wantStart && errors.Is(err, errBind) == false {
+				t.Errorf("Manager.Run() error = %v, want %v", err, errBind)
+			}
+			var runErr *RunError
+			if tt.wantRunErr != nil && (errors.As(err, &runErr) == false || errors.Is(err, tt.wantRunErr) == false) {
+				t.Errorf("Manager.Run() error = %v, want run error %v", err, tt.wantRunErr)
+			}
+			if tt.wantStart == false && tt.wantRunErr == nil && err != nil {
+				t.Errorf("Manager.Run() error = %v", err)
+			}
+			if reflect.DeepEqual(r.events, tt.want) == false {
+				t.Errorf("Manager.Run() events = %v, want %v", r.events, tt.want)
+			}
+		})
+	}
+}
+
+func TestManager_StopJob(t *testing.T) {
+	stopped := make(chan struct{})
+	m := &Manager{
+		Logger: logx.New(&bytes.Buffer{}, logx.ErrorLevel),
+	}
+	m.Add(Job("worker", func(ctx context.Context) error {
+		<-ctx.Done()
+		close(stopped)
+		return ctx.Err()
+	}))
+
+	if err := m.Start(context.Background()); err != nil {
+		t.Errorf("Manager.Start() error = %v", err)
+		return
+	}
+	if err := m.Stop(context.Background()); err != nil {
+		t.Errorf("Manager.Stop() error = %v", err)
+	}
+	select {
+	case <-stopped:
+	default:
+		t.Errorf("Manager.Stop() returned before the job finished")
+	}
+}
+
+func TestManager_StopTimeout(t *testing.T) {
+	m := &Manager{
+		Logger: logx.New(&bytes.Buffer{}, logx.ErrorLevel),
+	}
+	release := make(chan struct{})
+	defer close(release)
+	m.Add(Job("stuck", func(ctx context.Context) error {
+		<-release
+		return nil
+	}))
+
+	if err := m.Start(context.Background()); err != nil {
+		t.Errorf("Manager.Start() error = %v", err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := m.Stop(ctx)
+	var errs StopErrors
+	if errors.As(err, &errs) == false || len(errs) != 1 || errors.Is(errs[0], context.DeadlineExceeded) == false {
+		t.Errorf("Manager.Stop() error = %v, want deadline exceeded", err)
+	}
+}
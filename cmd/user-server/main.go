@@ -2,55 +2,313 @@ package main
 
 import (
 	"context"
-	"log"
+	"database/sql"
+	"errors"
+	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/g8rswimmer/go-data-access-example/cmd/user-server/internal/build"
+	"github.com/g8rswimmer/go-data-access-example/cmd/user-server/internal/config"
 	"github.com/g8rswimmer/go-data-access-example/cmd/user-server/internal/database"
-	"github.com/g8rswimmer/go-data-access-example/cmd/user-server/internal/env"
 	"github.com/g8rswimmer/go-data-access-example/cmd/user-server/internal/httpx"
+	"github.com/g8rswimmer/go-data-access-example/cmd/user-server/internal/lifecycle"
+	"github.com/g8rswimmer/go-data-access-example/pkg/api/group"
+	"github.com/g8rswimmer/go-data-access-example/pkg/api/organization"
 	"github.com/g8rswimmer/go-data-access-example/pkg/api/user"
 	"github.com/g8rswimmer/go-data-access-example/pkg/dal"
+	"github.com/g8rswimmer/go-data-access-example/pkg/logx"
+	"github.com/g8rswimmer/go-data-access-example/pkg/metrics"
+	"github.com/g8rswimmer/go-data-access-example/pkg/trace"
 	"github.com/google/uuid"
 )
 
-var info = httpx.Info{
-	Name:    "user-dal-example",
-	Version: "v0.1.0",
-}
+const (
+	name           = "user-dal-example"
+	defaultVersion = "v0.1.0"
+	reloadInterval = time.Second * 5
+)
+
+// exit statuses
+const (
+	exitOK = iota
+	// exitFailure is a component that failed while running or did not stop cleanly
+	exitFailure
+	exitConfig
+	exitStartup
+)
 
 func main() {
-	ctx := context.Background()
+	os.Exit(run(os.Args[1:]))
+}
 
-	config := env.Load()
+// run will start the service until it is signaled and returns the exit status
+func run(args []string) int {
+	if len(args) > 0 && args[0] == "config" {
+		return configCommand(args[1:])
+	}
 
-	db, err := database.Open(ctx, []string{dal.UserTable})
+	cfg, err := config.Load(args)
 	if err != nil {
-		log.Panic(err)
+		reportConfig(err)
+		return exitConfig
 	}
-	defer db.Close()
 
-	u := &user.Handler{
-		UserDAO: &dal.User{
-			DB: db,
-			GenerateUUID: func() string {
+	logger := logx.Default()
+	if level, err := logx.ParseLevel(cfg.Log.Level); err == nil {
+		logger.SetLevel(level)
+	}
+	ctx, cancel := context.WithCancel(logx.NewContext(context.Background(), logger))
+	defer cancel()
+
+	reloader := config.NewReloader(args, cfg)
+	limiter := httpx.NewRateLimiter(cfg.RateLimit.RequestsPerSecond, cfg.RateLimit.Burst)
+	cors := httpx.NewCORS(corsPolicy(cfg))
+	reloader.OnReload(func(c *config.Config) {
+		if level, err := logx.ParseLevel(c.Log.Level); err == nil {
+			logger.SetLevel(level)
+		}
+		limiter.SetLimit(c.RateLimit.RequestsPerSecond, c.RateLimit.Burst)
+		cors.SetPolicy(corsPolicy(c))
+		logger.Info("configuration reloaded", logx.Fields{"ignored": reloader.Status().Ignored})
+	})
+
+	registry := metrics.NewRegistry()
+	tables := dal.Tables
+	var db *sql.DB
+	var server *httpx.Server
+
+	manager := &lifecycle.Manager{
+		Logger:      logger,
+		StopTimeout: cfg.Lifecycle.StopTimeout,
+	}
+	manager.Add(lifecycle.Component{
+		Name: "database",
+		Start: func(ctx context.Context) error {
+			var err error
+			db, err = database.Open(ctx, cfg.Database.DSN, tables)
+			if err != nil {
+				return err
+			}
+			database.RegisterStats(registry, db)
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			return db.Close()
+		},
+	})
+	manager.Add(lifecycle.Component{
+		Name: "http",
+		Start: func(ctx context.Context) error {
+			dbVersion, err := database.Version(ctx, db)
+			if err != nil {
+				logger.Warn("database version", logx.Fields{"error": err})
+			}
+
+			bi := build.Read(defaultVersion)
+			info := httpx.Info{
+				Name:    name,
+				Version: bi.Version,
+				Build:   bi,
+				Database: &httpx.DatabaseInfo{
+					Driver:  database.Driver,
+					Version: dbVersion,
+				},
+				ConfigValues: func() map[string]string {
+					return reloader.Current().Values()
+				},
+				ReloadStatus: func() interface{} {
+					return reloader.Status()
+				},
+			}
+
+			queryDuration := registry.NewHistogramVec("dal_query_duration_seconds", "Duration of data access layer methods.", metrics.DefBuckets, "entity", "method")
+			generateUUID := func() string {
 				return uuid.New().String()
-			},
+			}
+			u := &user.Handler{
+				UserDAO: &dal.User{
+					DB:            db,
+					GenerateUUID:  generateUUID,
+					QueryDuration: queryDuration,
+				},
+			}
+			g := &group.Handler{
+				GroupDAO: &dal.Group{
+					DB:            db,
+					GenerateUUID:  generateUUID,
+					QueryDuration: queryDuration,
+				},
+			}
+			o := &organization.Handler{
+				OrganizationDAO: &dal.Organization{
+					DB:            db,
+					GenerateUUID:  generateUUID,
+					QueryDuration: queryDuration,
+				},
+			}
+
+			var tracer *trace.Tracer
+			if cfg.Trace.Exporter == "stdout" {
+				tracer = trace.NewTracer(trace.NewStdoutExporter(os.Stdout))
+			}
+
+			telemetry := httpx.Telemetry{
+				Logger:   logger,
+				Registry: registry,
+				Tracer:   tracer,
+			}
+			health := httpx.Health{
+				Checks: []httpx.Check{
+					{
+						Name:  "database",
+						Check: db.PingContext,
+					},
+					{
+						Name: "migrations",
+						Check: func(ctx context.Context) error {
+							return database.Migrated(ctx, db, tables)
+						},
+					},
+				},
+				DrainDelay: cfg.HTTP.DrainDelay,
+			}
+
+			server, err = httpx.NewServer(info, serverConfig(cfg), telemetry, health, httpx.Policy{RateLimiter: limiter, CORS: cors})
+			if err != nil {
+				return err
+			}
+			if err := server.Start([]httpx.Router{u, g, o}); err != nil {
+				return err
+			}
+			logger.Info("server running", logx.Fields{"listen": cfg.HTTP.Listen, "address": serverConfig(cfg).Address()})
+			return nil
+		},
+		Run: func(ctx context.Context) error {
+			return server.Serve()
+		},
+		Stop: func(ctx context.Context) error {
+			return server.Shutdown(ctx)
 		},
+	})
+	manager.Add(lifecycle.Job("config-watch", func(ctx context.Context) error {
+		reloader.Watch(ctx, reloadInterval, func(err error) {
+			logger.Error("configuration reload", logx.Fields{"error": err})
+		})
+		return nil
+	}))
+
+	go handleSignals(ctx, cancel, reloader, logger)
+
+	err = manager.Run(ctx)
+	var startErr *lifecycle.StartError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.As(err, &startErr):
+		logger.Error("server start", logx.Fields{"error": err})
+		return exitStartup
+	default:
+		logger.Error("server stopped", logx.Fields{"error": err})
+		return exitFailure
 	}
+}
 
-	server := httpx.NewServer(info, config.HTTP.Port, config.HTTP.ReadTimeout, config.HTTP.WriteTimeout)
-	server.Start([]httpx.Router{u})
-	defer func() {
-		if err := server.Shutdown(context.Background()); err != nil {
-			panic(err)
+// serverConfig returns the listener configuration of the http server
+func serverConfig(cfg *config.Config) httpx.Config {
+	sc := httpx.Config{
+		Network:      cfg.HTTP.Listen,
+		Port:         cfg.HTTP.Port,
+		SocketPath:   cfg.HTTP.Socket,
+		SocketMode:   cfg.HTTP.SocketMode,
+		H2C:          cfg.HTTP.H2C,
+		ReadTimeout:  cfg.HTTP.ReadTimeout,
+		WriteTimeout: cfg.HTTP.WriteTimeout,
+		MaxBodySize:  cfg.HTTP.MaxBodySize,
+	}
+	available := httpx.DefaultEncoders()
+	encoders := map[string]httpx.Encoder{}
+	for _, coding := range cfg.Compression.Encodings {
+		encoders[coding] = available[coding]
+	}
+	sc.Compression = &httpx.Compression{
+		MinSize:        cfg.Compression.MinSize,
+		ContentTypes:   cfg.Compression.ContentTypes,
+		Encoders:       encoders,
+		MaxRequestSize: cfg.Compression.MaxRequestSize,
+	}
+	if cfg.TLS.Enabled() {
+		sc.TLS = &httpx.TLS{
+			CertFile:     cfg.TLS.CertFile,
+			KeyFile:      cfg.TLS.KeyFile,
+			MinVersion:   cfg.TLS.Version(),
+			CipherSuites: cfg.TLS.CipherSuites(),
+			ClientCAFile: cfg.TLS.ClientCAFile,
+			ClientAuth:   cfg.TLS.ClientAuthType(),
 		}
-	}()
+	}
+	return sc
+}
 
-	log.Printf("server running on port %s", config.HTTP.Port)
+// corsPolicy returns the cross origin policy of the configuration
+func corsPolicy(cfg *config.Config) httpx.CORSPolicy {
+	return httpx.CORSPolicy{
+		AllowedOrigins:   cfg.CORS.AllowedOrigins,
+		AllowedMethods:   cfg.CORS.AllowedMethods,
+		AllowedHeaders:   cfg.CORS.AllowedHeaders,
+		AllowCredentials: cfg.CORS.AllowCredentials,
+		MaxAge:           cfg.CORS.MaxAge,
+	}
+}
 
+// handleSignals reloads the configuration on SIGHUP and cancels on interrupt, a second interrupt exits at once
+func handleSignals(ctx context.Context, cancel context.CancelFunc, reloader *config.Reloader, logger *logx.Logger) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
 	sig := make(chan os.Signal, 1)
-	signal.Notify(sig, os.Interrupt, os.Kill, syscall.SIGINT, syscall.SIGTERM)
-	_ = <-sig
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	for {
+		select {
+		case <-hup:
+			if err := reloader.Reload(); err != nil {
+				logger.Error("configuration reload", logx.Fields{"error": err})
+			}
+		case s := <-sig:
+			if ctx.Err() != nil {
+				logger.Error("forced exit", logx.Fields{"signal": s.String()})
+				os.Exit(exitFailure)
+			}
+			logger.Info("shutting down", logx.Fields{"signal": s.String()})
+			cancel()
+		}
+	}
+}
+
+// configCommand handles the config sub commands and returns the exit status
+func configCommand(args []string) int {
+	if len(args) == 0 || args[0] != "print" {
+		fmt.Fprintf(os.Stderr, "usage: user-server config print [flags]\n%s", config.Usage())
+		return exitConfig
+	}
+	cfg, err := config.Load(args[1:])
+	if err != nil {
+		reportConfig(err)
+		return exitConfig
+	}
+	cfg.Print(os.Stdout)
+	return exitOK
+}
+
+func reportConfig(err error) {
+	var errs config.Errors
+	if errors.As(err, &errs) == false {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "configuration has %d errors\n", len(errs))
+	for _, e := range errs {
+		fmt.Fprintf(os.Stderr, "  %s\n", e)
+	}
 }
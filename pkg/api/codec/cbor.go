@@ -0,0 +1,323 @@
+package codec
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+)
+
+// CBOR major types
+const (
+	cborUint   = 0
+	cborNegInt = 1
+	cborBytes  = 2
+	cborText   = 3
+	cborArray  = 4
+	cborMap    = 5
+	cborTag    = 6
+	cborSimple = 7
+)
+
+const (
+	cborFalse     = 0xf4
+	cborTrue      = 0xf5
+	cborNull      = 0xf6
+	cborUndefined = 0xf7
+	cborFloat16   = 0xf9
+	cborFloat32   = 0xfa
+	cborFloat64   = 0xfb
+	cborBreak     = 0xff
+	// cborIndefinite is the additional information of an indefinite length item
+	cborIndefinite = 31
+)
+
+// encodeCBOR encodes the generic value with definite lengths in the preferred serialization
+func encodeCBOR(g interface{}) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if err := writeCBOR(buf, g); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeCBOR(buf *bytes.Buffer, g interface{}) error {
+	switch t := g.(type) {
+	case nil:
+		buf.WriteByte(cborNull)
+	case bool:
+		if t {
+			buf.WriteByte(cborTrue)
+		} else {
+			buf.WriteByte(cborFalse)
+		}
+	case int64:
+		if t >= 0 {
+			writeCBORHead(buf, cborUint, uint64(t))
+		} else {
+			writeCBORHead(buf, cborNegInt, uint64(-1-t))
+		}
+	case uint64:
+		writeCBORHead(buf, cborUint, t)
+	case float64:
+		buf.WriteByte(cborFloat64)
+		writeUint(buf, math.Float64bits(t), 8)
+	case string:
+		writeCBORHead(buf, cborText, uint64(len(t)))
+		buf.WriteString(t)
+	case []interface{}:
+		writeCBORHead(buf, cborArray, uint64(len(t)))
+		for _, v := range t {
+			if err := writeCBOR(buf, v); err != nil {
+				return err
+			}
+		}
+	case object:
+		writeCBORHead(buf, cborMap, uint64(len(t)))
+		for _, m := range t {
+			writeCBORHead(buf, cborText, uint64(len(m.key)))
+			buf.WriteString(m.key)
+			if err := writeCBOR(buf, m.value); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("%w cbor can not encode %T", ErrFormat, g)
+	}
+	return nil
+}
+
+// writeCBORHead writes the major type with the argument in the fewest bytes
+func writeCBORHead(buf *bytes.Buffer, major byte, arg uint64) {
+	m := major << 5
+	switch {
+	case arg < 24:
+		buf.WriteByte(m | byte(arg))
+	case arg <= math.MaxUint8:
+		buf.WriteByte(m | 24)
+		buf.WriteByte(byte(arg))
+	case arg <= math.MaxUint16:
+		buf.WriteByte(m | 25)
+		writeUint(buf, arg, 2)
+	case arg <= math.MaxUint32:
+		buf.WriteByte(m | 26)
+		writeUint(buf, arg, 4)
+	default:
+		buf.WriteByte(m | 27)
+		writeUint(buf, arg, 8)
+	}
+}
+
+// decodeCBOR decodes a single CBOR item, tags are skipped and byte strings are read as text
+func decodeCBOR(data []byte) (interface{}, error) {
+	r := &reader{data: data}
+	g, err := readCBOR(r, 0)
+	if err != nil {
+		return nil, err
+	}
+	if g == errBreak {
+		return nil, fmt.Errorf("%w cbor unexpected break", ErrFormat)
+	}
+	if r.pos != len(r.data) {
+		return nil, fmt.Errorf("%w cbor has %d trailing bytes", ErrFormat, len(r.data)-r.pos)
+	}
+	return g, nil
+}
+
+// errBreak marks the end of an indefinite length item
+var errBreak = &struct{}{}
+
+func readCBOR(r *reader, depth int) (interface{}, error) {
+	if depth > maxDepth {
+		return nil, fmt.Errorf("%w cbor nested too deep", ErrFormat)
+	}
+	b, err := r.byte()
+	if err != nil {
+		return nil, err
+	}
+	major, info := b>>5, b&0x1f
+
+	if major == cborSimple {
+		return readCBORSimple(r, b)
+	}
+	if info == cborIndefinite {
+		return readCBORIndefinite(r, major, depth)
+	}
+	arg, err := readCBORArgument(r, info)
+	if err != nil {
+		return nil, err
+	}
+
+	switch major {
+	case cborUint:
+		if arg <= math.MaxInt64 {
+			return int64(arg), nil
+		}
+		return arg, nil
+	case cborNegInt:
+		if arg > math.MaxInt64 {
+			return nil, fmt.Errorf("%w cbor negative integer overflows", ErrFormat)
+		}
+		return -1 - int64(arg), nil
+	case cborBytes, cborText:
+		if arg > uint64(r.remaining()) {
+			return nil, fmt.Errorf("%w cbor string length %d", ErrFormat, arg)
+		}
+		return r.string(int(arg))
+	case cborArray:
+		if arg > uint64(r.remaining()) {
+			return nil, fmt.Errorf("%w cbor array length %d", ErrFormat, arg)
+		}
+		arr := make([]interface{}, 0, int(arg))
+		for i := uint64(0); i < arg; i++ {
+			v, err := readCBORItem(r, depth)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+		return arr, nil
+	case cborMap:
+		if arg*2 > uint64(r.remaining()) {
+			return nil, fmt.Errorf("%w cbor map length %d", ErrFormat, arg)
+		}
+		obj := make(object, 0, int(arg))
+		for i := uint64(0); i < arg; i++ {
+			m, err := readCBORMember(r, depth)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, m)
+		}
+		return obj, nil
+	default:
+		// a tag only describes the item that follows
+		return readCBOR(r, depth+1)
+	}
+}
+
+// readCBORItem reads an item that may not be a break
+func readCBORItem(r *reader, depth int) (interface{}, error) {
+	v, err := readCBOR(r, depth+1)
+	if err == nil && v == errBreak {
+		return nil, fmt.Errorf("%w cbor unexpected break", ErrFormat)
+	}
+	return v, err
+}
+
+func readCBORMember(r *reader, depth int) (member, error) {
+	k, err := readCBORItem(r, depth)
+	if err != nil {
+		return member{}, err
+	}
+	key, ok := k.(string)
+	if ok == false {
+		return member{}, fmt.Errorf("%w cbor map key %v is not a string", ErrFormat, k)
+	}
+	v, err := readCBORItem(r, depth)
+	return member{key: key, value: v}, err
+}
+
+func readCBORArgument(r *reader, info byte) (uint64, error) {
+	switch {
+	case info < 24:
+		return uint64(info), nil
+	case info <= 27:
+		return r.uint(1 << (info - 24))
+	default:
+		return 0, fmt.Errorf("%w cbor additional information %d", ErrFormat, info)
+	}
+}
+
+func readCBORIndefinite(r *reader, major byte, depth int) (interface{}, error) {
+	switch major {
+	case cborBytes, cborText:
+		s := ""
+		for {
+			v, err := readCBOR(r, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			if v == errBreak {
+				return s, nil
+			}
+			chunk, ok := v.(string)
+			if ok == false {
+				return nil, fmt.Errorf("%w cbor string chunk is %T", ErrFormat, v)
+			}
+			s += chunk
+		}
+	case cborArray:
+		arr := []interface{}{}
+		for {
+			v, err := readCBOR(r, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			if v == errBreak {
+				return arr, nil
+			}
+			arr = append(arr, v)
+		}
+	case cborMap:
+		obj := object{}
+		for {
+			if r.remaining() > 0 && r.data[r.pos] == cborBreak {
+				r.pos++
+				return obj, nil
+			}
+			m, err := readCBORMember(r, depth)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, m)
+		}
+	default:
+		return nil, fmt.Errorf("%w cbor major type %d can not have an indefinite length", ErrFormat, major)
+	}
+}
+
+func readCBORSimple(r *reader, b byte) (interface{}, error) {
+	switch b {
+	case cborFalse:
+		return false, nil
+	case cborTrue:
+		return true, nil
+	case cborNull, cborUndefined:
+		return nil, nil
+	case cborFloat16:
+		v, err := r.uint(2)
+		return float16(uint16(v)), err
+	case cborFloat32:
+		v, err := r.uint(4)
+		return float64(math.Float32frombits(uint32(v))), err
+	case cborFloat64:
+		v, err := r.uint(8)
+		return math.Float64frombits(v), err
+	case cborBreak:
+		return errBreak, nil
+	}
+	return nil, fmt.Errorf("%w cbor simple value 0x%02x is not supported", ErrFormat, b)
+}
+
+// float16 converts an IEEE 754 half precision float
+func float16(h uint16) float64 {
+	exp := int(h>>10) & 0x1f
+	mant := float64(h & 0x3ff)
+	var v float64
+	switch exp {
+	case 0:
+		v = math.Ldexp(mant, -24)
+	case 0x1f:
+		if mant == 0 {
+			v = math.Inf(1)
+		} else {
+			v = math.NaN()
+		}
+	default:
+		v = math.Ldexp(mant+1024, exp-25)
+	}
+	if h&0x8000 != 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,112 @@
+package codec
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+)
+
+// Codec encodes and decodes a media type
+type Codec struct {
+	// MediaType is the type sent in the Content-Type header
+	MediaType string
+	// Aliases are the other media types that select the codec
+	Aliases   []string
+	Marshal   func(v interface{}) ([]byte, error)
+	Unmarshal func(data []byte, v interface{}) error
+}
+
+// Matches returns if the media type selects the codec
+func (c *Codec) Matches(mediaType string) bool {
+	if c.MediaType == mediaType {
+		return true
+	}
+	for _, a := range c.Aliases {
+		if a == mediaType {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrFormat is returned when the data is not valid for the codec
+var ErrFormat = errors.New("invalid encoding")
+
+// maxDepth limits the nesting of decoded arrays and maps
+const maxDepth = 64
+
+var (
+	// JSON is the application/json codec
+	JSON = &Codec{
+		MediaType: "application/json",
+		Marshal:   json.Marshal,
+		Unmarshal: json.Unmarshal,
+	}
+	// XML is the application/xml codec, the elements are named by the json field names
+	XML = &Codec{
+		MediaType: "application/xml",
+		Aliases:   []string{"text/xml"},
+		Marshal:   viaGeneric(encodeXML),
+		Unmarshal: fromGenericDecoder(decodeXML),
+	}
+	// MessagePack is the application/msgpack codec
+	MessagePack = &Codec{
+		MediaType: "application/msgpack",
+		Aliases:   []string{"application/x-msgpack", "application/vnd.msgpack"},
+		Marshal:   viaGeneric(encodeMessagePack),
+		Unmarshal: fromGenericDecoder(decodeMessagePack),
+	}
+	// CBOR is the application/cbor codec
+	CBOR = &Codec{
+		MediaType: "application/cbor",
+		Marshal:   viaGeneric(encodeCBOR),
+		Unmarshal: fromGenericDecoder(decodeCBOR),
+	}
+)
+
+// viaGeneric marshals the value as json would and encodes the generic value
+func viaGeneric(encode func(g interface{}) ([]byte, error)) func(v interface{}) ([]byte, error) {
+	return func(v interface{}) ([]byte, error) {
+		g, err := toGeneric(v)
+		if err != nil {
+			return nil, err
+		}
+		return encode(g)
+	}
+}
+
+// fromGenericDecoder decodes the generic value and unmarshals it as json would, rejecting unknown fields
+func fromGenericDecoder(decode func(data []byte) (interface{}, error)) func(data []byte, v interface{}) error {
+	return func(data []byte, v interface{}) error {
+		g, err := decode(data)
+		if err != nil {
+			return err
+		}
+		return fromGeneric(g, v)
+	}
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = []*Codec{JSON, XML, MessagePack, CBOR}
+)
+
+// Register adds the codec or replaces the registered codec with the same media type
+func Register(c *Codec) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	for i, r := range registry {
+		if r.MediaType == c.MediaType {
+			registry[i] = c
+			return
+		}
+	}
+	registry = append(registry, c)
+}
+
+// Codecs returns the registered codecs, the first is the default
+func Codecs() []*Codec {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return append([]*Codec{}, registry...)
+}
@@ -0,0 +1,75 @@
+//go:build go1.18
+// +build go1.18
+
+package codec
+
+import (
+	"bytes"
+	"testing"
+)
+
+// fuzzDecoder checks that the decoder does not panic and that a decoded value encodes and decodes to the same encoding
+func fuzzDecoder(f *testing.F, c *Codec, decode func(data []byte) (interface{}, error), encode func(g interface{}) ([]byte, error)) {
+	seeds := []interface{}{
+		record{
+			Name:   "test <&> testison",
+			Count:  -1000,
+			Ratio:  1.5,
+			Big:    1<<64 - 1,
+			Active: true,
+			Tags:   []string{"a", "b"},
+			Labels: map[string]string{"team": "data", "cost center": "1 < 2", "": "empty"},
+			Parent: &record{Name: "parent", Tags: []string{}},
+		},
+		[]interface{}{nil, true, -32, 1 << 40, 0.1, "", []int{}},
+		"IETF",
+	}
+	for _, s := range seeds {
+		enc, err := c.Marshal(s)
+		if err != nil {
+			f.Fatalf("Marshal() error = %v", err)
+		}
+		f.Add(enc)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		_ = c.Unmarshal(data, &record{})
+		g, err := decode(data)
+		if err != nil {
+			return
+		}
+		enc, err := encode(g)
+		if err != nil {
+			t.Fatalf("encode() of a decoded value error = %v", err)
+		}
+		again, err := decode(enc)
+		if err != nil {
+			t.Fatalf("decode() of %x error = %v", enc, err)
+		}
+		reenc, err := encode(again)
+		if err != nil {
+			t.Fatalf("encode() of a decoded value error = %v", err)
+		}
+		if bytes.Equal(enc, reenc) == false {
+			t.Errorf("encode() = %x, want %x", reenc, enc)
+		}
+	})
+}
+
+func FuzzDecodeMessagePack(f *testing.F) {
+	f.Add([]byte{0xc1})
+	f.Add([]byte{0xdf, 0xff, 0xff, 0xff, 0xff})
+	fuzzDecoder(f, MessagePack, decodeMessagePack, encodeMessagePack)
+}
+
+func FuzzDecodeCBOR(f *testing.F) {
+	f.Add([]byte{0x9f, 0x01, 0xff})
+	f.Add([]byte{0xbf, 0x61, 0x61, 0x01, 0xff})
+	f.Add([]byte{0xf9, 0x7c, 0x00})
+	fuzzDecoder(f, CBOR, decodeCBOR, encodeCBOR)
+}
+
+func FuzzDecodeXML(f *testing.F) {
+	f.Add([]byte(`<response><entry key="a b">1</entry></response>`))
+	f.Add([]byte(`<response type="array"><item type="number">1e3</item></response>`))
+	fuzzDecoder(f, XML, decodeXML, encodeXML)
+}
@@ -0,0 +1,194 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type record struct {
+	Name    string            `json:"name"`
+	Count   int               `json:"count"`
+	Ratio   float64           `json:"ratio"`
+	Big     uint64            `json:"big"`
+	Active  bool              `json:"active"`
+	Tags    []string          `json:"tags"`
+	Labels  map[string]string `json:"labels"`
+	Parent  *record           `json:"parent"`
+	Missing *string           `json:"missing"`
+}
+
+func TestCodec_RoundTrip(t *testing.T) {
+	want := record{
+		Name:   "test <&> testison",
+		Count:  -1000,
+		Ratio:  1.5,
+		Big:    1<<64 - 1,
+		Active: true,
+		Tags:   []string{"a", "b"},
+		Labels: map[string]string{"team": "data", "cost center": "1 < 2", "xmlns": "x", "": "empty"},
+		Parent: &record{Name: "parent", Tags: []string{}},
+	}
+	tests := []struct {
+		name  string
+		codec *Codec
+	}{
+		{name: "json", codec: JSON},
+		{name: "xml", codec: XML},
+		{name: "msgpack", codec: MessagePack},
+		{name: "cbor", codec: CBOR},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enc, err := tt.codec.Marshal(want)
+			if err != nil {
+				t.Errorf("Marshal() error = %v", err)
+				return
+			}
+			got := record{}
+			if err := tt.codec.Unmarshal(enc, &got); err != nil {
+				t.Errorf("Unmarshal() error = %v", err)
+				return
+			}
+			if reflect.DeepEqual(got, want) == false {
+				t.Errorf("Unmarshal() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestCodec_Vectors(t *testing.T) {
+	tests := []struct {
+		name  string
+		codec *Codec
+		value interface{}
+		hex   string
+	}{
+		{name: "cbor uint", codec: CBOR, value: 100, hex: "1864"},
+		{name: "cbor negative", codec: CBOR, value: -1000, hex: "3903e7"},
+		{name: "cbor array", codec: CBOR, value: []int{1, 2, 3}, hex: "83010203"},
+		{name: "cbor text", codec: CBOR, value: "IETF", hex: "6449455446"},
+		{name: "cbor map", codec: CBOR, value: struct {
+			A int `json:"a"`
+		}{A: 1}, hex: "a1616101"},
+		{name: "msgpack map", codec: MessagePack, value: struct {
+			Compact bool `json:"compact"`
+			Schema  int  `json:"schema"`
+		}{Compact: true}, hex: "82a7636f6d70616374c3a6736368656d6100"},
+		{name: "msgpack negative fixint", codec: MessagePack, value: -32, hex: "e0"},
+		{name: "msgpack int16", codec: MessagePack, value: -129, hex: "d1ff7f"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enc, err := tt.codec.Marshal(tt.value)
+			if err != nil {
+				t.Errorf("Marshal() error = %v", err)
+				return
+			}
+			if got := hex.EncodeToString(enc); got != tt.hex {
+				t.Errorf("Marshal() = %s, want %s", got, tt.hex)
+			}
+		})
+	}
+}
+
+func TestCodec_Decode(t *testing.T) {
+	tests := []struct {
+		name    string
+		codec   *Codec
+		data    string
+		want    interface{}
+		wantErr error
+	}{
+		{name: "cbor half float", codec: CBOR, data: "f93c00", want: 1.0},
+		{name: "cbor indefinite array", codec: CBOR, data: "9f0102ff", want: []interface{}{1.0, 2.0}},
+		{name: "cbor indefinite text", codec: CBOR, data: "7f62616262636dff", want: "abcm"},
+		{name: "cbor tag", codec: CBOR, data: "c11a514b67b0", want: 1363896240.0},
+		{name: "cbor trailing", codec: CBOR, data: "0101", wantErr: ErrFormat},
+		{name: "cbor short", codec: CBOR, data: "1903", wantErr: ErrFormat},
+		{name: "cbor huge array", codec: CBOR, data: "9bffffffffffffffff", wantErr: ErrFormat},
+		{name: "cbor deep", codec: CBOR, data: string(bytes.Repeat([]byte("81"), maxDepth+2)) + "01", wantErr: ErrFormat},
+		{name: "msgpack float32", codec: MessagePack, data: "ca3fc00000", want: 1.5},
+		{name: "msgpack array16", codec: MessagePack, data: "dc0002c3c2", want: []interface{}{true, false}},
+		{name: "msgpack int key", codec: MessagePack, data: "810101", wantErr: ErrFormat},
+		{name: "msgpack huge map", codec: MessagePack, data: "dfffffffff", wantErr: ErrFormat},
+		{name: "msgpack ext", codec: MessagePack, data: "d40100", wantErr: ErrFormat},
+		{name: "xml trailing", codec: XML, data: hex.EncodeToString([]byte("<a>b</a><c/>")), wantErr: ErrFormat},
+		{name: "xml unclosed", codec: XML, data: hex.EncodeToString([]byte("<a>b")), wantErr: ErrFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, _ := hex.DecodeString(tt.data)
+			var got interface{}
+			err := tt.codec.Unmarshal(data, &got)
+			if errors.Is(err, tt.wantErr) == false {
+				t.Errorf("Unmarshal() error = %v, want %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr == nil && reflect.DeepEqual(got, tt.want) == false {
+				t.Errorf("Unmarshal() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCodec_UnknownField(t *testing.T) {
+	// {"nickname":"t"}
+	data, _ := hex.DecodeString("81a86e69636b6e616d65a174")
+	got := record{}
+	if err := MessagePack.Unmarshal(data, &got); err == nil {
+		t.Errorf("Unmarshal() expected an unknown field error")
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	codecs := []*Codec{JSON, XML, MessagePack, CBOR}
+	tests := []struct {
+		name   string
+		accept string
+		want   *Codec
+	}{
+		{name: "missing", accept: "", want: JSON},
+		{name: "exact", accept: "application/cbor", want: CBOR},
+		{name: "alias", accept: "application/x-msgpack", want: MessagePack},
+		{name: "any", accept: "*/*", want: JSON},
+		{name: "quality", accept: "application/json;q=0.5, application/xml", want: XML},
+		{name: "type wildcard", accept: "text/*", want: XML},
+		{name: "specific excludes", accept: "*/*, application/json;q=0", want: XML},
+		{name: "tie in server order", accept: "application/cbor, application/msgpack", want: MessagePack},
+		{name: "nothing", accept: "text/html", want: nil},
+		{name: "invalid quality", accept: "application/json;q=2", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Negotiate(tt.accept, codecs); got != tt.want {
+				t.Errorf("Negotiate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestForContentType(t *testing.T) {
+	codecs := []*Codec{JSON, XML, MessagePack, CBOR}
+	tests := []struct {
+		name        string
+		contentType string
+		want        *Codec
+	}{
+		{name: "json", contentType: "application/json; charset=utf-8", want: JSON},
+		{name: "json suffix", contentType: "application/merge-patch+json", want: JSON},
+		{name: "xml alias", contentType: "text/xml", want: XML},
+		{name: "cbor", contentType: "application/cbor", want: CBOR},
+		{name: "unknown", contentType: "text/plain", want: nil},
+		{name: "invalid", contentType: "/", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ForContentType(tt.contentType, codecs); got != tt.want {
+				t.Errorf("ForContentType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
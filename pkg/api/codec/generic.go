@@ -0,0 +1,133 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// the generic values are nil, bool, int64, uint64, float64, string, []interface{} and object
+
+// member is a key and value of an object
+type member struct {
+	key   string
+	value interface{}
+}
+
+// object keeps the members in the order of the struct fields
+type object []member
+
+// toGeneric returns the generic value of the json encoding of v
+func toGeneric(v interface{}) (interface{}, error) {
+	enc, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(enc))
+	dec.UseNumber()
+	return readGeneric(dec)
+}
+
+func readGeneric(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			obj := object{}
+			for dec.More() {
+				key, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				value, err := readGeneric(dec)
+				if err != nil {
+					return nil, err
+				}
+				obj = append(obj, member{key: key.(string), value: value})
+			}
+			_, err := dec.Token()
+			return obj, err
+		case '[':
+			arr := []interface{}{}
+			for dec.More() {
+				value, err := readGeneric(dec)
+				if err != nil {
+					return nil, err
+				}
+				arr = append(arr, value)
+			}
+			_, err := dec.Token()
+			return arr, err
+		}
+		return nil, fmt.Errorf("%w unexpected %v", ErrFormat, t)
+	case json.Number:
+		if i, err := strconv.ParseInt(t.String(), 10, 64); err == nil {
+			return i, nil
+		}
+		if u, err := strconv.ParseUint(t.String(), 10, 64); err == nil {
+			return u, nil
+		}
+		return t.Float64()
+	default:
+		return t, nil
+	}
+}
+
+// fromGeneric writes the generic value as json and decodes it into v, rejecting unknown fields
+func fromGeneric(g interface{}, v interface{}) error {
+	buf := &bytes.Buffer{}
+	if err := writeJSON(buf, g); err != nil {
+		return err
+	}
+	dec := json.NewDecoder(buf)
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
+
+func writeJSON(buf *bytes.Buffer, g interface{}) error {
+	switch t := g.(type) {
+	case object:
+		buf.WriteByte('{')
+		for i, m := range t {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			key, _ := json.Marshal(m.key)
+			buf.Write(key)
+			buf.WriteByte(':')
+			if err := writeJSON(buf, m.value); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+		return nil
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, v := range t {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeJSON(buf, v); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+	case float64:
+		if math.IsNaN(t) || math.IsInf(t, 0) {
+			return fmt.Errorf("%w number %v", ErrFormat, t)
+		}
+	}
+	enc, err := json.Marshal(g)
+	if err != nil {
+		return err
+	}
+	buf.Write(enc)
+	return nil
+}
@@ -0,0 +1,280 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// encodeMessagePack encodes the generic value in the smallest MessagePack formats
+func encodeMessagePack(g interface{}) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if err := writeMessagePack(buf, g); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeMessagePack(buf *bytes.Buffer, g interface{}) error {
+	switch t := g.(type) {
+	case nil:
+		buf.WriteByte(0xc0)
+	case bool:
+		if t {
+			buf.WriteByte(0xc3)
+		} else {
+			buf.WriteByte(0xc2)
+		}
+	case int64:
+		writeMessagePackInt(buf, t)
+	case uint64:
+		buf.WriteByte(0xcf)
+		writeUint(buf, t, 8)
+	case float64:
+		buf.WriteByte(0xcb)
+		writeUint(buf, math.Float64bits(t), 8)
+	case string:
+		n := len(t)
+		switch {
+		case n < 32:
+			buf.WriteByte(0xa0 | byte(n))
+		case n <= math.MaxUint8:
+			buf.WriteByte(0xd9)
+			buf.WriteByte(byte(n))
+		case n <= math.MaxUint16:
+			buf.WriteByte(0xda)
+			writeUint(buf, uint64(n), 2)
+		default:
+			buf.WriteByte(0xdb)
+			writeUint(buf, uint64(n), 4)
+		}
+		buf.WriteString(t)
+	case []interface{}:
+		writeMessagePackLength(buf, len(t), 0x90, 0xdc, 0xdd)
+		for _, v := range t {
+			if err := writeMessagePack(buf, v); err != nil {
+				return err
+			}
+		}
+	case object:
+		writeMessagePackLength(buf, len(t), 0x80, 0xde, 0xdf)
+		for _, m := range t {
+			if err := writeMessagePack(buf, m.key); err != nil {
+				return err
+			}
+			if err := writeMessagePack(buf, m.value); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("%w msgpack can not encode %T", ErrFormat, g)
+	}
+	return nil
+}
+
+func writeMessagePackInt(buf *bytes.Buffer, i int64) {
+	switch {
+	case i >= 0 && i <= 127:
+		buf.WriteByte(byte(i))
+	case i >= -32 && i < 0:
+		buf.WriteByte(byte(i))
+	case i >= 0 && i <= math.MaxUint8:
+		buf.WriteByte(0xcc)
+		buf.WriteByte(byte(i))
+	case i >= 0 && i <= math.MaxUint16:
+		buf.WriteByte(0xcd)
+		writeUint(buf, uint64(i), 2)
+	case i >= 0 && i <= math.MaxUint32:
+		buf.WriteByte(0xce)
+		writeUint(buf, uint64(i), 4)
+	case i >= 0:
+		buf.WriteByte(0xcf)
+		writeUint(buf, uint64(i), 8)
+	case i >= math.MinInt8:
+		buf.WriteByte(0xd0)
+		buf.WriteByte(byte(i))
+	case i >= math.MinInt16:
+		buf.WriteByte(0xd1)
+		writeUint(buf, uint64(i), 2)
+	case i >= math.MinInt32:
+		buf.WriteByte(0xd2)
+		writeUint(buf, uint64(i), 4)
+	default:
+		buf.WriteByte(0xd3)
+		writeUint(buf, uint64(i), 8)
+	}
+}
+
+func writeMessagePackLength(buf *bytes.Buffer, n int, fix, len16, len32 byte) {
+	switch {
+	case n < 16:
+		buf.WriteByte(fix | byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(len16)
+		writeUint(buf, uint64(n), 2)
+	default:
+		buf.WriteByte(len32)
+		writeUint(buf, uint64(n), 4)
+	}
+}
+
+// writeUint writes the low size bytes of v big endian
+func writeUint(buf *bytes.Buffer, v uint64, size int) {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	buf.Write(b[8-size:])
+}
+
+// decodeMessagePack decodes a single MessagePack value, extension types are not supported
+func decodeMessagePack(data []byte) (interface{}, error) {
+	r := &reader{data: data}
+	g, err := readMessagePack(r, 0)
+	if err != nil {
+		return nil, err
+	}
+	if r.pos != len(r.data) {
+		return nil, fmt.Errorf("%w msgpack has %d trailing bytes", ErrFormat, len(r.data)-r.pos)
+	}
+	return g, nil
+}
+
+func readMessagePack(r *reader, depth int) (interface{}, error) {
+	if depth > maxDepth {
+		return nil, fmt.Errorf("%w msgpack nested too deep", ErrFormat)
+	}
+	b, err := r.byte()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case b <= 0x7f:
+		return int64(b), nil
+	case b >= 0xe0:
+		return int64(int8(b)), nil
+	case b&0xf0 == 0x80:
+		return readMessagePackMap(r, int(b&0x0f), depth)
+	case b&0xf0 == 0x90:
+		return readMessagePackArray(r, int(b&0x0f), depth)
+	case b&0xe0 == 0xa0:
+		return r.string(int(b & 0x1f))
+	}
+
+	switch b {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xd9:
+		n, err := r.uint(1)
+		if err != nil {
+			return nil, err
+		}
+		return r.string(int(n))
+	case 0xc5, 0xda:
+		n, err := r.uint(2)
+		if err != nil {
+			return nil, err
+		}
+		return r.string(int(n))
+	case 0xc6, 0xdb:
+		n, err := r.uint(4)
+		if err != nil {
+			return nil, err
+		}
+		return r.string(int(n))
+	case 0xca:
+		v, err := r.uint(4)
+		return float64(math.Float32frombits(uint32(v))), err
+	case 0xcb:
+		v, err := r.uint(8)
+		return math.Float64frombits(v), err
+	case 0xcc, 0xcd, 0xce:
+		v, err := r.uint(1 << (b - 0xcc))
+		return int64(v), err
+	case 0xcf:
+		v, err := r.uint(8)
+		if v <= math.MaxInt64 {
+			return int64(v), err
+		}
+		return v, err
+	case 0xd0:
+		v, err := r.uint(1)
+		return int64(int8(v)), err
+	case 0xd1:
+		v, err := r.uint(2)
+		return int64(int16(v)), err
+	case 0xd2:
+		v, err := r.uint(4)
+		return int64(int32(v)), err
+	case 0xd3:
+		v, err := r.uint(8)
+		return int64(v), err
+	case 0xdc:
+		n, err := r.uint(2)
+		if err != nil {
+			return nil, err
+		}
+		return readMessagePackArray(r, int(n), depth)
+	case 0xdd:
+		n, err := r.uint(4)
+		if err != nil {
+			return nil, err
+		}
+		return readMessagePackArray(r, int(n), depth)
+	case 0xde:
+		n, err := r.uint(2)
+		if err != nil {
+			return nil, err
+		}
+		return readMessagePackMap(r, int(n), depth)
+	case 0xdf:
+		n, err := r.uint(4)
+		if err != nil {
+			return nil, err
+		}
+		return readMessagePackMap(r, int(n), depth)
+	}
+	return nil, fmt.Errorf("%w msgpack type 0x%02x is not supported", ErrFormat, b)
+}
+
+func readMessagePackArray(r *reader, n int, depth int) (interface{}, error) {
+	// every element is at least one byte
+	if n > r.remaining() {
+		return nil, fmt.Errorf("%w msgpack array length %d", ErrFormat, n)
+	}
+	arr := make([]interface{}, 0, n)
+	for i := 0; i < n; i++ {
+		v, err := readMessagePack(r, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, v)
+	}
+	return arr, nil
+}
+
+func readMessagePackMap(r *reader, n int, depth int) (interface{}, error) {
+	if n*2 > r.remaining() {
+		return nil, fmt.Errorf("%w msgpack map length %d", ErrFormat, n)
+	}
+	obj := make(object, 0, n)
+	for i := 0; i < n; i++ {
+		k, err := readMessagePack(r, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		key, ok := k.(string)
+		if ok == false {
+			return nil, fmt.Errorf("%w msgpack map key %v is not a string", ErrFormat, k)
+		}
+		v, err := readMessagePack(r, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		obj = append(obj, member{key: key, value: v})
+	}
+	return obj, nil
+}
@@ -0,0 +1,116 @@
+package codec
+
+import (
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type acceptRange struct {
+	mediaType string
+	q         float64
+}
+
+// Negotiate returns the codec the Accept header prefers, ties go to the order of the codecs.
+// A missing Accept header selects the first codec and nil is returned when nothing is acceptable.
+func Negotiate(accept string, codecs []*Codec) *Codec {
+	if len(codecs) == 0 {
+		return nil
+	}
+	if len(strings.TrimSpace(accept)) == 0 {
+		return codecs[0]
+	}
+	ranges := parseAccept(accept)
+
+	var best *Codec
+	bestQ := 0.0
+	for _, c := range codecs {
+		q := c.quality(ranges)
+		if q > bestQ {
+			best, bestQ = c, q
+		}
+	}
+	return best
+}
+
+// quality is the q value of the most specific range that matches the codec
+func (c *Codec) quality(ranges []acceptRange) float64 {
+	q, specificity := 0.0, -1
+	for _, r := range ranges {
+		s := -1
+		switch {
+		case r.mediaType == "*/*":
+			s = 0
+		case strings.HasSuffix(r.mediaType, "/*"):
+			prefix := strings.TrimSuffix(r.mediaType, "*")
+			if strings.HasPrefix(c.MediaType, prefix) {
+				s = 1
+			}
+			for _, a := range c.Aliases {
+				if strings.HasPrefix(a, prefix) {
+					s = 1
+				}
+			}
+		case c.Matches(r.mediaType):
+			s = 2
+		}
+		if s > specificity {
+			q, specificity = r.q, s
+		}
+	}
+	return q
+}
+
+func parseAccept(accept string) []acceptRange {
+	ranges := []acceptRange{}
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, has := params["q"]; has {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil || f < 0 || f > 1 {
+				continue
+			}
+			q = f
+		}
+		ranges = append(ranges, acceptRange{mediaType: mediaType, q: q})
+	}
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].q > ranges[j].q
+	})
+	return ranges
+}
+
+// ForContentType returns the codec for the Content-Type header, the +json media types select the json codec
+func ForContentType(contentType string, codecs []*Codec) *Codec {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil
+	}
+	for _, c := range codecs {
+		if c.Matches(mediaType) {
+			return c
+		}
+	}
+	if strings.HasSuffix(mediaType, "+json") {
+		for _, c := range codecs {
+			if c.Matches(JSON.MediaType) {
+				return c
+			}
+		}
+	}
+	return nil
+}
+
+// MediaTypes returns the media types of the codecs
+func MediaTypes(codecs []*Codec) []string {
+	types := make([]string, 0, len(codecs))
+	for _, c := range codecs {
+		types = append(types, c.MediaType)
+	}
+	return types
+}
@@ -0,0 +1,52 @@
+package codec
+
+import (
+	"fmt"
+	"io"
+)
+
+// reader reads the binary formats, every read is checked against the remaining data
+type reader struct {
+	data []byte
+	pos  int
+}
+
+func (r *reader) remaining() int {
+	return len(r.data) - r.pos
+}
+
+func (r *reader) byte() (byte, error) {
+	if r.remaining() < 1 {
+		return 0, fmt.Errorf("%w %s", ErrFormat, io.ErrUnexpectedEOF)
+	}
+	b := r.data[r.pos]
+	r.pos++
+	return b, nil
+}
+
+func (r *reader) bytes(n int) ([]byte, error) {
+	if n < 0 || n > r.remaining() {
+		return nil, fmt.Errorf("%w %s", ErrFormat, io.ErrUnexpectedEOF)
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b, nil
+}
+
+func (r *reader) string(n int) (string, error) {
+	b, err := r.bytes(n)
+	return string(b), err
+}
+
+// uint reads a big endian unsigned integer of size bytes
+func (r *reader) uint(size int) (uint64, error) {
+	b, err := r.bytes(size)
+	if err != nil {
+		return 0, err
+	}
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v, nil
+}
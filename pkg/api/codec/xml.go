@@ -0,0 +1,264 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	xmlRoot = "response"
+	xmlItem = "item"
+	// xmlType is the attribute that types the values that are not strings or objects
+	xmlType = "type"
+	xmlNil  = "nil"
+	// xmlEntry is the element of an object member whose key is not an xml name, the key is its attribute
+	xmlEntry = "entry"
+	xmlKey   = "key"
+)
+
+// encodeXML encodes the generic value under a response element, arrays are item elements
+func encodeXML(g interface{}) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(buf)
+	if err := writeXML(enc, xmlStart(xmlRoot), g); err != nil {
+		return nil, err
+	}
+	if err := enc.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// xmlStart returns the element of the name
+func xmlStart(name string) xml.StartElement {
+	return xml.StartElement{Name: xml.Name{Local: name}}
+}
+
+// memberStart returns the element of an object member, a key that is not an xml name is an entry element
+// with the key attribute
+func memberStart(key string) xml.StartElement {
+	if validXMLName(key) {
+		return xmlStart(key)
+	}
+	start := xmlStart(xmlEntry)
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: xmlKey}, Value: key})
+	return start
+}
+
+func writeXML(enc *xml.Encoder, start xml.StartElement, g interface{}) error {
+	text := ""
+	switch t := g.(type) {
+	case nil:
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: xmlNil}, Value: "true"})
+	case bool:
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: xmlType}, Value: "boolean"})
+		text = strconv.FormatBool(t)
+	case int64:
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: xmlType}, Value: "number"})
+		text = strconv.FormatInt(t, 10)
+	case uint64:
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: xmlType}, Value: "number"})
+		text = strconv.FormatUint(t, 10)
+	case float64:
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: xmlType}, Value: "number"})
+		text = strconv.FormatFloat(t, 'g', -1, 64)
+	case string:
+		text = t
+	case []interface{}:
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: xmlType}, Value: "array"})
+		if err := enc.EncodeToken(start); err != nil {
+			return err
+		}
+		for _, v := range t {
+			if err := writeXML(enc, xmlStart(xmlItem), v); err != nil {
+				return err
+			}
+		}
+		return enc.EncodeToken(start.End())
+	case object:
+		if err := enc.EncodeToken(start); err != nil {
+			return err
+		}
+		for _, m := range t {
+			if err := writeXML(enc, memberStart(m.key), m.value); err != nil {
+				return err
+			}
+		}
+		return enc.EncodeToken(start.End())
+	default:
+		return fmt.Errorf("%w xml can not encode %T", ErrFormat, g)
+	}
+
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	if len(text) > 0 {
+		if err := enc.EncodeToken(xml.CharData(text)); err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
+
+// validXMLName allows the json field names that are also xml element names
+func validXMLName(name string) bool {
+	if len(name) == 0 || strings.HasPrefix(strings.ToLower(name), "xml") {
+		return false
+	}
+	for i, c := range name {
+		switch {
+		case c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+		case i > 0 && (c == '-' || c == '.' || (c >= '0' && c <= '9')):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// decodeXML decodes the document element, elements without a type are objects when they have children and strings otherwise
+func decodeXML(data []byte) (interface{}, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.Strict = true
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, xmlError(err)
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			g, err := readXML(dec, start, 0)
+			if err != nil {
+				return nil, err
+			}
+			if err := xmlEnd(dec); err != nil {
+				return nil, err
+			}
+			return g, nil
+		}
+	}
+}
+
+// xmlEnd allows only comments, processing instructions and white space after the document element
+func xmlEnd(dec *xml.Decoder) error {
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return xmlError(err)
+		}
+		switch t := tok.(type) {
+		case xml.Comment, xml.ProcInst:
+		case xml.CharData:
+			if len(bytes.TrimSpace(t)) > 0 {
+				return fmt.Errorf("%w xml has trailing data", ErrFormat)
+			}
+		default:
+			return fmt.Errorf("%w xml has trailing data", ErrFormat)
+		}
+	}
+}
+
+func readXML(dec *xml.Decoder, start xml.StartElement, depth int) (interface{}, error) {
+	if depth > maxDepth {
+		return nil, fmt.Errorf("%w xml nested too deep", ErrFormat)
+	}
+	typ, isNil := "", false
+	for _, a := range start.Attr {
+		switch a.Name.Local {
+		case xmlType:
+			typ = a.Value
+		case xmlNil:
+			isNil = a.Value == "true"
+		}
+	}
+
+	text := &strings.Builder{}
+	var arr []interface{}
+	var obj object
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, xmlError(err)
+		}
+		switch t := tok.(type) {
+		case xml.CharData:
+			text.Write(t)
+		case xml.StartElement:
+			v, err := readXML(dec, t, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			if typ == "array" {
+				arr = append(arr, v)
+			} else {
+				obj = append(obj, member{key: memberKey(t), value: v})
+			}
+		case xml.EndElement:
+			return xmlValue(start.Name.Local, typ, isNil, text.String(), arr, obj)
+		}
+	}
+}
+
+// memberKey returns the object key of a member element, the key attribute of an entry element
+func memberKey(start xml.StartElement) string {
+	if start.Name.Local == xmlEntry {
+		for _, a := range start.Attr {
+			if a.Name.Local == xmlKey {
+				return a.Value
+			}
+		}
+	}
+	return start.Name.Local
+}
+
+func xmlValue(name, typ string, isNil bool, text string, arr []interface{}, obj object) (interface{}, error) {
+	if isNil {
+		return nil, nil
+	}
+	switch typ {
+	case "array":
+		if arr == nil {
+			arr = []interface{}{}
+		}
+		return arr, nil
+	case "boolean":
+		b, err := strconv.ParseBool(strings.TrimSpace(text))
+		if err != nil {
+			return nil, fmt.Errorf("%w xml element %s is not a boolean", ErrFormat, name)
+		}
+		return b, nil
+	case "number":
+		n := strings.TrimSpace(text)
+		if i, err := strconv.ParseInt(n, 10, 64); err == nil {
+			return i, nil
+		}
+		if u, err := strconv.ParseUint(n, 10, 64); err == nil {
+			return u, nil
+		}
+		f, err := strconv.ParseFloat(n, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w xml element %s is not a number", ErrFormat, name)
+		}
+		return f, nil
+	case "":
+		if obj != nil {
+			return obj, nil
+		}
+		return text, nil
+	}
+	return nil, fmt.Errorf("%w xml element %s has the unknown type %q", ErrFormat, name, typ)
+}
+
+func xmlError(err error) error {
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return fmt.Errorf("%w %s", ErrFormat, err.Error())
+}
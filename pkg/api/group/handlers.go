@@ -0,0 +1,282 @@
+package group
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/g8rswimmer/go-data-access-example/pkg/api/request"
+	"github.com/g8rswimmer/go-data-access-example/pkg/api/response"
+	"github.com/g8rswimmer/go-data-access-example/pkg/errorx"
+	"github.com/g8rswimmer/go-data-access-example/pkg/logx"
+	"github.com/g8rswimmer/go-data-access-example/pkg/model"
+	"github.com/gorilla/mux"
+)
+
+// DAO is the group data access object
+type DAO interface {
+	Create(ctx context.Context, group *model.Group) (*model.GroupEntity, error)
+	FetchByID(ctx context.Context, id string) (*model.GroupEntity, error)
+	FetchAll(ctx context.Context) ([]*model.GroupEntity, error)
+	Update(ctx context.Context, id string, group *model.Group) (*model.GroupEntity, error)
+	Delete(ctx context.Context, id string) error
+	AddMember(ctx context.Context, groupID, userID string, role model.MemberRole) (*model.Member, error)
+	RemoveMember(ctx context.Context, groupID, userID string) error
+	Members(ctx context.Context, groupID string) ([]*model.Member, error)
+	UserGroups(ctx context.Context, userID string) ([]*model.GroupEntity, error)
+}
+
+const (
+	groupID = "id"
+	userID  = "user_id"
+)
+
+type errorMessage struct {
+	ID      string `json:"id,omitempty"`
+	Error   string `json:"error,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// memberRequest is the body that adds a member
+type memberRequest struct {
+	UserID string           `json:"user_id"`
+	Role   model.MemberRole `json:"role"`
+}
+
+// Handler provides all of the group handlers
+type Handler struct {
+	GroupDAO DAO
+}
+
+// decodeError responds to a body that could not be decoded
+func decodeError(w http.ResponseWriter, r *http.Request, err error) {
+	logx.FromContext(r.Context()).Debug("group decode error", logx.Fields{"error": err})
+	msg := &errorMessage{
+		Error:   err.Error(),
+		Message: "group decode error",
+	}
+	response.Write(w, r, request.Status(err), msg)
+}
+
+// invalid responds to a body that is not valid
+func invalid(w http.ResponseWriter, r *http.Request, err error) {
+	msg := &errorMessage{
+		Error:   err.Error(),
+		Message: "group validation error",
+	}
+	response.Write(w, r, http.StatusBadRequest, msg)
+}
+
+// datastoreError responds with the status of the error class, the group errors name the group
+func datastoreError(w http.ResponseWriter, r *http.Request, err error) {
+	id := mux.Vars(r)[groupID]
+	status := response.Status(err)
+	switch {
+	case errors.Is(err, errorx.ErrNoGroup):
+		response.Write(w, r, status, &errorMessage{ID: id, Message: fmt.Sprintf("group %s does not exist", id)})
+		return
+	case errors.Is(err, errorx.ErrDeleteGroup):
+		response.Write(w, r, http.StatusGone, &errorMessage{ID: id, Message: fmt.Sprintf("group %s has been deleted", id)})
+		return
+	case errors.Is(err, errorx.ErrNoUser), errors.Is(err, errorx.ErrDeleteUser):
+		// the user of a membership is part of the request and not the resource
+		response.Write(w, r, http.StatusUnprocessableEntity, &errorMessage{ID: id, Message: err.Error()})
+		return
+	case errors.Is(err, errorx.ErrGroupConflict), errors.Is(err, errorx.ErrMemberConflict), errors.Is(err, errorx.ErrNoMember):
+		response.Write(w, r, status, &errorMessage{ID: id, Message: err.Error()})
+		return
+	}
+
+	fields := logx.Fields{"error": err, "status": status}
+	if status >= http.StatusInternalServerError {
+		logx.FromContext(r.Context()).Error("group datastore error", fields)
+	} else {
+		logx.FromContext(r.Context()).Debug("group datastore error", fields)
+	}
+	if status == http.StatusServiceUnavailable {
+		w.Header().Set("Retry-After", "1")
+	}
+	msg := &errorMessage{
+		Error:   err.Error(),
+		Message: "group datastore error",
+	}
+	response.Write(w, r, status, msg)
+}
+
+// create handles the group create request
+func (h *Handler) create() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		group := &model.Group{}
+		if err := request.Decode(r, group); err != nil {
+			decodeError(w, r, err)
+			return
+		}
+		if len(group.Name) == 0 {
+			invalid(w, r, fmt.Errorf("%w: name is required", errorx.ErrInvalidGroup))
+			return
+		}
+		if err := group.Validate(); err != nil {
+			invalid(w, r, err)
+			return
+		}
+
+		entity, err := h.GroupDAO.Create(r.Context(), group)
+		if err != nil {
+			datastoreError(w, r, err)
+			return
+		}
+		response.Write(w, r, http.StatusCreated, entity)
+	}
+}
+
+// fetchByID will return a group by its id
+func (h *Handler) fetchByID() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		entity, err := h.GroupDAO.FetchByID(r.Context(), mux.Vars(r)[groupID])
+		if err != nil {
+			datastoreError(w, r, err)
+			return
+		}
+		response.Write(w, r, http.StatusOK, entity)
+	}
+}
+
+// list will return all of the groups
+func (h *Handler) list() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		entities, err := h.GroupDAO.FetchAll(r.Context())
+		if err != nil {
+			datastoreError(w, r, err)
+			return
+		}
+		response.Write(w, r, http.StatusOK, entities)
+	}
+}
+
+// update will change the group name or description
+func (h *Handler) update() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		group := &model.Group{}
+		if err := request.Decode(r, group); err != nil {
+			decodeError(w, r, err)
+			return
+		}
+		if len(group.Name) == 0 && len(group.Description) == 0 {
+			msg := &errorMessage{
+				Message: "group must have fields to update",
+			}
+			response.Write(w, r, http.StatusBadRequest, msg)
+			return
+		}
+		if err := group.Validate(); err != nil {
+			invalid(w, r, err)
+			return
+		}
+
+		entity, err := h.GroupDAO.Update(r.Context(), mux.Vars(r)[groupID], group)
+		if err != nil {
+			datastoreError(w, r, err)
+			return
+		}
+		response.Write(w, r, http.StatusOK, entity)
+	}
+}
+
+// delete will remove the group
+func (h *Handler) delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := h.GroupDAO.Delete(r.Context(), mux.Vars(r)[groupID]); err != nil {
+			datastoreError(w, r, err)
+			return
+		}
+		response.Write(w, r, http.StatusNoContent, nil)
+	}
+}
+
+// members will return the members of the group
+func (h *Handler) members() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		members, err := h.GroupDAO.Members(r.Context(), mux.Vars(r)[groupID])
+		if err != nil {
+			datastoreError(w, r, err)
+			return
+		}
+		response.Write(w, r, http.StatusOK, members)
+	}
+}
+
+// addMember will add an user to the group
+func (h *Handler) addMember() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body := &memberRequest{}
+		if err := request.Decode(r, body); err != nil {
+			decodeError(w, r, err)
+			return
+		}
+		switch {
+		case len(body.UserID) == 0:
+			invalid(w, r, fmt.Errorf("%w: user_id is required", errorx.ErrInvalidGroup))
+			return
+		case len(body.Role) > 0 && body.Role.Valid() == false:
+			invalid(w, r, fmt.Errorf("%w: role %q must be one of %v", errorx.ErrInvalidGroup, body.Role, model.MemberRoles))
+			return
+		}
+
+		member, err := h.GroupDAO.AddMember(r.Context(), mux.Vars(r)[groupID], body.UserID, body.Role)
+		if err != nil {
+			datastoreError(w, r, err)
+			return
+		}
+		response.Write(w, r, http.StatusCreated, member)
+	}
+}
+
+// removeMember will remove an user from the group
+func (h *Handler) removeMember() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		if err := h.GroupDAO.RemoveMember(r.Context(), vars[groupID], vars[userID]); err != nil {
+			datastoreError(w, r, err)
+			return
+		}
+		response.Write(w, r, http.StatusNoContent, nil)
+	}
+}
+
+// userGroups will return the groups of an user
+func (h *Handler) userGroups() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := mux.Vars(r)[userID]
+		groups, err := h.GroupDAO.UserGroups(r.Context(), id)
+		switch {
+		case errors.Is(err, errorx.ErrNoUser):
+			msg := &errorMessage{
+				Message: fmt.Sprintf("user %s does not exist", id),
+			}
+			response.Write(w, r, http.StatusNotFound, msg)
+		case errors.Is(err, errorx.ErrDeleteUser):
+			msg := &errorMessage{
+				Message: fmt.Sprintf("user %s has been deleted", id),
+			}
+			response.Write(w, r, http.StatusGone, msg)
+		case err != nil:
+			datastoreError(w, r, err)
+		default:
+			response.Write(w, r, http.StatusOK, groups)
+		}
+	}
+}
+
+// Add will configure the routes for group and membership operations
+func (h *Handler) Add(router *mux.Router) {
+	router.Methods(http.MethodPost).Path("/groups").Handler(h.create()).Name("group-create")
+	router.Methods(http.MethodGet).Path("/groups").Handler(h.list()).Name("group-fetch-all")
+	router.Methods(http.MethodGet).Path(fmt.Sprintf("/groups/{%s}", groupID)).Handler(h.fetchByID()).Name("group-fetch")
+	router.Methods(http.MethodPatch).Path(fmt.Sprintf("/groups/{%s}", groupID)).Handler(h.update()).Name("group-update")
+	router.Methods(http.MethodDelete).Path(fmt.Sprintf("/groups/{%s}", groupID)).Handler(h.delete()).Name("group-delete")
+	router.Methods(http.MethodGet).Path(fmt.Sprintf("/groups/{%s}/members", groupID)).Handler(h.members()).Name("group-members")
+	router.Methods(http.MethodPost).Path(fmt.Sprintf("/groups/{%s}/members", groupID)).Handler(h.addMember()).Name("group-member-add")
+	router.Methods(http.MethodDelete).Path(fmt.Sprintf("/groups/{%s}/members/{%s}", groupID, userID)).Handler(h.removeMember()).Name("group-member-remove")
+	router.Methods(http.MethodGet).Path(fmt.Sprintf("/users/{%s}/groups", userID)).Handler(h.userGroups()).Name("user-groups")
+}
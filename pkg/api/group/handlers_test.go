@@ -0,0 +1,253 @@
+package group
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/g8rswimmer/go-data-access-example/pkg/errorx"
+	"github.com/g8rswimmer/go-data-access-example/pkg/model"
+	"github.com/gorilla/mux"
+)
+
+func TestHandler(t *testing.T) {
+	type fields struct {
+		GroupDAO DAO
+	}
+	type args struct {
+		req *http.Request
+	}
+	created := time.Date(2020, time.July, 23, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		status int
+		body   interface{}
+	}{
+		{
+			name: "create",
+			fields: fields{
+				GroupDAO: &mockGroupDAO{
+					group: &model.GroupEntity{
+						Entity: model.Entity{ID: "1234", CreatedAt: created},
+						Group:  model.Group{Name: "Engineering"},
+					},
+				},
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodPost, "http://localhost:8080/groups", strings.NewReader(`{"name":"Engineering"}`)),
+			},
+			status: http.StatusCreated,
+			body: model.GroupEntity{
+				Entity: model.Entity{ID: "1234", CreatedAt: created},
+				Group:  model.Group{Name: "Engineering"},
+			},
+		},
+		{
+			name: "create without name",
+			fields: fields{
+				GroupDAO: &mockGroupDAO{},
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodPost, "http://localhost:8080/groups", strings.NewReader(`{"description":"builders"}`)),
+			},
+			status: http.StatusBadRequest,
+			body: errorMessage{
+				Error:   "group is not valid: name is required",
+				Message: "group validation error",
+			},
+		},
+		{
+			name: "create conflict",
+			fields: fields{
+				GroupDAO: &mockGroupDAO{err: errorx.ErrGroupConflict},
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodPost, "http://localhost:8080/groups", strings.NewReader(`{"name":"Engineering"}`)),
+			},
+			status: http.StatusConflict,
+			body: errorMessage{
+				Message: "group already exists",
+			},
+		},
+		{
+			name: "fetch missing",
+			fields: fields{
+				GroupDAO: &mockGroupDAO{err: errorx.ErrNoGroup},
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodGet, "http://localhost:8080/groups/1234", nil),
+			},
+			status: http.StatusNotFound,
+			body: errorMessage{
+				ID:      "1234",
+				Message: "group 1234 does not exist",
+			},
+		},
+		{
+			name: "fetch deleted",
+			fields: fields{
+				GroupDAO: &mockGroupDAO{err: errorx.ErrDeleteGroup},
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodGet, "http://localhost:8080/groups/1234", nil),
+			},
+			status: http.StatusGone,
+			body: errorMessage{
+				ID:      "1234",
+				Message: "group 1234 has been deleted",
+			},
+		},
+		{
+			name: "add member",
+			fields: fields{
+				GroupDAO: &mockGroupDAO{
+					member: &model.Member{GroupID: "1234", UserID: "5678", Role: model.MemberAdmin, CreatedAt: created},
+				},
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodPost, "http://localhost:8080/groups/1234/members", strings.NewReader(`{"user_id":"5678","role":"admin"}`)),
+			},
+			status: http.StatusCreated,
+			body:   model.Member{GroupID: "1234", UserID: "5678", Role: model.MemberAdmin, CreatedAt: created},
+		},
+		{
+			name: "add member role",
+			fields: fields{
+				GroupDAO: &mockGroupDAO{},
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodPost, "http://localhost:8080/groups/1234/members", strings.NewReader(`{"user_id":"5678","role":"root"}`)),
+			},
+			status: http.StatusBadRequest,
+			body: errorMessage{
+				Error:   `group is not valid: role "root" must be one of [owner admin member]`,
+				Message: "group validation error",
+			},
+		},
+		{
+			name: "add member missing user",
+			fields: fields{
+				GroupDAO: &mockGroupDAO{err: errorx.ErrNoUser},
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodPost, "http://localhost:8080/groups/1234/members", strings.NewReader(`{"user_id":"5678"}`)),
+			},
+			status: http.StatusUnprocessableEntity,
+			body: errorMessage{
+				ID:      "1234",
+				Message: "user is not present",
+			},
+		},
+		{
+			name: "add member again",
+			fields: fields{
+				GroupDAO: &mockGroupDAO{err: errorx.ErrMemberConflict},
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodPost, "http://localhost:8080/groups/1234/members", strings.NewReader(`{"user_id":"5678"}`)),
+			},
+			status: http.StatusConflict,
+			body: errorMessage{
+				ID:      "1234",
+				Message: "user is already a member of the group",
+			},
+		},
+		{
+			name: "remove missing member",
+			fields: fields{
+				GroupDAO: &mockGroupDAO{err: errorx.ErrNoMember},
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodDelete, "http://localhost:8080/groups/1234/members/5678", nil),
+			},
+			status: http.StatusNotFound,
+			body: errorMessage{
+				ID:      "1234",
+				Message: "user is not a member of the group",
+			},
+		},
+		{
+			name: "user groups deleted user",
+			fields: fields{
+				GroupDAO: &mockGroupDAO{err: errorx.ErrDeleteUser},
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodGet, "http://localhost:8080/users/5678/groups", nil),
+			},
+			status: http.StatusGone,
+			body: errorMessage{
+				Message: "user 5678 has been deleted",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Handler{
+				GroupDAO: tt.fields.GroupDAO,
+			}
+			router := mux.NewRouter()
+			h.Add(router)
+			writer := httptest.NewRecorder()
+			router.ServeHTTP(writer, tt.args.req)
+
+			if writer.Result().StatusCode != tt.status {
+				t.Errorf("Handler = %v, want %v", writer.Result().StatusCode, tt.status)
+				return
+			}
+
+			var bodyMap map[string]interface{}
+			if err := json.NewDecoder(writer.Body).Decode(&bodyMap); err != nil {
+				t.Errorf("Handler = json body decode error %v", err)
+				return
+			}
+
+			var wantBodyMap map[string]interface{}
+			if enc, err := json.Marshal(tt.body); err == nil {
+				_ = json.Unmarshal(enc, &wantBodyMap)
+			}
+
+			if !reflect.DeepEqual(bodyMap, wantBodyMap) {
+				t.Errorf("Handler = %v, want %v", bodyMap, wantBodyMap)
+			}
+		})
+	}
+}
+
+func TestHandler_Add(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		want   bool
+	}{
+		{name: "create", method: http.MethodPost, path: "/groups", want: true},
+		{name: "list", method: http.MethodGet, path: "/groups", want: true},
+		{name: "fetch", method: http.MethodGet, path: "/groups/1234", want: true},
+		{name: "update", method: http.MethodPatch, path: "/groups/1234", want: true},
+		{name: "delete", method: http.MethodDelete, path: "/groups/1234", want: true},
+		{name: "members", method: http.MethodGet, path: "/groups/1234/members", want: true},
+		{name: "add member", method: http.MethodPost, path: "/groups/1234/members", want: true},
+		{name: "remove member", method: http.MethodDelete, path: "/groups/1234/members/5678", want: true},
+		{name: "user groups", method: http.MethodGet, path: "/users/5678/groups", want: true},
+		{name: "nope", method: http.MethodPut, path: "/groups/1234/members", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Handler{}
+			r := mux.NewRouter()
+
+			h.Add(r)
+
+			var match mux.RouteMatch
+			if ok := r.Match(httptest.NewRequest(tt.method, "http://localhost:8080"+tt.path, nil), &match); ok != tt.want {
+				t.Errorf("Handler.Add() %v", tt.want)
+			}
+		})
+	}
+}
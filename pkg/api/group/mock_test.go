@@ -0,0 +1,51 @@
+package group
+
+import (
+	"context"
+
+	"github.com/g8rswimmer/go-data-access-example/pkg/model"
+)
+
+type mockGroupDAO struct {
+	group   *model.GroupEntity
+	groups  []*model.GroupEntity
+	member  *model.Member
+	members []*model.Member
+	err     error
+}
+
+func (m *mockGroupDAO) Create(ctx context.Context, group *model.Group) (*model.GroupEntity, error) {
+	return m.group, m.err
+}
+
+func (m *mockGroupDAO) FetchByID(ctx context.Context, id string) (*model.GroupEntity, error) {
+	return m.group, m.err
+}
+
+func (m *mockGroupDAO) FetchAll(ctx context.Context) ([]*model.GroupEntity, error) {
+	return m.groups, m.err
+}
+
+func (m *mockGroupDAO) Update(ctx context.Context, id string, group *model.Group) (*model.GroupEntity, error) {
+	return m.group, m.err
+}
+
+func (m *mockGroupDAO) Delete(ctx context.Context, id string) error {
+	return m.err
+}
+
+func (m *mockGroupDAO) AddMember(ctx context.Context, groupID, userID string, role model.MemberRole) (*model.Member, error) {
+	return m.member, m.err
+}
+
+func (m *mockGroupDAO) RemoveMember(ctx context.Context, groupID, userID string) error {
+	return m.err
+}
+
+func (m *mockGroupDAO) Members(ctx context.Context, groupID string) ([]*model.Member, error) {
+	return m.members, m.err
+}
+
+func (m *mockGroupDAO) UserGroups(ctx context.Context, userID string) ([]*model.GroupEntity, error) {
+	return m.groups, m.err
+}
@@ -0,0 +1,44 @@
+package identity
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+)
+
+// Client is the identity of a client that presented a verified certificate
+type Client struct {
+	CommonName   string   `json:"common_name"`
+	Organization []string `json:"organization,omitempty"`
+	DNSNames     []string `json:"dns_names,omitempty"`
+	EmailAddress []string `json:"email_address,omitempty"`
+	SerialNumber string   `json:"serial_number"`
+	Fingerprint  string   `json:"fingerprint"`
+}
+
+// FromCertificate returns the identity in the certificate
+func FromCertificate(cert *x509.Certificate) *Client {
+	sum := sha256.Sum256(cert.Raw)
+	return &Client{
+		CommonName:   cert.Subject.CommonName,
+		Organization: cert.Subject.Organization,
+		DNSNames:     cert.DNSNames,
+		EmailAddress: cert.EmailAddresses,
+		SerialNumber: cert.SerialNumber.String(),
+		Fingerprint:  hex.EncodeToString(sum[:]),
+	}
+}
+
+type contextKey struct{}
+
+// NewContext returns a context carrying the client identity
+func NewContext(ctx context.Context, c *Client) context.Context {
+	return context.WithValue(ctx, contextKey{}, c)
+}
+
+// FromContext returns the client identity, false when the client did not present a verified certificate
+func FromContext(ctx context.Context) (*Client, bool) {
+	c, ok := ctx.Value(contextKey{}).(*Client)
+	return c, ok && c != nil
+}
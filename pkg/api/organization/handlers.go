@@ -0,0 +1,280 @@
+package organization
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/g8rswimmer/go-data-access-example/pkg/api/request"
+	"github.com/g8rswimmer/go-data-access-example/pkg/api/response"
+	"github.com/g8rswimmer/go-data-access-example/pkg/errorx"
+	"github.com/g8rswimmer/go-data-access-example/pkg/logx"
+	"github.com/g8rswimmer/go-data-access-example/pkg/model"
+	"github.com/gorilla/mux"
+)
+
+// DAO is the organization data access object
+type DAO interface {
+	Create(ctx context.Context, org *model.Organization) (*model.OrganizationEntity, error)
+	FetchByID(ctx context.Context, id string) (*model.OrganizationEntity, error)
+	Roots(ctx context.Context) ([]*model.OrganizationNode, error)
+	Children(ctx context.Context, id string) ([]*model.OrganizationNode, error)
+	Ancestors(ctx context.Context, id string) ([]*model.OrganizationNode, error)
+	Descendants(ctx context.Context, id string) ([]*model.OrganizationNode, error)
+	Rename(ctx context.Context, id, name string) (*model.OrganizationEntity, error)
+	Move(ctx context.Context, id, parentID string) (*model.OrganizationEntity, error)
+	Delete(ctx context.Context, id string) error
+	PlaceUser(ctx context.Context, id, userID string) error
+	RemoveUser(ctx context.Context, id, userID string) error
+}
+
+const (
+	organizationID = "id"
+	userID         = "user_id"
+)
+
+type errorMessage struct {
+	ID      string `json:"id,omitempty"`
+	Error   string `json:"error,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// renameRequest is the body that renames an organization
+type renameRequest struct {
+	Name string `json:"name"`
+}
+
+// parentRequest is the body that moves an organization, an empty parent makes it a root
+type parentRequest struct {
+	ParentID string `json:"parent_id"`
+}
+
+// Handler provides all of the organization handlers
+type Handler struct {
+	OrganizationDAO DAO
+}
+
+// decodeError responds to a body that could not be decoded
+func decodeError(w http.ResponseWriter, r *http.Request, err error) {
+	logx.FromContext(r.Context()).Debug("organization decode error", logx.Fields{"error": err})
+	msg := &errorMessage{
+		Error:   err.Error(),
+		Message: "organization decode error",
+	}
+	response.Write(w, r, request.Status(err), msg)
+}
+
+// invalid responds to a body that is not valid
+func invalid(w http.ResponseWriter, r *http.Request, err error) {
+	msg := &errorMessage{
+		Error:   err.Error(),
+		Message: "organization validation error",
+	}
+	response.Write(w, r, http.StatusBadRequest, msg)
+}
+
+// datastoreError responds with the status of the error class, the organization errors name the organization
+func datastoreError(w http.ResponseWriter, r *http.Request, err error) {
+	id := mux.Vars(r)[organizationID]
+	status := response.Status(err)
+	switch {
+	case errors.Is(err, errorx.ErrNoOrganization):
+		response.Write(w, r, status, &errorMessage{ID: id, Message: fmt.Sprintf("organization %s does not exist", id)})
+		return
+	case errors.Is(err, errorx.ErrDeleteOrganization):
+		response.Write(w, r, http.StatusGone, &errorMessage{ID: id, Message: fmt.Sprintf("organization %s has been deleted", id)})
+		return
+	case errors.Is(err, errorx.ErrInvalidOrganization), errors.Is(err, errorx.ErrNoUser), errors.Is(err, errorx.ErrDeleteUser):
+		// the parent or the user is part of the request and not the resource
+		response.Write(w, r, http.StatusUnprocessableEntity, &errorMessage{ID: id, Message: err.Error()})
+		return
+	case errors.Is(err, errorx.ErrOrganizationCycle), errors.Is(err, errorx.ErrOrganizationChildren):
+		response.Write(w, r, status, &errorMessage{ID: id, Message: err.Error()})
+		return
+	}
+
+	fields := logx.Fields{"error": err, "status": status}
+	if status >= http.StatusInternalServerError {
+		logx.FromContext(r.Context()).Error("organization datastore error", fields)
+	} else {
+		logx.FromContext(r.Context()).Debug("organization datastore error", fields)
+	}
+	if status == http.StatusServiceUnavailable {
+		w.Header().Set("Retry-After", "1")
+	}
+	msg := &errorMessage{
+		Error:   err.Error(),
+		Message: "organization datastore error",
+	}
+	response.Write(w, r, status, msg)
+}
+
+// create handles the organization create request
+func (h *Handler) create() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		org := &model.Organization{}
+		if err := request.Decode(r, org); err != nil {
+			decodeError(w, r, err)
+			return
+		}
+		if err := org.Validate(); err != nil {
+			invalid(w, r, err)
+			return
+		}
+
+		entity, err := h.OrganizationDAO.Create(r.Context(), org)
+		if err != nil {
+			datastoreError(w, r, err)
+			return
+		}
+		response.Write(w, r, http.StatusCreated, entity)
+	}
+}
+
+// fetchByID will return an organization by its id
+func (h *Handler) fetchByID() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		entity, err := h.OrganizationDAO.FetchByID(r.Context(), mux.Vars(r)[organizationID])
+		if err != nil {
+			datastoreError(w, r, err)
+			return
+		}
+		response.Write(w, r, http.StatusOK, entity)
+	}
+}
+
+// nodes will return the organizations of the tree query
+func (h *Handler) nodes(query func(ctx context.Context, id string) ([]*model.OrganizationNode, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		nodes, err := query(r.Context(), mux.Vars(r)[organizationID])
+		if err != nil {
+			datastoreError(w, r, err)
+			return
+		}
+		response.Write(w, r, http.StatusOK, nodes)
+	}
+}
+
+func (h *Handler) children(ctx context.Context, id string) ([]*model.OrganizationNode, error) {
+	return h.OrganizationDAO.Children(ctx, id)
+}
+
+func (h *Handler) ancestors(ctx context.Context, id string) ([]*model.OrganizationNode, error) {
+	return h.OrganizationDAO.Ancestors(ctx, id)
+}
+
+func (h *Handler) descendants(ctx context.Context, id string) ([]*model.OrganizationNode, error) {
+	return h.OrganizationDAO.Descendants(ctx, id)
+}
+
+// roots will return the organizations at the top of the tree
+func (h *Handler) roots() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		nodes, err := h.OrganizationDAO.Roots(r.Context())
+		if err != nil {
+			datastoreError(w, r, err)
+			return
+		}
+		response.Write(w, r, http.StatusOK, nodes)
+	}
+}
+
+// rename will change the organization name
+func (h *Handler) rename() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body := &renameRequest{}
+		if err := request.Decode(r, body); err != nil {
+			decodeError(w, r, err)
+			return
+		}
+		if err := model.ValidateOrganizationName(body.Name); err != nil {
+			invalid(w, r, err)
+			return
+		}
+
+		entity, err := h.OrganizationDAO.Rename(r.Context(), mux.Vars(r)[organizationID], body.Name)
+		if err != nil {
+			datastoreError(w, r, err)
+			return
+		}
+		response.Write(w, r, http.StatusOK, entity)
+	}
+}
+
+// move will place the organization under a new parent
+func (h *Handler) move() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body := &parentRequest{}
+		if err := request.Decode(r, body); err != nil {
+			decodeError(w, r, err)
+			return
+		}
+
+		entity, err := h.OrganizationDAO.Move(r.Context(), mux.Vars(r)[organizationID], body.ParentID)
+		if err != nil {
+			datastoreError(w, r, err)
+			return
+		}
+		response.Write(w, r, http.StatusOK, entity)
+	}
+}
+
+// delete will remove the organization
+func (h *Handler) delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := h.OrganizationDAO.Delete(r.Context(), mux.Vars(r)[organizationID]); err != nil {
+			datastoreError(w, r, err)
+			return
+		}
+		response.Write(w, r, http.StatusNoContent, nil)
+	}
+}
+
+// placeUser will place an user in the organization
+func (h *Handler) placeUser() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		if err := h.OrganizationDAO.PlaceUser(r.Context(), vars[organizationID], vars[userID]); err != nil {
+			datastoreError(w, r, err)
+			return
+		}
+		response.Write(w, r, http.StatusNoContent, nil)
+	}
+}
+
+// removeUser will remove an user from the organization
+func (h *Handler) removeUser() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		err := h.OrganizationDAO.RemoveUser(r.Context(), vars[organizationID], vars[userID])
+		switch {
+		case errors.Is(err, errorx.ErrNoUser):
+			msg := &errorMessage{
+				ID:      vars[organizationID],
+				Message: fmt.Sprintf("user %s is not in the organization", vars[userID]),
+			}
+			response.Write(w, r, http.StatusNotFound, msg)
+		case err != nil:
+			datastoreError(w, r, err)
+		default:
+			response.Write(w, r, http.StatusNoContent, nil)
+		}
+	}
+}
+
+// Add will configure the routes for organization tree operations
+func (h *Handler) Add(router *mux.Router) {
+	org := fmt.Sprintf("/organizations/{%s}", organizationID)
+	router.Methods(http.MethodPost).Path("/organizations").Handler(h.create()).Name("organization-create")
+	router.Methods(http.MethodGet).Path("/organizations").Handler(h.roots()).Name("organization-roots")
+	router.Methods(http.MethodGet).Path(org).Handler(h.fetchByID()).Name("organization-fetch")
+	router.Methods(http.MethodPatch).Path(org).Handler(h.rename()).Name("organization-rename")
+	router.Methods(http.MethodDelete).Path(org).Handler(h.delete()).Name("organization-delete")
+	router.Methods(http.MethodGet).Path(org + "/children").Handler(h.nodes(h.children)).Name("organization-children")
+	router.Methods(http.MethodGet).Path(org + "/ancestors").Handler(h.nodes(h.ancestors)).Name("organization-ancestors")
+	router.Methods(http.MethodGet).Path(org + "/descendants").Handler(h.nodes(h.descendants)).Name("organization-descendants")
+	router.Methods(http.MethodPut).Path(org + "/parent").Handler(h.move()).Name("organization-move")
+	router.Methods(http.MethodPut).Path(fmt.Sprintf("%s/users/{%s}", org, userID)).Handler(h.placeUser()).Name("organization-user-place")
+	router.Methods(http.MethodDelete).Path(fmt.Sprintf("%s/users/{%s}", org, userID)).Handler(h.removeUser()).Name("organization-user-remove")
+}
@@ -0,0 +1,224 @@
+package organization
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/g8rswimmer/go-data-access-example/pkg/errorx"
+	"github.com/g8rswimmer/go-data-access-example/pkg/model"
+	"github.com/gorilla/mux"
+)
+
+func TestHandler(t *testing.T) {
+	type fields struct {
+		OrganizationDAO DAO
+	}
+	type args struct {
+		req *http.Request
+	}
+	created := time.Date(2020, time.July, 23, 0, 0, 0, 0, time.UTC)
+	team := model.OrganizationEntity{
+		Entity:       model.Entity{ID: "1234", CreatedAt: created},
+		Organization: model.Organization{Name: "platform", Kind: model.OrganizationTeam, ParentID: "5678"},
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		status int
+		body   interface{}
+	}{
+		{
+			name: "create",
+			fields: fields{
+				OrganizationDAO: &mockOrganizationDAO{org: &team},
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodPost, "http://localhost:8080/organizations", strings.NewReader(`{"name":"platform","kind":"team","parent_id":"5678"}`)),
+			},
+			status: http.StatusCreated,
+			body:   team,
+		},
+		{
+			name: "create kind",
+			fields: fields{
+				OrganizationDAO: &mockOrganizationDAO{},
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodPost, "http://localhost:8080/organizations", strings.NewReader(`{"name":"platform","kind":"squad"}`)),
+			},
+			status: http.StatusBadRequest,
+			body: errorMessage{
+				Error:   `organization is not valid: kind "squad" must be one of [company department team]`,
+				Message: "organization validation error",
+			},
+		},
+		{
+			name: "create placement",
+			fields: fields{
+				OrganizationDAO: &mockOrganizationDAO{err: model.OrganizationDepartment.CanParent(model.OrganizationTeam)},
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodPost, "http://localhost:8080/organizations", strings.NewReader(`{"name":"sales","kind":"department","parent_id":"1234"}`)),
+			},
+			status: http.StatusUnprocessableEntity,
+			body: errorMessage{
+				Message: "organization is not valid: a department can not be within a team",
+			},
+		},
+		{
+			name: "descendants",
+			fields: fields{
+				OrganizationDAO: &mockOrganizationDAO{
+					nodes: []*model.OrganizationNode{{OrganizationEntity: team, Depth: 0, UserCount: 3}},
+				},
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodGet, "http://localhost:8080/organizations/1234/descendants", nil),
+			},
+			status: http.StatusOK,
+			body:   []*model.OrganizationNode{{OrganizationEntity: team, Depth: 0, UserCount: 3}},
+		},
+		{
+			name: "ancestors missing",
+			fields: fields{
+				OrganizationDAO: &mockOrganizationDAO{err: errorx.ErrNoOrganization},
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodGet, "http://localhost:8080/organizations/1234/ancestors", nil),
+			},
+			status: http.StatusNotFound,
+			body: errorMessage{
+				ID:      "1234",
+				Message: "organization 1234 does not exist",
+			},
+		},
+		{
+			name: "move cycle",
+			fields: fields{
+				OrganizationDAO: &mockOrganizationDAO{err: errorx.ErrOrganizationCycle},
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodPut, "http://localhost:8080/organizations/5678/parent", strings.NewReader(`{"parent_id":"1234"}`)),
+			},
+			status: http.StatusUnprocessableEntity,
+			body: errorMessage{
+				ID:      "5678",
+				Message: "organization can not be moved within its own subtree",
+			},
+		},
+		{
+			name: "rename without name",
+			fields: fields{
+				OrganizationDAO: &mockOrganizationDAO{},
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodPatch, "http://localhost:8080/organizations/1234", strings.NewReader(`{}`)),
+			},
+			status: http.StatusBadRequest,
+			body: errorMessage{
+				Error:   "organization is not valid: name is required",
+				Message: "organization validation error",
+			},
+		},
+		{
+			name: "delete deleted",
+			fields: fields{
+				OrganizationDAO: &mockOrganizationDAO{err: errorx.ErrDeleteOrganization},
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodDelete, "http://localhost:8080/organizations/1234", nil),
+			},
+			status: http.StatusGone,
+			body: errorMessage{
+				ID:      "1234",
+				Message: "organization 1234 has been deleted",
+			},
+		},
+		{
+			name: "remove user missing",
+			fields: fields{
+				OrganizationDAO: &mockOrganizationDAO{err: errorx.ErrNoUser},
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodDelete, "http://localhost:8080/organizations/1234/users/5678", nil),
+			},
+			status: http.StatusNotFound,
+			body: errorMessage{
+				ID:      "1234",
+				Message: "user 5678 is not in the organization",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Handler{
+				OrganizationDAO: tt.fields.OrganizationDAO,
+			}
+			router := mux.NewRouter()
+			h.Add(router)
+			writer := httptest.NewRecorder()
+			router.ServeHTTP(writer, tt.args.req)
+
+			if writer.Result().StatusCode != tt.status {
+				t.Errorf("Handler = %v, want %v", writer.Result().StatusCode, tt.status)
+				return
+			}
+
+			var body interface{}
+			if err := json.NewDecoder(writer.Body).Decode(&body); err != nil {
+				t.Errorf("Handler = json body decode error %v", err)
+				return
+			}
+
+			var wantBody interface{}
+			if enc, err := json.Marshal(tt.body); err == nil {
+				_ = json.Unmarshal(enc, &wantBody)
+			}
+
+			if !reflect.DeepEqual(body, wantBody) {
+				t.Errorf("Handler = %v, want %v", body, wantBody)
+			}
+		})
+	}
+}
+
+func TestHandler_Add(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		want   bool
+	}{
+		{name: "create", method: http.MethodPost, path: "/organizations", want: true},
+		{name: "roots", method: http.MethodGet, path: "/organizations", want: true},
+		{name: "fetch", method: http.MethodGet, path: "/organizations/1234", want: true},
+		{name: "rename", method: http.MethodPatch, path: "/organizations/1234", want: true},
+		{name: "delete", method: http.MethodDelete, path: "/organizations/1234", want: true},
+		{name: "children", method: http.MethodGet, path: "/organizations/1234/children", want: true},
+		{name: "ancestors", method: http.MethodGet, path: "/organizations/1234/ancestors", want: true},
+		{name: "descendants", method: http.MethodGet, path: "/organizations/1234/descendants", want: true},
+		{name: "move", method: http.MethodPut, path: "/organizations/1234/parent", want: true},
+		{name: "place user", method: http.MethodPut, path: "/organizations/1234/users/5678", want: true},
+		{name: "remove user", method: http.MethodDelete, path: "/organizations/1234/users/5678", want: true},
+		{name: "nope", method: http.MethodPost, path: "/organizations/1234/parent", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Handler{}
+			r := mux.NewRouter()
+
+			h.Add(r)
+
+			var match mux.RouteMatch
+			if ok := r.Match(httptest.NewRequest(tt.method, "http://localhost:8080"+tt.path, nil), &match); ok != tt.want {
+				t.Errorf("Handler.Add() %v", tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package organization
+
+import (
+	"context"
+
+	"github.com/g8rswimmer/go-data-access-example/pkg/model"
+)
+
+type mockOrganizationDAO struct {
+	org   *model.OrganizationEntity
+	nodes []*model.OrganizationNode
+	err   error
+}
+
+func (m *mockOrganizationDAO) Create(ctx context.Context, org *model.Organization) (*model.OrganizationEntity, error) {
+	return m.org, m.err
+}
+
+func (m *mockOrganizationDAO) FetchByID(ctx context.Context, id string) (*model.OrganizationEntity, error) {
+	return m.org, m.err
+}
+
+func (m *mockOrganizationDAO) Roots(ctx context.Context) ([]*model.OrganizationNode, error) {
+	return m.nodes, m.err
+}
+
+func (m *mockOrganizationDAO) Children(ctx context.Context, id string) ([]*model.OrganizationNode, error) {
+	return m.nodes, m.err
+}
+
+func (m *mockOrganizationDAO) Ancestors(ctx context.Context, id string) ([]*model.OrganizationNode, error) {
+	return m.nodes, m.err
+}
+
+func (m *mockOrganizationDAO) Descendants(ctx context.Context, id string) ([]*model.OrganizationNode, error) {
+	return m.nodes, m.err
+}
+
+func (m *mockOrganizationDAO) Rename(ctx context.Context, id, name string) (*model.OrganizationEntity, error) {
+	return m.org, m.err
+}
+
+func (m *mockOrganizationDAO) Move(ctx context.Context, id, parentID string) (*model.OrganizationEntity, error) {
+	return m.org, m.err
+}
+
+func (m *mockOrganizationDAO) Delete(ctx context.Context, id string) error {
+	return m.err
+}
+
+func (m *mockOrganizationDAO) PlaceUser(ctx context.Context, id, userID string) error {
+	return m.err
+}
+
+func (m *mockOrganizationDAO) RemoveUser(ctx context.Context, id, userID string) error {
+	return m.err
+}
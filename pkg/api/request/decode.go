@@ -0,0 +1,44 @@
+package request
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/g8rswimmer/go-data-access-example/pkg/api/codec"
+	"github.com/g8rswimmer/go-data-access-example/pkg/errorx"
+)
+
+// Decode will decode the request body with the codec of its content type, json is decoded strictly
+func Decode(r *http.Request, v interface{}) error {
+	contentType := r.Header.Get("Content-Type")
+	if len(contentType) == 0 {
+		return JSON(r, v)
+	}
+
+	codecs := codec.Codecs()
+	c := codec.ForContentType(contentType, codecs)
+	switch {
+	case c == nil:
+		return fmt.Errorf("%w %q, expected one of %s", errorx.ErrUnsupportedMediaType, contentType, strings.Join(codec.MediaTypes(codecs), ", "))
+	case c == codec.JSON:
+		return JSON(r, v)
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return decodeError(err)
+	}
+	if len(body) == 0 {
+		return errors.New("request body is empty")
+	}
+	if err := c.Unmarshal(body, v); err != nil {
+		if errors.Is(err, codec.ErrFormat) {
+			return fmt.Errorf("malformed %s: %s", c.MediaType, err.Error())
+		}
+		return decodeError(err)
+	}
+	return nil
+}
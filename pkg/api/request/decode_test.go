@@ -0,0 +1,150 @@
+package request
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/g8rswimmer/go-data-access-example/pkg/errorx"
+)
+
+func TestDecode(t *testing.T) {
+	type args struct {
+		contentType string
+		body        string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    person
+		wantErr error
+		errMsg  string
+		status  int
+	}{
+		{
+			name: "json",
+			args: args{
+				contentType: "application/json",
+				body:        `{"first_name":"test","age":7}`,
+			},
+			want:   person{FirstName: "test", Age: 7},
+			status: http.StatusOK,
+		},
+		{
+			name: "msgpack",
+			args: args{
+				contentType: "application/msgpack",
+				body:        "\x82\xaafirst_name\xa4test\xa3age\x07",
+			},
+			want:   person{FirstName: "test", Age: 7},
+			status: http.StatusOK,
+		},
+		{
+			name: "cbor",
+			args: args{
+				contentType: "application/cbor",
+				body:        "\xa2\x6afirst_name\x64test\x63age\x07",
+			},
+			want:   person{FirstName: "test", Age: 7},
+			status: http.StatusOK,
+		},
+		{
+			name: "xml",
+			args: args{
+				contentType: "application/xml",
+				body:        `<response><first_name>test</first_name><age type="number">7</age></response>`,
+			},
+			want:   person{FirstName: "test", Age: 7},
+			status: http.StatusOK,
+		},
+		{
+			name: "unsupported",
+			args: args{
+				contentType: "text/plain",
+				body:        "test",
+			},
+			wantErr: errorx.ErrUnsupportedMediaType,
+			errMsg:  "application/cbor",
+			status:  http.StatusUnsupportedMediaType,
+		},
+		{
+			name: "unknown field",
+			args: args{
+				contentType: "application/cbor",
+				body:        "\xa1\x68nickname\x61t",
+			},
+			wantErr: errorx.ErrUnknownField,
+			errMsg:  `"nickname"`,
+			status:  http.StatusBadRequest,
+		},
+		{
+			name: "wrong type",
+			args: args{
+				contentType: "application/xml",
+				body:        `<response><age>seven</age></response>`,
+			},
+			errMsg: `field "age" must be int`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "malformed",
+			args: args{
+				contentType: "application/msgpack",
+				body:        "\x82\xaafirst_name",
+			},
+			errMsg: "malformed application/msgpack",
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "empty",
+			args: args{
+				contentType: "application/cbor",
+			},
+			errMsg: "request body is empty",
+			status: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "http://localhost:8080/v1/user", strings.NewReader(tt.args.body))
+			req.Header.Set("Content-Type", tt.args.contentType)
+
+			got := person{}
+			err := Decode(req, &got)
+			if tt.status == http.StatusOK {
+				if err != nil {
+					t.Errorf("Decode() error = %v", err)
+				}
+				if got != tt.want {
+					t.Errorf("Decode() = %v, want %v", got, tt.want)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Errorf("Decode() expected an error")
+				return
+			}
+			if tt.wantErr != nil && errors.Is(err, tt.wantErr) == false {
+				t.Errorf("Decode() error = %v, want %v", err, tt.wantErr)
+			}
+			if strings.Contains(err.Error(), tt.errMsg) == false {
+				t.Errorf("Decode() error = %v, want it to contain %v", err, tt.errMsg)
+			}
+			if Status(err) != tt.status {
+				t.Errorf("Status() = %v, want %v", Status(err), tt.status)
+			}
+		})
+	}
+}
+
+func TestDecode_TooLarge(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "http://localhost:8080/v1/user", &errReader{err: errorx.ErrBodyTooLarge})
+	req.Header.Set("Content-Type", "application/cbor")
+	err := Decode(req, &person{})
+	if Status(err) != http.StatusRequestEntityTooLarge {
+		t.Errorf("Status() = %v, want %v", Status(err), http.StatusRequestEntityTooLarge)
+	}
+}
@@ -0,0 +1,54 @@
+package request
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/g8rswimmer/go-data-access-example/pkg/errorx"
+)
+
+const fieldsParam = "fields"
+
+// Fields returns the fields named by the fields query parameter in the order of the allowed fields.
+// No fields are returned when the parameter is missing and an unknown field is an error.
+func Fields(r *http.Request, allowed []string) ([]string, error) {
+	values, has := r.URL.Query()[fieldsParam]
+	if has == false {
+		return nil, nil
+	}
+
+	requested := map[string]bool{}
+	for _, v := range values {
+		for _, f := range strings.Split(v, ",") {
+			f = strings.TrimSpace(f)
+			if len(f) == 0 {
+				continue
+			}
+			if indexOf(allowed, f) < 0 {
+				return nil, fmt.Errorf("%w %q, expected one of %s", errorx.ErrUnknownField, f, strings.Join(allowed, ", "))
+			}
+			requested[f] = true
+		}
+	}
+	if len(requested) == 0 {
+		return nil, fmt.Errorf("%w, the %s parameter is empty", errorx.ErrUnknownField, fieldsParam)
+	}
+
+	fields := make([]string, 0, len(requested))
+	for _, f := range allowed {
+		if requested[f] {
+			fields = append(fields, f)
+		}
+	}
+	return fields, nil
+}
+
+func indexOf(values []string, v string) int {
+	for i, s := range values {
+		if s == v {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,60 @@
+package request
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/g8rswimmer/go-data-access-example/pkg/errorx"
+)
+
+func TestFields(t *testing.T) {
+	allowed := []string{"id", "first_name", "last_name"}
+	tests := []struct {
+		name    string
+		query   string
+		want    []string
+		wantErr error
+	}{
+		{
+			name:  "missing",
+			query: "",
+			want:  nil,
+		},
+		{
+			name:  "allowed order",
+			query: "?fields=last_name,%20id",
+			want:  []string{"id", "last_name"},
+		},
+		{
+			name:  "repeated",
+			query: "?fields=id&fields=first_name,id",
+			want:  []string{"id", "first_name"},
+		},
+		{
+			name:    "unknown",
+			query:   "?fields=id,password",
+			wantErr: errorx.ErrUnknownField,
+		},
+		{
+			name:    "empty",
+			query:   "?fields=,",
+			wantErr: errorx.ErrUnknownField,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "http://localhost:8080/v1/users"+tt.query, nil)
+			got, err := Fields(req, allowed)
+			if errors.Is(err, tt.wantErr) == false {
+				t.Errorf("Fields() error = %v, want %v", err, tt.wantErr)
+				return
+			}
+			if reflect.DeepEqual(got, tt.want) == false {
+				t.Errorf("Fields() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package request
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/g8rswimmer/go-data-access-example/pkg/errorx"
+)
+
+const unknownFieldPrefix = "json: unknown field "
+
+// JSON will decode the request body into v, rejecting unknown fields, trailing data and bodies that are not json
+func JSON(r *http.Request, v interface{}) error {
+	if err := jsonContentType(r.Header.Get("Content-Type")); err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return decodeError(err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		if errors.Is(err, errorx.ErrBodyTooLarge) {
+			return err
+		}
+		return errorx.ErrTrailingData
+	}
+	return nil
+}
+
+// jsonContentType allows a missing content type, application/json and the +json media types
+func jsonContentType(contentType string) error {
+	if len(contentType) == 0 {
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || (mediaType != "application/json" && strings.HasSuffix(mediaType, "+json") == false) {
+		return fmt.Errorf("%w %q, expected application/json", errorx.ErrUnsupportedMediaType, contentType)
+	}
+	return nil
+}
+
+// decodeError names the field or the position of the problem
+func decodeError(err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.Is(err, errorx.ErrBodyTooLarge):
+		return err
+	case err == io.EOF:
+		return errors.New("request body is empty")
+	case err == io.ErrUnexpectedEOF:
+		return errors.New("request body ends before the json value is complete")
+	case errors.As(err, &syntaxErr):
+		return fmt.Errorf("malformed json at offset %d: %s", syntaxErr.Offset, syntaxErr.Error())
+	case errors.As(err, &typeErr) && len(typeErr.Field) > 0:
+		return fmt.Errorf("field %q must be %s not %s", typeErr.Field, typeErr.Type.String(), typeErr.Value)
+	case strings.HasPrefix(err.Error(), unknownFieldPrefix):
+		return fmt.Errorf("%w %s", errorx.ErrUnknownField, strings.TrimPrefix(err.Error(), unknownFieldPrefix))
+	default:
+		return err
+	}
+}
+
+// Status returns the response status for a request body that could not be decoded
+func Status(err error) int {
+	switch {
+	case errors.Is(err, errorx.ErrBodyTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, errorx.ErrUnsupportedMediaType):
+		return http.StatusUnsupportedMediaType
+	default:
+		return http.StatusBadRequest
+	}
+}
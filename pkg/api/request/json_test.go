@@ -0,0 +1,161 @@
+package request
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/g8rswimmer/go-data-access-example/pkg/errorx"
+)
+
+type person struct {
+	FirstName string `json:"first_name"`
+	Age       int    `json:"age"`
+}
+
+type errReader struct {
+	err error
+}
+
+func (r *errReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+func TestJSON(t *testing.T) {
+	type args struct {
+		contentType string
+		body        string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    person
+		wantErr error
+		errMsg  string
+		status  int
+	}{
+		{
+			name: "decoded",
+			args: args{
+				contentType: "application/json; charset=utf-8",
+				body:        `{"first_name":"test","age":7}` + "\n",
+			},
+			want:   person{FirstName: "test", Age: 7},
+			status: http.StatusOK,
+		},
+		{
+			name: "no content type",
+			args: args{
+				body: `{"first_name":"test"}`,
+			},
+			want:   person{FirstName: "test"},
+			status: http.StatusOK,
+		},
+		{
+			name: "json suffix",
+			args: args{
+				contentType: "application/merge-patch+json",
+				body:        `{"first_name":"test"}`,
+			},
+			want:   person{FirstName: "test"},
+			status: http.StatusOK,
+		},
+		{
+			name: "not json",
+			args: args{
+				contentType: "text/plain",
+				body:        `{"first_name":"test"}`,
+			},
+			wantErr: errorx.ErrUnsupportedMediaType,
+			status:  http.StatusUnsupportedMediaType,
+		},
+		{
+			name: "unknown field",
+			args: args{
+				body: `{"first_name":"test","nickname":"t"}`,
+			},
+			wantErr: errorx.ErrUnknownField,
+			errMsg:  `"nickname"`,
+			status:  http.StatusBadRequest,
+		},
+		{
+			name: "trailing data",
+			args: args{
+				body: `{"first_name":"test"} {"first_name":"again"}`,
+			},
+			wantErr: errorx.ErrTrailingData,
+			status:  http.StatusBadRequest,
+		},
+		{
+			name: "trailing garbage",
+			args: args{
+				body: `{"first_name":"test"}garbage`,
+			},
+			wantErr: errorx.ErrTrailingData,
+			status:  http.StatusBadRequest,
+		},
+		{
+			name: "wrong type",
+			args: args{
+				body: `{"age":"seven"}`,
+			},
+			errMsg: `field "age" must be int`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "malformed",
+			args: args{
+				body: `{"first_name":`,
+			},
+			errMsg: "request body",
+			status: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "http://localhost:8080/v1/user", strings.NewReader(tt.args.body))
+			if len(tt.args.contentType) > 0 {
+				req.Header.Set("Content-Type", tt.args.contentType)
+			}
+
+			got := person{}
+			err := JSON(req, &got)
+			if tt.status == http.StatusOK {
+				if err != nil {
+					t.Errorf("JSON() error = %v", err)
+				}
+				if got != tt.want {
+					t.Errorf("JSON() = %v, want %v", got, tt.want)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Errorf("JSON() expected an error")
+				return
+			}
+			if tt.wantErr != nil && errors.Is(err, tt.wantErr) == false {
+				t.Errorf("JSON() error = %v, want %v", err, tt.wantErr)
+			}
+			if strings.Contains(err.Error(), tt.errMsg) == false {
+				t.Errorf("JSON() error = %v, want it to contain %v", err, tt.errMsg)
+			}
+			if Status(err) != tt.status {
+				t.Errorf("Status() = %v, want %v", Status(err), tt.status)
+			}
+		})
+	}
+}
+
+func TestJSON_TooLarge(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "http://localhost:8080/v1/user", &errReader{err: errorx.ErrBodyTooLarge})
+	err := JSON(req, &person{})
+	if errors.Is(err, errorx.ErrBodyTooLarge) == false {
+		t.Errorf("JSON() error = %v, want %v", err, errorx.ErrBodyTooLarge)
+	}
+	if Status(err) != http.StatusRequestEntityTooLarge {
+		t.Errorf("Status() = %v, want %v", Status(err), http.StatusRequestEntityTooLarge)
+	}
+}
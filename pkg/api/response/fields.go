@@ -0,0 +1,62 @@
+package response
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+)
+
+// sparse serializes only the fields of the value
+type sparse struct {
+	value  interface{}
+	fields []string
+}
+
+// Project returns the body with only the fields serialized, a slice projects each element.
+// The body is returned as is when there are no fields.
+func Project(body interface{}, fields []string) interface{} {
+	if len(fields) == 0 || body == nil {
+		return body
+	}
+	v := reflect.ValueOf(body)
+	if v.Kind() != reflect.Slice {
+		return &sparse{value: body, fields: fields}
+	}
+	projected := make([]interface{}, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		projected = append(projected, &sparse{value: v.Index(i).Interface(), fields: fields})
+	}
+	return projected
+}
+
+// MarshalJSON writes the fields in the order they are given
+func (s *sparse) MarshalJSON() ([]byte, error) {
+	enc, err := json.Marshal(s.value)
+	if err != nil {
+		return nil, err
+	}
+	members := map[string]json.RawMessage{}
+	if err := json.Unmarshal(enc, &members); err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteByte('{')
+	written := 0
+	for _, f := range s.fields {
+		value, has := members[f]
+		if has == false {
+			continue
+		}
+		if written > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(f)
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+		written++
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
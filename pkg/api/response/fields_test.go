@@ -0,0 +1,57 @@
+package response
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type projected struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+func TestProject(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   interface{}
+		fields []string
+		want   string
+	}{
+		{
+			name: "no fields",
+			body: &projected{ID: "1", Name: "test", Age: 7},
+			want: `{"id":"1","name":"test","age":7}`,
+		},
+		{
+			name:   "fields",
+			body:   &projected{ID: "1", Name: "test", Age: 7},
+			fields: []string{"age", "id"},
+			want:   `{"age":7,"id":"1"}`,
+		},
+		{
+			name:   "slice",
+			body:   []*projected{{ID: "1", Name: "a"}, {ID: "2", Name: "b"}},
+			fields: []string{"name"},
+			want:   `[{"name":"a"},{"name":"b"}]`,
+		},
+		{
+			name:   "empty slice",
+			body:   []*projected{},
+			fields: []string{"name"},
+			want:   `[]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enc, err := json.Marshal(Project(tt.body, tt.fields))
+			if err != nil {
+				t.Errorf("Project() error = %v", err)
+				return
+			}
+			if string(enc) != tt.want {
+				t.Errorf("Project() = %s, want %s", enc, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package response
+
+import "net/http"
+
+// securityHeaders are the defaults for an api that only returns data
+var securityHeaders = map[string]string{
+	"Strict-Transport-Security": "max-age=63072000; includeSubDomains",
+	"X-Content-Type-Options":    "nosniff",
+	"Content-Security-Policy":   "default-src 'none'; frame-ancestors 'none'",
+	"X-Frame-Options":           "DENY",
+	"Referrer-Policy":           "no-referrer",
+}
+
+// SecurityHeaders will set the standard security headers that have not already been set
+func SecurityHeaders(h http.Header) {
+	for k, v := range securityHeaders {
+		if len(h.Get(k)) == 0 {
+			h.Set(k, v)
+		}
+	}
+}
@@ -5,8 +5,9 @@ import (
 	"net/http"
 )
 
-// JSON will send a response with a json body
+// JSON will send a response with a json body and the security headers
 func JSON(w http.ResponseWriter, status int, body interface{}) {
+	SecurityHeaders(w.Header())
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 
@@ -55,6 +55,11 @@ func TestJSON(t *testing.T) {
 			if reflect.DeepEqual(body, tt.body) == false {
 				t.Errorf("JSON() got %v want %v", body, tt.body)
 			}
+			for k, v := range securityHeaders {
+				if got := writer.Result().Header.Get(k); got != v {
+					t.Errorf("JSON() header %s got %v want %v", k, got, v)
+				}
+			}
 		})
 	}
 }
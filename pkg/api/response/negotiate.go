@@ -0,0 +1,72 @@
+package response
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/g8rswimmer/go-data-access-example/pkg/api/codec"
+	"github.com/g8rswimmer/go-data-access-example/pkg/errorx"
+)
+
+type problem struct {
+	Error     string   `json:"error"`
+	Message   string   `json:"message"`
+	Available []string `json:"available,omitempty"`
+}
+
+// Write will send the body in the media type the request accepts, a 406 lists the available types
+func Write(w http.ResponseWriter, r *http.Request, status int, body interface{}) {
+	codecs := codec.Codecs()
+	w.Header().Add("Vary", "Accept")
+
+	c := codec.Negotiate(r.Header.Get("Accept"), codecs)
+	if c == nil {
+		notAcceptable(w, r, codecs)
+		return
+	}
+	if c == codec.JSON {
+		JSON(w, status, body)
+		return
+	}
+
+	var enc []byte
+	if body != nil {
+		var err error
+		if enc, err = c.Marshal(body); err != nil {
+			JSON(w, http.StatusInternalServerError, &problem{
+				Error:   err.Error(),
+				Message: fmt.Sprintf("response can not be encoded as %s", c.MediaType),
+			})
+			return
+		}
+	}
+
+	SecurityHeaders(w.Header())
+	w.Header().Set("Content-Type", c.MediaType)
+	w.WriteHeader(status)
+	if body == nil {
+		return
+	}
+	_, _ = w.Write(enc)
+}
+
+// Negotiate will answer 406 before the handler runs when no response media type is acceptable
+func Negotiate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		codecs := codec.Codecs()
+		if codec.Negotiate(r.Header.Get("Accept"), codecs) == nil {
+			w.Header().Add("Vary", "Accept")
+			notAcceptable(w, r, codecs)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func notAcceptable(w http.ResponseWriter, r *http.Request, codecs []*codec.Codec) {
+	JSON(w, http.StatusNotAcceptable, &problem{
+		Error:     fmt.Errorf("%w %q", errorx.ErrNotAcceptable, r.Header.Get("Accept")).Error(),
+		Message:   "no response media type is acceptable",
+		Available: codec.MediaTypes(codecs),
+	})
+}
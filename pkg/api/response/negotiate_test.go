@@ -0,0 +1,132 @@
+package response
+
+import (
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	type args struct {
+		accept string
+		status int
+		body   interface{}
+	}
+	tests := []struct {
+		name        string
+		args        args
+		status      int
+		contentType string
+		body        string
+	}{
+		{
+			name: "default json",
+			args: args{
+				status: http.StatusOK,
+				body:   map[string]int{"a": 1},
+			},
+			status:      http.StatusOK,
+			contentType: "application/json",
+			body:        `{"a":1}`,
+		},
+		{
+			name: "cbor",
+			args: args{
+				accept: "application/cbor",
+				status: http.StatusCreated,
+				body:   map[string]int{"a": 1},
+			},
+			status:      http.StatusCreated,
+			contentType: "application/cbor",
+			body:        hexString("a1616101"),
+		},
+		{
+			name: "msgpack",
+			args: args{
+				accept: "application/json;q=0.1, application/msgpack",
+				status: http.StatusOK,
+				body:   map[string]int{"a": 1},
+			},
+			status:      http.StatusOK,
+			contentType: "application/msgpack",
+			body:        hexString("81a16101"),
+		},
+		{
+			name: "xml",
+			args: args{
+				accept: "application/xml",
+				status: http.StatusOK,
+				body:   map[string]string{"a": "b"},
+			},
+			status:      http.StatusOK,
+			contentType: "application/xml",
+			body:        `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<response><a>b</a></response>`,
+		},
+		{
+			name: "no body",
+			args: args{
+				accept: "application/cbor",
+				status: http.StatusNoContent,
+			},
+			status:      http.StatusNoContent,
+			contentType: "application/cbor",
+		},
+		{
+			name: "not acceptable",
+			args: args{
+				accept: "text/html",
+				status: http.StatusOK,
+				body:   map[string]int{"a": 1},
+			},
+			status:      http.StatusNotAcceptable,
+			contentType: "application/json",
+			body:        `{"error":"not acceptable \"text/html\"","message":"no response media type is acceptable","available":["application/json","application/xml","application/msgpack","application/cbor"]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "http://localhost:8080/v1/user", nil)
+			if len(tt.args.accept) > 0 {
+				req.Header.Set("Accept", tt.args.accept)
+			}
+			writer := httptest.NewRecorder()
+
+			Write(writer, req, tt.args.status, tt.args.body)
+
+			if writer.Code != tt.status {
+				t.Errorf("Write() status = %v, want %v", writer.Code, tt.status)
+			}
+			if got := writer.Header().Get("Content-Type"); got != tt.contentType {
+				t.Errorf("Write() content type = %v, want %v", got, tt.contentType)
+			}
+			if got := writer.Header().Get("Vary"); got != "Accept" {
+				t.Errorf("Write() vary = %v, want Accept", got)
+			}
+			if got := writer.Body.String(); got != tt.body {
+				t.Errorf("Write() body = %q, want %q", got, tt.body)
+			}
+		})
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	handler := Negotiate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Negotiate() called the handler")
+	}))
+	req := httptest.NewRequest(http.MethodPost, "http://localhost:8080/v1/user", strings.NewReader(`{}`))
+	req.Header.Set("Accept", "image/png")
+	writer := httptest.NewRecorder()
+
+	handler.ServeHTTP(writer, req)
+
+	if writer.Code != http.StatusNotAcceptable {
+		t.Errorf("Negotiate() status = %v, want %v", writer.Code, http.StatusNotAcceptable)
+	}
+}
+
+func hexString(s string) string {
+	b, _ := hex.DecodeString(s)
+	return string(b)
+}
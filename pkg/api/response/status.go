@@ -0,0 +1,30 @@
+package response
+
+import (
+	"net/http"
+
+	"github.com/g8rswimmer/go-data-access-example/pkg/errorx"
+)
+
+// StatusClientClosedRequest is sent when the client canceled the request before the response
+const StatusClientClosedRequest = 499
+
+// Status returns the response status of the error class, an error without a class is a 500
+func Status(err error) int {
+	switch errorx.Class(err) {
+	case errorx.ErrNotFound:
+		return http.StatusNotFound
+	case errorx.ErrConflict:
+		return http.StatusConflict
+	case errorx.ErrConstraint:
+		return http.StatusUnprocessableEntity
+	case errorx.ErrTimeout:
+		return http.StatusGatewayTimeout
+	case errorx.ErrCanceled:
+		return StatusClientClosedRequest
+	case errorx.ErrUnavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,33 @@
+package response
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/g8rswimmer/go-data-access-example/pkg/errorx"
+)
+
+func TestStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "not found", err: errorx.ErrNoUser, want: http.StatusNotFound},
+		{name: "conflict", err: fmt.Errorf("insert %w", errorx.ErrUserConflict), want: http.StatusConflict},
+		{name: "constraint", err: errorx.Wrap(errorx.ErrConstraint, errors.New("NOT NULL constraint failed")), want: http.StatusUnprocessableEntity},
+		{name: "timeout", err: errorx.ErrTimeout, want: http.StatusGatewayTimeout},
+		{name: "canceled", err: errorx.ErrCanceled, want: StatusClientClosedRequest},
+		{name: "unavailable", err: errorx.ErrUnavailable, want: http.StatusServiceUnavailable},
+		{name: "unclassified", err: errors.New("syntax error"), want: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Status(tt.err); got != tt.want {
+				t.Errorf("Status() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,13 +2,18 @@ package user
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"time"
 
+	"github.com/g8rswimmer/go-data-access-example/pkg/api/request"
 	"github.com/g8rswimmer/go-data-access-example/pkg/api/response"
 	"github.com/g8rswimmer/go-data-access-example/pkg/errorx"
+	"github.com/g8rswimmer/go-data-access-example/pkg/logx"
 	"github.com/g8rswimmer/go-data-access-example/pkg/model"
 	"github.com/gorilla/mux"
 )
@@ -16,18 +21,71 @@ import (
 // DAO is the user data access object
 type DAO interface {
 	Create(ctx context.Context, user *model.User) (*model.UserEntity, error)
-	FetchByID(ctx context.Context, id string) (*model.UserEntity, error)
-	FetchAll(ctx context.Context) ([]*model.UserEntity, error)
+	FetchByID(ctx context.Context, id string, fields ...string) (*model.UserEntity, error)
+	Search(ctx context.Context, search *model.UserSearch, fields ...string) ([]*model.UserEntity, string, error)
 	Update(ctx context.Context, id string, user *model.User) (*model.UserEntity, error)
 	Delete(ctx context.Context, id string) error
+	RemoveManager(ctx context.Context, id string) error
+	Reports(ctx context.Context, id string, depth int) ([]*model.UserNode, error)
+	Chain(ctx context.Context, id string) ([]*model.UserNode, error)
+	Stats(ctx context.Context, query *model.UserStatsQuery) (*model.UserStats, error)
 }
 
-const userID = "id"
+const (
+	userID = "id"
+	// maxDepth is the deepest reporting line that can be requested
+	maxDepth = 64
+	// statsLastNames is the number of last names of the statistics when top is not given
+	statsLastNames = 10
+)
 
 type errorMessage struct {
 	ID      string `json:"id,omitempty"`
 	Error   string `json:"error,omitempty"`
 	Message string `json:"message,omitempty"`
+	// Position and Token point at the offending token of a filter
+	Position int    `json:"position,omitempty"`
+	Token    string `json:"token,omitempty"`
+}
+
+// fieldsError is the message for a fields parameter that is not valid
+func fieldsError(err error) *errorMessage {
+	return &errorMessage{
+		Error:   err.Error(),
+		Message: "user fields error",
+	}
+}
+
+// datastoreError responds with the status of the error class, only server errors are logged as errors
+func datastoreError(w http.ResponseWriter, r *http.Request, err error) {
+	status := response.Status(err)
+	fields := logx.Fields{"error": err, "status": status}
+	if status >= http.StatusInternalServerError {
+		logx.FromContext(r.Context()).Error("user datastore error", fields)
+	} else {
+		logx.FromContext(r.Context()).Debug("user datastore error", fields)
+	}
+	if status == http.StatusServiceUnavailable {
+		w.Header().Set("Retry-After", "1")
+	}
+	msg := &errorMessage{
+		Error:   err.Error(),
+		Message: "user datastore error",
+	}
+	response.Write(w, r, status, msg)
+}
+
+// managerError is the message for a manager that the user can not report to
+func managerError(err error) *errorMessage {
+	return &errorMessage{
+		Error:   err.Error(),
+		Message: "user manager is not valid",
+	}
+}
+
+// invalidManager returns true when the error is a manager the user can not report to
+func invalidManager(err error) bool {
+	return errors.Is(err, errorx.ErrNoManager) || errors.Is(err, errorx.ErrDeleteManager) || errors.Is(err, errorx.ErrManagerCycle)
 }
 
 // Handler provides all of the user handlers
@@ -39,25 +97,42 @@ type Handler struct {
 func (h *Handler) create() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		user := &model.User{}
-		if err := json.NewDecoder(r.Body).Decode(user); err != nil {
+		if err := request.Decode(r, user); err != nil {
+			logx.FromContext(r.Context()).Debug("user json decode error", logx.Fields{"error": err})
 			msg := &errorMessage{
 				Error:   err.Error(),
 				Message: "user json decode error",
 			}
-			response.JSON(w, http.StatusBadRequest, msg)
+			response.Write(w, r, request.Status(err), msg)
+			return
+		}
+		if err := user.Validate(); err != nil {
+			msg := &errorMessage{
+				Error:   err.Error(),
+				Message: "user validation error",
+			}
+			response.Write(w, r, http.StatusBadRequest, msg)
 			return
 		}
 
 		entity, err := h.UserDAO.Create(r.Context(), user)
-		if err != nil {
+		switch {
+		case invalidManager(err):
+			response.Write(w, r, http.StatusUnprocessableEntity, managerError(err))
+			return
+		case errors.Is(err, errorx.ErrUserConflict):
 			msg := &errorMessage{
 				Error:   err.Error(),
-				Message: "user datastore error",
+				Message: "user conflicts with an existing user",
 			}
-			response.JSON(w, http.StatusInternalServerError, msg)
+			response.Write(w, r, http.StatusConflict, msg)
 			return
+		case err != nil:
+			datastoreError(w, r, err)
+			return
+		default:
+			response.Write(w, r, http.StatusCreated, entity)
 		}
-		response.JSON(w, http.StatusCreated, entity)
 	}
 }
 
@@ -66,54 +141,133 @@ func (h *Handler) fetchByID() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		vars := mux.Vars(r)
 		id := vars[userID]
-		entity, err := h.UserDAO.FetchByID(r.Context(), id)
+		fields, err := request.Fields(r, model.UserFields)
+		if err != nil {
+			response.Write(w, r, http.StatusBadRequest, fieldsError(err))
+			return
+		}
+		entity, err := h.UserDAO.FetchByID(r.Context(), id, fields...)
 		switch {
 		case errors.Is(err, errorx.ErrNoUser):
 			msg := &errorMessage{
 				Message: fmt.Sprintf("user %s does not exist", id),
 			}
-			response.JSON(w, http.StatusNotFound, msg)
+			response.Write(w, r, http.StatusNotFound, msg)
 			return
 		case errors.Is(err, errorx.ErrDeleteUser):
 			msg := &errorMessage{
 				Message: fmt.Sprintf("user %s has been deleted", id),
 			}
-			response.JSON(w, http.StatusGone, msg)
+			response.Write(w, r, http.StatusGone, msg)
 			return
 		case err != nil:
-			msg := &errorMessage{
-				Error:   err.Error(),
-				Message: "user datastore error",
-			}
-			response.JSON(w, http.StatusInternalServerError, msg)
+			datastoreError(w, r, err)
 			return
 		default:
-			response.JSON(w, http.StatusOK, entity)
+			response.Write(w, r, http.StatusOK, response.Project(entity, fields))
 		}
 
 	}
 }
 
-// list will return all of the users
+// searchOf returns the user search of the query parameters
+func searchOf(r *http.Request) (*model.UserSearch, error) {
+	query := r.URL.Query()
+	search := &model.UserSearch{
+		Status:   model.UserStatus(query.Get("status")),
+		LastName: query.Get("last_name"),
+		Email:    query.Get("email"),
+		Username: query.Get("username"),
+		Text:     query.Get("q"),
+		Filter:   query.Get("filter"),
+		Cursor:   query.Get("cursor"),
+	}
+	if param := query.Get("limit"); len(param) > 0 {
+		limit, err := strconv.Atoi(param)
+		if err != nil || limit < 1 {
+			return nil, fmt.Errorf("%w: limit %q must be a number from 1 to %d", errorx.ErrInvalidSearch, param, model.MaxSearchLimit)
+		}
+		search.Limit = limit
+	}
+	times := []struct {
+		name string
+		t    *time.Time
+	}{
+		{name: "created_after", t: &search.CreatedAfter},
+		{name: "created_before", t: &search.CreatedBefore},
+	}
+	for _, t := range times {
+		param := query.Get(t.name)
+		if len(param) == 0 {
+			continue
+		}
+		parsed, err := parseTime(param)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s %q must be a RFC 3339 time or a date", errorx.ErrInvalidSearch, t.name, param)
+		}
+		*t.t = parsed
+	}
+	return search, search.Validate()
+}
+
+// parseTime parses a RFC 3339 time or a date, a date is midnight UTC
+func parseTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", s)
+}
+
+// searchError is the message for a search parameter, filter or cursor that is not valid
+func searchError(err error) *errorMessage {
+	msg := &errorMessage{
+		Error:   err.Error(),
+		Message: "user search error",
+	}
+	filterErr := &errorx.FilterError{}
+	if errors.As(err, &filterErr) {
+		msg.Position = filterErr.Position
+		msg.Token = filterErr.Token
+	}
+	return msg
+}
+
+// list will return a page of the users that match the search parameters, a Link header
+// has the next page when there is one
 func (h *Handler) list() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		entities, err := h.UserDAO.FetchAll(r.Context())
+		fields, err := request.Fields(r, model.UserFields)
+		if err != nil {
+			response.Write(w, r, http.StatusBadRequest, fieldsError(err))
+			return
+		}
+		search, err := searchOf(r)
+		if err != nil {
+			response.Write(w, r, http.StatusBadRequest, searchError(err))
+			return
+		}
+		entities, cursor, err := h.UserDAO.Search(r.Context(), search, fields...)
+		if len(cursor) > 0 {
+			query := r.URL.Query()
+			query.Set("cursor", cursor)
+			next := url.URL{Path: r.URL.Path, RawQuery: query.Encode()}
+			w.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"next\"", next.String()))
+		}
 		switch {
+		case errors.Is(err, errorx.ErrInvalidSearch):
+			response.Write(w, r, http.StatusBadRequest, searchError(err))
+			return
 		case errors.Is(err, errorx.ErrNoUser):
 			msg := &errorMessage{
 				Message: fmt.Sprintf("no users exist"),
 			}
-			response.JSON(w, http.StatusNotFound, msg)
+			response.Write(w, r, http.StatusNotFound, msg)
 			return
 		case err != nil:
-			msg := &errorMessage{
-				Error:   err.Error(),
-				Message: "user datastore error",
-			}
-			response.JSON(w, http.StatusInternalServerError, msg)
+			datastoreError(w, r, err)
 			return
 		default:
-			response.JSON(w, http.StatusOK, entities)
+			response.Write(w, r, http.StatusOK, response.Project(entities, fields))
 		}
 	}
 }
@@ -122,19 +276,28 @@ func (h *Handler) list() http.HandlerFunc {
 func (h *Handler) update() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		user := &model.User{}
-		if err := json.NewDecoder(r.Body).Decode(user); err != nil {
+		if err := request.Decode(r, user); err != nil {
+			logx.FromContext(r.Context()).Debug("user json decode error", logx.Fields{"error": err})
 			msg := &errorMessage{
 				Error:   err.Error(),
 				Message: "user json decode error",
 			}
-			response.JSON(w, http.StatusBadRequest, msg)
+			response.Write(w, r, request.Status(err), msg)
 			return
 		}
-		if len(user.FirstName) == 0 && len(user.LastName) == 0 {
+		if reflect.DeepEqual(user, &model.User{}) {
 			msg := &errorMessage{
 				Message: "user must have fields to update",
 			}
-			response.JSON(w, http.StatusBadRequest, msg)
+			response.Write(w, r, http.StatusBadRequest, msg)
+			return
+		}
+		if err := user.Validate(); err != nil {
+			msg := &errorMessage{
+				Error:   err.Error(),
+				Message: "user validation error",
+			}
+			response.Write(w, r, http.StatusBadRequest, msg)
 			return
 		}
 
@@ -142,27 +305,34 @@ func (h *Handler) update() http.HandlerFunc {
 		id := vars[userID]
 		entity, err := h.UserDAO.Update(r.Context(), id, user)
 		switch {
+		case invalidManager(err):
+			// a deleted manager is also a deleted user, but not the user that is updated
+			response.Write(w, r, http.StatusUnprocessableEntity, managerError(err))
+			return
 		case errors.Is(err, errorx.ErrNoUser):
 			msg := &errorMessage{
 				Message: fmt.Sprintf("user %s does not exist", id),
 			}
-			response.JSON(w, http.StatusNotFound, msg)
+			response.Write(w, r, http.StatusNotFound, msg)
 			return
 		case errors.Is(err, errorx.ErrDeleteUser):
 			msg := &errorMessage{
 				Message: fmt.Sprintf("user %s has been deleted", id),
 			}
-			response.JSON(w, http.StatusGone, msg)
+			response.Write(w, r, http.StatusGone, msg)
 			return
-		case err != nil:
+		case errors.Is(err, errorx.ErrUserConflict):
 			msg := &errorMessage{
 				Error:   err.Error(),
-				Message: "user datastore error",
+				Message: "user conflicts with an existing user",
 			}
-			response.JSON(w, http.StatusInternalServerError, msg)
+			response.Write(w, r, http.StatusConflict, msg)
+			return
+		case err != nil:
+			datastoreError(w, r, err)
 			return
 		default:
-			response.JSON(w, http.StatusOK, entity)
+			response.Write(w, r, http.StatusOK, entity)
 		}
 
 	}
@@ -179,33 +349,189 @@ func (h *Handler) delete() http.HandlerFunc {
 			msg := &errorMessage{
 				Message: fmt.Sprintf("user %s does not exist", id),
 			}
-			response.JSON(w, http.StatusNotFound, msg)
+			response.Write(w, r, http.StatusNotFound, msg)
 			return
 		case errors.Is(err, errorx.ErrDeleteUser):
 			msg := &errorMessage{
 				Message: fmt.Sprintf("user %s has been deleted", id),
 			}
-			response.JSON(w, http.StatusGone, msg)
+			response.Write(w, r, http.StatusGone, msg)
 			return
 		case err != nil:
+			datastoreError(w, r, err)
+			return
+		default:
+			response.Write(w, r, http.StatusNoContent, nil)
+		}
+	}
+
+}
+
+// removeManager will remove the manager the user reports to
+func (h *Handler) removeManager() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := mux.Vars(r)[userID]
+		err := h.UserDAO.RemoveManager(r.Context(), id)
+		switch {
+		case errors.Is(err, errorx.ErrNoUser):
 			msg := &errorMessage{
-				Error:   err.Error(),
-				Message: "user datastore error",
+				Message: fmt.Sprintf("user %s does not exist", id),
 			}
-			response.JSON(w, http.StatusInternalServerError, msg)
-			return
+			response.Write(w, r, http.StatusNotFound, msg)
+		case errors.Is(err, errorx.ErrDeleteUser):
+			msg := &errorMessage{
+				Message: fmt.Sprintf("user %s has been deleted", id),
+			}
+			response.Write(w, r, http.StatusGone, msg)
+		case err != nil:
+			datastoreError(w, r, err)
 		default:
-			response.JSON(w, http.StatusNoContent, nil)
+			response.Write(w, r, http.StatusNoContent, nil)
+		}
+	}
+}
+
+// reports will return the users that report to an user, the depth parameter limits the levels returned
+func (h *Handler) reports() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		depth := 0
+		if param := r.URL.Query().Get("depth"); len(param) > 0 {
+			d, err := strconv.Atoi(param)
+			if err != nil || d < 1 || d > maxDepth {
+				msg := &errorMessage{
+					Message: fmt.Sprintf("depth %q must be a number from 1 to %d", param, maxDepth),
+				}
+				response.Write(w, r, http.StatusBadRequest, msg)
+				return
+			}
+			depth = d
+		}
+		id := mux.Vars(r)[userID]
+		nodes, err := h.UserDAO.Reports(r.Context(), id, depth)
+		reportingLine(w, r, id, nodes, err)
+	}
+}
+
+// chain will return the managers of an user up to the top of the reporting line
+func (h *Handler) chain() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := mux.Vars(r)[userID]
+		nodes, err := h.UserDAO.Chain(r.Context(), id)
+		reportingLine(w, r, id, nodes, err)
+	}
+}
+
+// reportingLine responds with the users of a reporting line query
+func reportingLine(w http.ResponseWriter, r *http.Request, id string, nodes []*model.UserNode, err error) {
+	switch {
+	case errors.Is(err, errorx.ErrNoUser):
+		msg := &errorMessage{
+			Message: fmt.Sprintf("user %s does not exist", id),
 		}
+		response.Write(w, r, http.StatusNotFound, msg)
+	case errors.Is(err, errorx.ErrDeleteUser):
+		msg := &errorMessage{
+			Message: fmt.Sprintf("user %s has been deleted", id),
+		}
+		response.Write(w, r, http.StatusGone, msg)
+	case err != nil:
+		datastoreError(w, r, err)
+	default:
+		response.Write(w, r, http.StatusOK, nodes)
 	}
+}
 
+// statsQueryOf returns the statistics query of the query parameters, the range is the last 30 days,
+// 12 weeks or 12 months of the interval when it is not given
+func statsQueryOf(r *http.Request, now time.Time) (*model.UserStatsQuery, error) {
+	query := r.URL.Query()
+	q := &model.UserStatsQuery{
+		Interval:  model.UserStatsDay,
+		Location:  time.UTC,
+		LastNames: statsLastNames,
+	}
+	if param := query.Get("interval"); len(param) > 0 {
+		q.Interval = model.UserStatsInterval(param)
+	}
+	if param := query.Get("tz"); len(param) > 0 {
+		loc, err := time.LoadLocation(param)
+		if err != nil || param == "Local" {
+			return nil, fmt.Errorf("%w: tz %q is not an IANA time zone", errorx.ErrInvalidStats, param)
+		}
+		q.Location = loc
+	}
+	if param := query.Get("top"); len(param) > 0 {
+		top, err := strconv.Atoi(param)
+		if err != nil {
+			return nil, fmt.Errorf("%w: top %q must be a number from 0 to %d", errorx.ErrInvalidStats, param, model.MaxStatsLastNames)
+		}
+		q.LastNames = top
+	}
+
+	q.To = now
+	if param := query.Get("to"); len(param) > 0 {
+		to, err := parseTimeIn(param, q.Location)
+		if err != nil {
+			return nil, fmt.Errorf("%w: to %q must be a RFC 3339 time or a date", errorx.ErrInvalidStats, param)
+		}
+		q.To = to
+	}
+	switch q.Interval {
+	case model.UserStatsWeek:
+		q.From = q.To.AddDate(0, 0, -7*12)
+	case model.UserStatsMonth:
+		q.From = q.To.AddDate(0, -12, 0)
+	default:
+		q.From = q.To.AddDate(0, 0, -30)
+	}
+	if param := query.Get("from"); len(param) > 0 {
+		from, err := parseTimeIn(param, q.Location)
+		if err != nil {
+			return nil, fmt.Errorf("%w: from %q must be a RFC 3339 time or a date", errorx.ErrInvalidStats, param)
+		}
+		q.From = from
+	}
+	return q, q.Validate()
+}
+
+// parseTimeIn parses a RFC 3339 time or a date, a date is midnight in the location
+func parseTimeIn(s string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02", s, loc)
+}
+
+// stats will return the aggregate statistics of the users
+func (h *Handler) stats() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query, err := statsQueryOf(r, time.Now())
+		if err != nil {
+			msg := &errorMessage{
+				Error:   err.Error(),
+				Message: "user stats error",
+			}
+			response.Write(w, r, http.StatusBadRequest, msg)
+			return
+		}
+		stats, err := h.UserDAO.Stats(r.Context(), query)
+		if err != nil {
+			datastoreError(w, r, err)
+			return
+		}
+		response.Write(w, r, http.StatusOK, stats)
+	}
 }
 
 // Add will configure the routes for user operations
 func (h *Handler) Add(router *mux.Router) {
 	router.Methods(http.MethodPost).Path("/user").Handler(h.create()).Name("user-create")
+	router.Methods(http.MethodGet).Path("/users/stats").Handler(h.stats()).Name("user-stats")
 	router.Methods(http.MethodGet).Path(fmt.Sprintf("/users/{%s}", userID)).Handler(h.fetchByID()).Name("user-fetch")
 	router.Methods(http.MethodGet).Path("/users").Handler(h.list()).Name("user-fetch-all")
 	router.Methods(http.MethodPatch).Path(fmt.Sprintf("/users/{%s}", userID)).Handler(h.update()).Name("user-update")
 	router.Methods(http.MethodDelete).Path(fmt.Sprintf("/users/{%s}", userID)).Handler(h.delete()).Name("user-delete")
+	router.Methods(http.MethodDelete).Path(fmt.Sprintf("/users/{%s}/manager", userID)).Handler(h.removeManager()).Name("user-remove-manager")
+	router.Methods(http.MethodGet).Path(fmt.Sprintf("/users/{%s}/reports", userID)).Handler(h.reports()).Name("user-reports")
+	router.Methods(http.MethodGet).Path(fmt.Sprintf("/users/{%s}/chain", userID)).Handler(h.chain()).Name("user-chain")
 }
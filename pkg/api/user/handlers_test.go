@@ -2,14 +2,19 @@ package user
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"reflect"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/g8rswimmer/go-data-access-example/pkg/errorx"
 	"github.com/g8rswimmer/go-data-access-example/pkg/model"
 	"github.com/gorilla/mux"
 )
@@ -66,6 +71,129 @@ func TestHandler_Create(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "unknown field",
+			fields: fields{
+				UserDAO: &mockUserDAO{},
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodPost, "http://www.google.com", strings.NewReader(`{"first_name":"test","middle_name":"t"}`)),
+			},
+			status: http.StatusBadRequest,
+			body: errorMessage{
+				Error:   `unknown field "middle_name"`,
+				Message: "user json decode error",
+			},
+		},
+		{
+			name: "not json",
+			fields: fields{
+				UserDAO: &mockUserDAO{},
+			},
+			args: args{
+				req: func() *http.Request {
+					req := httptest.NewRequest(http.MethodPost, "http://www.google.com", strings.NewReader(`first_name=test`))
+					req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+					return req
+				}(),
+			},
+			status: http.StatusUnsupportedMediaType,
+			body: errorMessage{
+				Error:   `unsupported media type "application/x-www-form-urlencoded", expected one of application/json, application/xml, application/msgpack, application/cbor`,
+				Message: "user json decode error",
+			},
+		},
+		{
+			name: "msgpack body",
+			fields: fields{
+				UserDAO: &mockUserDAO{
+					user: &model.UserEntity{
+						Entity: model.Entity{
+							ID: "1234",
+						},
+					},
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					// {"first_name":"tom"}
+					req := httptest.NewRequest(http.MethodPost, "http://www.google.com", strings.NewReader("\x81\xaafirst_name\xa3tom"))
+					req.Header.Set("Content-Type", "application/msgpack")
+					return req
+				}(),
+			},
+			status: http.StatusCreated,
+			body: model.UserEntity{
+				Entity: model.Entity{
+					ID: "1234",
+				},
+			},
+		},
+		{
+			name: "not acceptable",
+			fields: fields{
+				UserDAO: &mockUserDAO{
+					user: &model.UserEntity{},
+				},
+			},
+			args: args{
+				req: func() *http.Request {
+					req := httptest.NewRequest(http.MethodPost, "http://www.google.com", strings.NewReader(`{"first_name":"test"}`))
+					req.Header.Set("Accept", "text/html")
+					return req
+				}(),
+			},
+			status: http.StatusNotAcceptable,
+			body: map[string]interface{}{
+				"error":     `not acceptable "text/html"`,
+				"message":   "no response media type is acceptable",
+				"available": []string{"application/json", "application/xml", "application/msgpack", "application/cbor"},
+			},
+		},
+		{
+			name: "invalid",
+			fields: fields{
+				UserDAO: &mockUserDAO{},
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodPost, "http://www.google.com", strings.NewReader(`{"first_name":"test","phone":"555-0123"}`)),
+			},
+			status: http.StatusBadRequest,
+			body: errorMessage{
+				Error:   `user is not valid: phone "555-0123" is not an E.164 number`,
+				Message: "user validation error",
+			},
+		},
+		{
+			name: "conflict",
+			fields: fields{
+				UserDAO: &mockUserDAO{
+					err: fmt.Errorf("user create insert %w with the same email", errorx.ErrUserConflict),
+				},
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodPost, "http://www.google.com", strings.NewReader(`{"first_name":"test","email":"test@example.com"}`)),
+			},
+			status: http.StatusConflict,
+			body: errorMessage{
+				Error:   "user create insert user already exists with the same email",
+				Message: "user conflicts with an existing user",
+			},
+		},
+		{
+			name: "body too large",
+			fields: fields{
+				UserDAO: &mockUserDAO{},
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodPost, "http://www.google.com", &errReader{err: errorx.ErrBodyTooLarge}),
+			},
+			status: http.StatusRequestEntityTooLarge,
+			body: errorMessage{
+				Error:   errorx.ErrBodyTooLarge.Error(),
+				Message: "user json decode error",
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -99,6 +227,36 @@ func TestHandler_Create(t *testing.T) {
 	}
 }
 
+func TestHandler_Create_XML(t *testing.T) {
+	h := &Handler{
+		UserDAO: &mockUserDAO{
+			user: &model.UserEntity{
+				Entity: model.Entity{
+					ID:        "1234",
+					CreatedAt: time.Date(2020, time.July, 23, 0, 0, 0, 0, time.UTC),
+				},
+				User: model.User{
+					FirstName:  "test",
+					LastName:   "testison",
+					Attributes: model.Attributes{"cost center": "x"},
+				},
+			},
+		},
+	}
+	req := httptest.NewRequest(http.MethodPost, "http://www.google.com", strings.NewReader(`{"first_name":"test","last_name":"testison","attributes":{"cost center":"x"}}`))
+	req.Header.Set("Accept", "application/xml")
+	writer := httptest.NewRecorder()
+	h.create().ServeHTTP(writer, req)
+
+	if writer.Result().StatusCode != http.StatusCreated {
+		t.Errorf("Handler.Create() = %v, want %v", writer.Result().StatusCode, http.StatusCreated)
+		return
+	}
+	if body := writer.Body.String(); strings.Contains(body, `<entry key="cost center">x</entry>`) == false {
+		t.Errorf("Handler.Create() = %s, want a cost center entry", body)
+	}
+}
+
 func TestHandler_FetchByID(t *testing.T) {
 	type fields struct {
 		UserDAO DAO
@@ -146,6 +304,75 @@ func TestHandler_FetchByID(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "fields",
+			fields: fields{
+				UserDAO: &mockUserDAO{
+					user: &model.UserEntity{
+						Entity: model.Entity{
+							ID: "1234",
+						},
+						User: model.User{
+							FirstName: "test",
+						},
+					},
+				},
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodGet, "http://www.google.com/1234?fields=first_name,id", nil),
+			},
+			status: http.StatusOK,
+			body: map[string]interface{}{
+				"id":         "1234",
+				"first_name": "test",
+			},
+		},
+		{
+			name: "unknown field",
+			fields: fields{
+				UserDAO: &mockUserDAO{},
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodGet, "http://www.google.com/1234?fields=id,password", nil),
+			},
+			status: http.StatusBadRequest,
+			body: errorMessage{
+				Error:   `unknown field "password", expected one of id, first_name, last_name, email, username, phone, status, locale, timezone, attributes, manager_id, created_at, updated_at, deleted_at`,
+				Message: "user fields error",
+			},
+		},
+		{
+			name: "timeout",
+			fields: fields{
+				UserDAO: &mockUserDAO{
+					err: fmt.Errorf("user fetch query %w", errorx.Wrap(errorx.ErrTimeout, context.DeadlineExceeded)),
+				},
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodGet, "http://www.google.com/1234", nil),
+			},
+			status: http.StatusGatewayTimeout,
+			body: errorMessage{
+				Error:   "user fetch query context deadline exceeded",
+				Message: "user datastore error",
+			},
+		},
+		{
+			name: "unavailable",
+			fields: fields{
+				UserDAO: &mockUserDAO{
+					err: errorx.Wrap(errorx.ErrUnavailable, errors.New("database is locked")),
+				},
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodGet, "http://www.google.com/1234", nil),
+			},
+			status: http.StatusServiceUnavailable,
+			body: errorMessage{
+				Error:   "database is locked",
+				Message: "user datastore error",
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -283,6 +510,116 @@ func TestHandler_List(t *testing.T) {
 	}
 }
 
+func TestHandler_Search(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		dao        *mockUserDAO
+		status     int
+		wantSearch *model.UserSearch
+		wantLink   string
+		wantBody   map[string]interface{}
+	}{
+		{
+			name:   "parameters",
+			target: "/v1/users?status=inactive&last_name=smith&q=ann&created_after=2026-01-01&created_before=2026-02-01T00:00:00Z&limit=2",
+			dao:    &mockUserDAO{users: []*model.UserEntity{}},
+			status: http.StatusOK,
+			wantSearch: &model.UserSearch{
+				Status:        model.UserInactive,
+				LastName:      "smith",
+				Text:          "ann",
+				CreatedAfter:  time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC),
+				CreatedBefore: time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC),
+				Limit:         2,
+			},
+		},
+		{
+			name:       "next page",
+			target:     "/v1/users?limit=1&cursor=abc",
+			dao:        &mockUserDAO{users: []*model.UserEntity{}, cursor: "def"},
+			status:     http.StatusOK,
+			wantSearch: &model.UserSearch{Limit: 1, Cursor: "abc"},
+			wantLink:   `</v1/users?cursor=def&limit=1>; rel="next"`,
+		},
+		{
+			name:   "limit",
+			target: "/v1/users?limit=0",
+			dao:    &mockUserDAO{},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "limit large",
+			target: "/v1/users?limit=1001",
+			dao:    &mockUserDAO{},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "status",
+			target: "/v1/users?status=deleted",
+			dao:    &mockUserDAO{},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "created after",
+			target: "/v1/users?created_after=yesterday",
+			dao:    &mockUserDAO{},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:       "filter",
+			target:     "/v1/users?filter=" + url.QueryEscape(`last_name eq "Smith" and created_at gt 2026-01-01`),
+			dao:        &mockUserDAO{users: []*model.UserEntity{}},
+			status:     http.StatusOK,
+			wantSearch: &model.UserSearch{Filter: `last_name eq "Smith" and created_at gt 2026-01-01`},
+		},
+		{
+			name:   "filter error",
+			target: "/v1/users?filter=password",
+			dao:    &mockUserDAO{err: &errorx.FilterError{Position: 1, Token: "password", Message: "unknown field"}},
+			status: http.StatusBadRequest,
+			wantBody: map[string]interface{}{
+				"error":    `filter position 1 "password": unknown field`,
+				"message":  "user search error",
+				"position": float64(1),
+				"token":    "password",
+			},
+		},
+		{
+			name:   "cursor",
+			target: "/v1/users?cursor=abc",
+			dao:    &mockUserDAO{err: fmt.Errorf("%w: cursor is not valid", errorx.ErrInvalidSearch)},
+			status: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Handler{
+				UserDAO: tt.dao,
+			}
+			writer := httptest.NewRecorder()
+			h.list().ServeHTTP(writer, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			if writer.Result().StatusCode != tt.status {
+				t.Errorf("Handler.List() = %v, want %v", writer.Result().StatusCode, tt.status)
+				return
+			}
+			if tt.wantSearch != nil && reflect.DeepEqual(tt.dao.search, tt.wantSearch) == false {
+				t.Errorf("Handler.List() search = %+v, want %+v", tt.dao.search, tt.wantSearch)
+			}
+			if got := writer.Header().Get("Link"); got != tt.wantLink {
+				t.Errorf("Handler.List() Link = %q, want %q", got, tt.wantLink)
+			}
+			if tt.wantBody != nil {
+				body := map[string]interface{}{}
+				if err := json.NewDecoder(writer.Body).Decode(&body); err != nil || reflect.DeepEqual(body, tt.wantBody) == false {
+					t.Errorf("Handler.List() body = %v, %v want %v", body, err, tt.wantBody)
+				}
+			}
+		})
+	}
+}
+
 func TestHandler_Update(t *testing.T) {
 	type fields struct {
 		UserDAO DAO
@@ -335,6 +672,34 @@ func TestHandler_Update(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "deleted manager",
+			fields: fields{
+				UserDAO: &mockUserDAO{err: errorx.ErrDeleteManager},
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodPatch, "http://www.google.com/1234", strings.NewReader(`{"manager_id":"123456789012345678901234567890123456"}`)),
+			},
+			status: http.StatusUnprocessableEntity,
+			body: errorMessage{
+				Error:   "manager user has been deleted",
+				Message: "user manager is not valid",
+			},
+		},
+		{
+			name: "manager cycle",
+			fields: fields{
+				UserDAO: &mockUserDAO{err: errorx.ErrManagerCycle},
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodPatch, "http://www.google.com/1234", strings.NewReader(`{"manager_id":"123456789012345678901234567890123456"}`)),
+			},
+			status: http.StatusUnprocessableEntity,
+			body: errorMessage{
+				Error:   "user can not report to itself or one of its reports",
+				Message: "user manager is not valid",
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -411,14 +776,302 @@ func TestHandler_Delete(t *testing.T) {
 	}
 }
 
+func TestHandler_RemoveManager(t *testing.T) {
+	type fields struct {
+		UserDAO DAO
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		status int
+	}{
+		{
+			name: "removed",
+			fields: fields{
+				UserDAO: &mockUserDAO{},
+			},
+			status: http.StatusNoContent,
+		},
+		{
+			name: "no user",
+			fields: fields{
+				UserDAO: &mockUserDAO{err: errorx.ErrNoUser},
+			},
+			status: http.StatusNotFound,
+		},
+		{
+			name: "deleted user",
+			fields: fields{
+				UserDAO: &mockUserDAO{err: errorx.ErrDeleteUser},
+			},
+			status: http.StatusGone,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Handler{
+				UserDAO: tt.fields.UserDAO,
+			}
+			writer := httptest.NewRecorder()
+			handler := h.removeManager()
+			handler.ServeHTTP(writer, httptest.NewRequest(http.MethodDelete, "http://www.google.com/users/1234/manager", nil))
+
+			if writer.Result().StatusCode != tt.status {
+				t.Errorf("Handler.RemoveManager() = %v, want %v", writer.Result().StatusCode, tt.status)
+			}
+		})
+	}
+}
+
+func TestHandler_Reports(t *testing.T) {
+	type fields struct {
+		UserDAO *mockUserDAO
+	}
+	type args struct {
+		req *http.Request
+	}
+	report := &model.UserNode{
+		UserEntity: model.UserEntity{
+			Entity: model.Entity{ID: "5678"},
+			User:   model.User{FirstName: "test", ManagerID: "1234"},
+		},
+		Depth: 1,
+	}
+	tests := []struct {
+		name   string
+		fields fields
+		args   args
+		status int
+		depth  int
+		body   interface{}
+	}{
+		{
+			name: "reports",
+			fields: fields{
+				UserDAO: &mockUserDAO{nodes: []*model.UserNode{report}},
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodGet, "http://localhost:8080/users/1234/reports?depth=2", nil),
+			},
+			status: http.StatusOK,
+			depth:  2,
+			body:   []*model.UserNode{report},
+		},
+		{
+			name: "reports depth",
+			fields: fields{
+				UserDAO: &mockUserDAO{},
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodGet, "http://localhost:8080/users/1234/reports?depth=0", nil),
+			},
+			status: http.StatusBadRequest,
+			body: errorMessage{
+				Message: `depth "0" must be a number from 1 to 64`,
+			},
+		},
+		{
+			name: "chain deleted user",
+			fields: fields{
+				UserDAO: &mockUserDAO{err: errorx.ErrDeleteUser},
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodGet, "http://localhost:8080/users/1234/chain", nil),
+			},
+			status: http.StatusGone,
+			body: errorMessage{
+				Message: "user 1234 has been deleted",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Handler{
+				UserDAO: tt.fields.UserDAO,
+			}
+			router := mux.NewRouter()
+			h.Add(router)
+			writer := httptest.NewRecorder()
+			router.ServeHTTP(writer, tt.args.req)
+
+			if writer.Result().StatusCode != tt.status {
+				t.Errorf("Handler.Reports() = %v, want %v", writer.Result().StatusCode, tt.status)
+				return
+			}
+			if tt.fields.UserDAO.depth != tt.depth {
+				t.Errorf("Handler.Reports() depth = %v, want %v", tt.fields.UserDAO.depth, tt.depth)
+			}
+
+			var body interface{}
+			if err := json.NewDecoder(writer.Body).Decode(&body); err != nil {
+				t.Errorf("Handler.Reports() = json body decode error %v", err)
+				return
+			}
+
+			var wantBody interface{}
+			if enc, err := json.Marshal(tt.body); err == nil {
+				_ = json.Unmarshal(enc, &wantBody)
+			}
+
+			if !reflect.DeepEqual(body, wantBody) {
+				t.Errorf("Handler.Reports() = %v, want %v", body, wantBody)
+			}
+		})
+	}
+}
+
+func TestHandler_Stats(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		t.Skipf("time zone database error %v", err)
+	}
+	stats := &model.UserStats{
+		Active:    2,
+		Deleted:   1,
+		Statuses:  map[model.UserStatus]int{model.UserActive: 2},
+		Interval:  model.UserStatsWeek,
+		Timezone:  "America/Chicago",
+		Created:   []model.UserStatsBucket{{Start: time.Date(2026, 3, 2, 0, 0, 0, 0, chicago), Count: 3}},
+		LastNames: []model.UserStatsLastName{{LastName: "smith", Count: 2}},
+	}
+	tests := []struct {
+		name      string
+		target    string
+		dao       *mockUserDAO
+		status    int
+		wantQuery *model.UserStatsQuery
+		wantBody  interface{}
+	}{
+		{
+			name:   "stats",
+			target: "/v1/users/stats?interval=week&tz=America/Chicago&from=2026-03-01&to=2026-03-15T00:00:00Z&top=5",
+			dao:    &mockUserDAO{stats: stats},
+			status: http.StatusOK,
+			wantQuery: &model.UserStatsQuery{
+				From:      time.Date(2026, 3, 1, 0, 0, 0, 0, chicago),
+				To:        time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC),
+				Interval:  model.UserStatsWeek,
+				Location:  chicago,
+				LastNames: 5,
+			},
+			wantBody: stats,
+		},
+		{
+			name:   "interval",
+			target: "/v1/users/stats?interval=year",
+			dao:    &mockUserDAO{},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "time zone",
+			target: "/v1/users/stats?tz=Mars/Olympus",
+			dao:    &mockUserDAO{},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "range",
+			target: "/v1/users/stats?from=2026-03-15&to=2026-03-01",
+			dao:    &mockUserDAO{},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "buckets",
+			target: "/v1/users/stats?from=2020-01-01&to=2026-01-01",
+			dao:    &mockUserDAO{},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "top",
+			target: "/v1/users/stats?top=many",
+			dao:    &mockUserDAO{},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "datastore",
+			target: "/v1/users/stats",
+			dao:    &mockUserDAO{err: errors.New("database is locked")},
+			status: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Handler{
+				UserDAO: tt.dao,
+			}
+			writer := httptest.NewRecorder()
+			h.stats().ServeHTTP(writer, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			if writer.Result().StatusCode != tt.status {
+				t.Errorf("Handler.Stats() = %v, want %v", writer.Result().StatusCode, tt.status)
+				return
+			}
+			if tt.wantQuery != nil && reflect.DeepEqual(tt.dao.statsQuery, tt.wantQuery) == false {
+				t.Errorf("Handler.Stats() query = %+v, want %+v", tt.dao.statsQuery, tt.wantQuery)
+			}
+			if tt.wantBody == nil {
+				return
+			}
+			var body, wantBody interface{}
+			if err := json.NewDecoder(writer.Body).Decode(&body); err != nil {
+				t.Errorf("Handler.Stats() = json body decode error %v", err)
+				return
+			}
+			if enc, err := json.Marshal(tt.wantBody); err == nil {
+				_ = json.Unmarshal(enc, &wantBody)
+			}
+			if reflect.DeepEqual(body, wantBody) == false {
+				t.Errorf("Handler.Stats() = %v, want %v", body, wantBody)
+			}
+		})
+	}
+}
+
+func TestStatsQueryOf_Defaults(t *testing.T) {
+	now := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		target   string
+		wantFrom time.Time
+	}{
+		{
+			name:     "day",
+			target:   "/v1/users/stats",
+			wantFrom: now.AddDate(0, 0, -30),
+		},
+		{
+			name:     "week",
+			target:   "/v1/users/stats?interval=week",
+			wantFrom: now.AddDate(0, 0, -84),
+		},
+		{
+			name:     "month",
+			target:   "/v1/users/stats?interval=month",
+			wantFrom: now.AddDate(0, -12, 0),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := statsQueryOf(httptest.NewRequest(http.MethodGet, tt.target, nil), now)
+			if err != nil {
+				t.Errorf("statsQueryOf() error = %v", err)
+				return
+			}
+			if q.From.Equal(tt.wantFrom) == false || q.To.Equal(now) == false || q.Location != time.UTC || q.LastNames != statsLastNames {
+				t.Errorf("statsQueryOf() = %+v, want from %v to %v", q, tt.wantFrom, now)
+			}
+		})
+	}
+}
+
 func TestHandler_Add(t *testing.T) {
 	type args struct {
 		req *http.Request
 	}
 	tests := []struct {
-		name string
-		args args
-		want bool
+		name  string
+		args  args
+		want  bool
+		route string
 	}{
 		{
 			name: "create",
@@ -455,6 +1108,36 @@ func TestHandler_Add(t *testing.T) {
 			},
 			want: true,
 		},
+		{
+			name: "reports",
+			args: args{
+				req: httptest.NewRequest(http.MethodGet, "http://localhost:8080/users/1234/reports?depth=2", nil),
+			},
+			want: true,
+		},
+		{
+			name: "chain",
+			args: args{
+				req: httptest.NewRequest(http.MethodGet, "http://localhost:8080/users/1234/chain", nil),
+			},
+			want: true,
+		},
+		{
+			name: "remove manager",
+			args: args{
+				req: httptest.NewRequest(http.MethodDelete, "http://localhost:8080/users/1234/manager", nil),
+			},
+			want:  true,
+			route: "user-remove-manager",
+		},
+		{
+			name: "stats",
+			args: args{
+				req: httptest.NewRequest(http.MethodGet, "http://localhost:8080/users/stats", nil),
+			},
+			want:  true,
+			route: "user-stats",
+		},
 		{
 			name: "nope",
 			args: args{
@@ -474,6 +1157,9 @@ func TestHandler_Add(t *testing.T) {
 			if ok := r.Match(tt.args.req, &match); ok != tt.want {
 				t.Errorf("Handler.Add() %v", tt.want)
 			}
+			if len(tt.route) > 0 && match.Route.GetName() != tt.route {
+				t.Errorf("Handler.Add() route = %v, want %v", match.Route.GetName(), tt.route)
+			}
 		})
 	}
 }
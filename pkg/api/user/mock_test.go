@@ -9,19 +9,28 @@ import (
 type mockUserDAO struct {
 	user  *model.UserEntity
 	users []*model.UserEntity
-	err   error
+	nodes []*model.UserNode
+	depth int
+	// search is the last search and cursor is the cursor of the next page
+	search *model.UserSearch
+	cursor string
+	// stats are returned by Stats and statsQuery is the last query
+	stats      *model.UserStats
+	statsQuery *model.UserStatsQuery
+	err        error
 }
 
 func (m *mockUserDAO) Create(ctx context.Context, user *model.User) (*model.UserEntity, error) {
 	return m.user, m.err
 }
 
-func (m *mockUserDAO) FetchByID(ctx context.Context, id string) (*model.UserEntity, error) {
+func (m *mockUserDAO) FetchByID(ctx context.Context, id string, fields ...string) (*model.UserEntity, error) {
 	return m.user, m.err
 }
 
-func (m *mockUserDAO) FetchAll(ctx context.Context) ([]*model.UserEntity, error) {
-	return m.users, m.err
+func (m *mockUserDAO) Search(ctx context.Context, search *model.UserSearch, fields ...string) ([]*model.UserEntity, string, error) {
+	m.search = search
+	return m.users, m.cursor, m.err
 }
 
 func (m *mockUserDAO) Update(ctx context.Context, id string, user *model.User) (*model.UserEntity, error) {
@@ -31,3 +40,29 @@ func (m *mockUserDAO) Update(ctx context.Context, id string, user *model.User) (
 func (m *mockUserDAO) Delete(ctx context.Context, id string) error {
 	return m.err
 }
+
+func (m *mockUserDAO) RemoveManager(ctx context.Context, id string) error {
+	return m.err
+}
+
+type errReader struct {
+	err error
+}
+
+func (r *errReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+func (m *mockUserDAO) Reports(ctx context.Context, id string, depth int) ([]*model.UserNode, error) {
+	m.depth = depth
+	return m.nodes, m.err
+}
+
+func (m *mockUserDAO) Chain(ctx context.Context, id string) ([]*model.UserNode, error) {
+	return m.nodes, m.err
+}
+
+func (m *mockUserDAO) Stats(ctx context.Context, query *model.UserStatsQuery) (*model.UserStats, error) {
+	m.statsQuery = query
+	return m.stats, m.err
+}
@@ -0,0 +1,68 @@
+package dal
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/g8rswimmer/go-data-access-example/pkg/errorx"
+)
+
+// cursorValue is an order value of a cursor, the type is kept so that it is bound as it was scanned
+type cursorValue struct {
+	String *string    `json:"s,omitempty"`
+	Time   *time.Time `json:"t,omitempty"`
+	Int    *int64     `json:"i,omitempty"`
+}
+
+// encodeCursor returns the opaque cursor of the order values of the last row of a page
+func encodeCursor(values ...interface{}) (string, error) {
+	cursor := make([]cursorValue, 0, len(values))
+	for _, v := range values {
+		switch value := v.(type) {
+		case string:
+			cursor = append(cursor, cursorValue{String: &value})
+		case time.Time:
+			cursor = append(cursor, cursorValue{Time: &value})
+		case int64:
+			cursor = append(cursor, cursorValue{Int: &value})
+		case int:
+			i := int64(value)
+			cursor = append(cursor, cursorValue{Int: &i})
+		default:
+			return "", fmt.Errorf("cursor value %T is not a string, time or integer", v)
+		}
+	}
+	enc, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(enc), nil
+}
+
+// decodeCursor returns the order values of the cursor, there must be as many values as order columns
+func decodeCursor(cursor string, columns int) ([]interface{}, error) {
+	dec, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("%w: cursor is not valid", errorx.ErrInvalidSearch)
+	}
+	decoded := []cursorValue{}
+	if err := json.Unmarshal(dec, &decoded); err != nil || len(decoded) != columns {
+		return nil, fmt.Errorf("%w: cursor is not valid", errorx.ErrInvalidSearch)
+	}
+	values := make([]interface{}, 0, len(decoded))
+	for _, v := range decoded {
+		switch {
+		case v.String != nil:
+			values = append(values, *v.String)
+		case v.Time != nil:
+			values = append(values, *v.Time)
+		case v.Int != nil:
+			values = append(values, *v.Int)
+		default:
+			return nil, fmt.Errorf("%w: cursor is not valid", errorx.ErrInvalidSearch)
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,82 @@
+package dal
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/g8rswimmer/go-data-access-example/pkg/errorx"
+	"github.com/mattn/go-sqlite3"
+)
+
+// translate classifies the driver and context errors, the original error is still wrapped
+func translate(err error) error {
+	var sqliteErr sqlite3.Error
+	switch {
+	case err == nil:
+		return nil
+	case errorx.Class(err) != nil:
+		return err
+	case errors.Is(err, context.DeadlineExceeded):
+		return errorx.Wrap(errorx.ErrTimeout, err)
+	case errors.Is(err, context.Canceled):
+		return errorx.Wrap(errorx.ErrCanceled, err)
+	case errors.Is(err, sql.ErrNoRows):
+		return errorx.Wrap(errorx.ErrNotFound, err)
+	case errors.Is(err, sql.ErrConnDone), errors.Is(err, driver.ErrBadConn):
+		return errorx.Wrap(errorx.ErrUnavailable, err)
+	case errors.As(err, &sqliteErr):
+		if class := sqliteClass(sqliteErr); class != nil {
+			return errorx.Wrap(class, err)
+		}
+	}
+	return err
+}
+
+// sqliteClass returns the class of the sqlite result code
+func sqliteClass(err sqlite3.Error) error {
+	switch err.Code {
+	case sqlite3.ErrConstraint:
+		switch err.ExtendedCode {
+		case sqlite3.ErrConstraintUnique, sqlite3.ErrConstraintPrimaryKey:
+			return errorx.ErrConflict
+		}
+		return errorx.ErrConstraint
+	case sqlite3.ErrBusy, sqlite3.ErrLocked, sqlite3.ErrCantOpen, sqlite3.ErrFull, sqlite3.ErrIoErr, sqlite3.ErrReadonly, sqlite3.ErrProtocol:
+		return errorx.ErrUnavailable
+	case sqlite3.ErrInterrupt:
+		return errorx.ErrCanceled
+	}
+	return nil
+}
+
+// uniqueColumn returns the column of an unique constraint violation
+func uniqueColumn(err error) (string, bool) {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) == false || sqliteErr.ExtendedCode != sqlite3.ErrConstraintUnique {
+		return "", false
+	}
+	// the message is "UNIQUE constraint failed: table.column"
+	msg := sqliteErr.Error()
+	column := msg[strings.LastIndex(msg, ".")+1:]
+	return column, true
+}
+
+// userConflict maps an unique constraint violation to the user conflict error, the driver error is still wrapped
+func userConflict(err error) error {
+	if column, ok := uniqueColumn(err); ok {
+		return errorx.WithCause(fmt.Errorf("%w with the same %s", errorx.ErrUserConflict, column), err)
+	}
+	return err
+}
+
+// groupConflict maps an unique constraint violation to the group conflict error, the driver error is still wrapped
+func groupConflict(err error) error {
+	if _, ok := uniqueColumn(err); ok {
+		return errorx.WithCause(errorx.ErrGroupConflict, err)
+	}
+	return err
+}
@@ -0,0 +1,70 @@
+package dal
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/g8rswimmer/go-data-access-example/pkg/errorx"
+	"github.com/g8rswimmer/go-data-access-example/pkg/model"
+	"github.com/mattn/go-sqlite3"
+)
+
+func TestTranslate(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{name: "nil", err: nil, want: nil},
+		{name: "deadline", err: fmt.Errorf("query %w", context.DeadlineExceeded), want: errorx.ErrTimeout},
+		{name: "canceled", err: context.Canceled, want: errorx.ErrCanceled},
+		{name: "no rows", err: sql.ErrNoRows, want: errorx.ErrNotFound},
+		{name: "bad conn", err: driver.ErrBadConn, want: errorx.ErrUnavailable},
+		{name: "conn done", err: sql.ErrConnDone, want: errorx.ErrUnavailable},
+		{name: "unique", err: sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintUnique}, want: errorx.ErrConflict},
+		{name: "primary key", err: sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintPrimaryKey}, want: errorx.ErrConflict},
+		{name: "not null", err: sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintNotNull}, want: errorx.ErrConstraint},
+		{name: "busy", err: sqlite3.Error{Code: sqlite3.ErrBusy}, want: errorx.ErrUnavailable},
+		{name: "interrupt", err: sqlite3.Error{Code: sqlite3.ErrInterrupt}, want: errorx.ErrCanceled},
+		{name: "sql error", err: sqlite3.Error{Code: sqlite3.ErrError}, want: nil},
+		{name: "unknown opcode", err: sqlite3.Error{Code: sqlite3.ErrNotFound}, want: nil},
+		{name: "classified", err: errorx.ErrNoUser, want: errorx.ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := translate(tt.err)
+			if errorx.Class(got) != tt.want {
+				t.Errorf("translate() class = %v, want %v", errorx.Class(got), tt.want)
+			}
+			if tt.err != nil && errors.Is(got, tt.err) == false {
+				t.Errorf("translate() = %v does not wrap %v", got, tt.err)
+			}
+		})
+	}
+}
+
+func TestUser_Errors(t *testing.T) {
+	u := &User{
+		DB: setupDB(append(Tables,
+			`INSERT INTO user (id, first_name, last_name) VALUES ('123456789012345678901234567890123456', 'test', 'one')`,
+		)),
+		GenerateUUID: func() string {
+			return "123456789012345678901234567890123456"
+		},
+	}
+	defer u.DB.Close()
+
+	if _, err := u.Create(context.Background(), &model.User{FirstName: "test"}); errors.Is(err, errorx.ErrConflict) == false {
+		t.Errorf("User.Create() error = %v, want %v", err, errorx.ErrConflict)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := u.FetchAll(ctx); errors.Is(err, errorx.ErrCanceled) == false {
+		t.Errorf("User.FetchAll() error = %v, want %v", err, errorx.ErrCanceled)
+	}
+}
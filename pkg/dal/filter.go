@@ -0,0 +1,327 @@
+package dal
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/g8rswimmer/go-data-access-example/pkg/errorx"
+)
+
+// A filter is a small expression language that is compiled to predicates:
+//
+//	last_name eq "Smith" and (created_at ge 2026-01-01 or not status eq "active")
+//
+// A comparison is a field, an operator and a value.  The operators are eq, ne, gt, ge, lt, le and
+// co (contains), comparisons are joined with and, or and not, and grouped with parentheses.
+// Strings are double quoted with \" and \\ escapes, times are RFC 3339 times or dates and null
+// matches a field without a value.  A field without a value does not equal any string, so ne and
+// not match it.  The keywords are not case sensitive.
+
+const (
+	// maxFilterLength is the longest filter in characters
+	maxFilterLength = 2048
+	// maxFilterDepth is the deepest nesting of parentheses and not
+	maxFilterDepth = 32
+)
+
+// filterKind is the type of the values of a filter field
+type filterKind int
+
+const (
+	filterString filterKind = iota
+	filterTime
+)
+
+// filterField is a field that can be filtered on
+type filterField struct {
+	column string
+	kind   filterKind
+	// nullable is a column that stores the empty string as null
+	nullable bool
+}
+
+// filterOperators are the comparison operators
+var filterOperators = map[string]func(column string, value interface{}) Predicate{
+	"eq": Eq,
+	"ne": Ne,
+	"gt": Gt,
+	"ge": Gte,
+	"lt": Lt,
+	"le": Lte,
+}
+
+type filterTokenKind int
+
+const (
+	tokenWord filterTokenKind = iota
+	tokenString
+	tokenOpen
+	tokenClose
+	tokenEnd
+)
+
+// filterToken is a token of a filter, the text is the token as written and the value is the unquoted string
+type filterToken struct {
+	kind     filterTokenKind
+	text     string
+	value    string
+	position int
+}
+
+// is returns true when the token is the keyword
+func (t filterToken) is(keyword string) bool {
+	return t.kind == tokenWord && strings.EqualFold(t.text, keyword)
+}
+
+// lexFilter returns the tokens of the filter, the last token is the end
+func lexFilter(filter string) ([]filterToken, error) {
+	runes := []rune(filter)
+	tokens := []filterToken{}
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, filterToken{kind: tokenOpen, text: "(", position: i + 1})
+			i++
+		case r == ')':
+			tokens = append(tokens, filterToken{kind: tokenClose, text: ")", position: i + 1})
+			i++
+		case r == '"':
+			start := i
+			value := strings.Builder{}
+			closed := false
+			for i++; i < len(runes) && closed == false; i++ {
+				switch runes[i] {
+				case '"':
+					closed = true
+				case '\\':
+					if i+1 == len(runes) {
+						return nil, &errorx.FilterError{Position: start + 1, Token: string(runes[start:]), Message: "string is not terminated"}
+					}
+					if runes[i+1] != '"' && runes[i+1] != '\\' {
+						return nil, &errorx.FilterError{Position: i + 1, Token: string(runes[i : i+2]), Message: `only \" and \\ are string escapes`}
+					}
+					i++
+					value.WriteRune(runes[i])
+				default:
+					value.WriteRune(runes[i])
+				}
+			}
+			if closed == false {
+				return nil, &errorx.FilterError{Position: start + 1, Token: string(runes[start:]), Message: "string is not terminated"}
+			}
+			tokens = append(tokens, filterToken{kind: tokenString, text: string(runes[start:i]), value: value.String(), position: start + 1})
+		default:
+			start := i
+			for i < len(runes) && unicode.IsSpace(runes[i]) == false && strings.ContainsRune(`()"`, runes[i]) == false {
+				i++
+			}
+			tokens = append(tokens, filterToken{kind: tokenWord, text: string(runes[start:i]), position: start + 1})
+		}
+	}
+	return append(tokens, filterToken{kind: tokenEnd, position: len(runes) + 1}), nil
+}
+
+// filterParser is a recursive descent parser of a filter:
+//
+//	or         = and { "or" and }
+//	and        = unary { "and" unary }
+//	unary      = "not" unary | "(" or ")" | comparison
+//	comparison = field operator value
+type filterParser struct {
+	tokens []filterToken
+	next   int
+	depth  int
+	fields map[string]filterField
+}
+
+// parseFilter returns the predicate of the filter, the fields are the allowlist of the fields that can be compared
+func parseFilter(filter string, fields map[string]filterField) (Predicate, error) {
+	if n := utf8.RuneCountInString(filter); n > maxFilterLength {
+		return nil, &errorx.FilterError{Position: maxFilterLength + 1, Message: "filter is longer than 2048 characters"}
+	}
+	tokens, err := lexFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	p := &filterParser{tokens: tokens, fields: fields}
+	predicate, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEnd {
+		return nil, p.errorf(t, "expected and, or or the end of the filter")
+	}
+	return predicate, nil
+}
+
+func (p *filterParser) peek() filterToken {
+	return p.tokens[p.next]
+}
+
+func (p *filterParser) advance() filterToken {
+	t := p.tokens[p.next]
+	if t.kind != tokenEnd {
+		p.next++
+	}
+	return t
+}
+
+func (p *filterParser) errorf(t filterToken, message string) error {
+	return &errorx.FilterError{Position: t.position, Token: t.text, Message: message}
+}
+
+func (p *filterParser) or() (Predicate, error) {
+	predicates := []Predicate{}
+	for {
+		predicate, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		predicates = append(predicates, predicate)
+		if p.peek().is("or") == false {
+			return Or(predicates...), nil
+		}
+		p.advance()
+	}
+}
+
+func (p *filterParser) and() (Predicate, error) {
+	predicates := []Predicate{}
+	for {
+		predicate, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		predicates = append(predicates, predicate)
+		if p.peek().is("and") == false {
+			return And(predicates...), nil
+		}
+		p.advance()
+	}
+}
+
+func (p *filterParser) unary() (Predicate, error) {
+	t := p.peek()
+	if t.is("not") == false && t.kind != tokenOpen {
+		return p.comparison()
+	}
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > maxFilterDepth {
+		return nil, p.errorf(t, "filter is nested more than 32 levels")
+	}
+	p.advance()
+
+	if t.kind == tokenOpen {
+		predicate, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if c := p.advance(); c.kind != tokenClose {
+			return nil, p.errorf(c, "expected )")
+		}
+		return predicate, nil
+	}
+	predicate, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	return Not(predicate), nil
+}
+
+func (p *filterParser) comparison() (Predicate, error) {
+	name := p.advance()
+	if name.kind != tokenWord {
+		return nil, p.errorf(name, "expected a field")
+	}
+	field, ok := p.fields[name.text]
+	if ok == false {
+		return nil, p.errorf(name, "unknown field, expected one of "+strings.Join(p.fieldNames(), ", "))
+	}
+
+	op := p.advance()
+	operator := strings.ToLower(op.text)
+	compare, ok := filterOperators[operator]
+	if (ok == false && operator != "co") || op.kind != tokenWord {
+		return nil, p.errorf(op, "expected an operator, one of eq, ne, gt, ge, lt, le or co")
+	}
+
+	value := p.advance()
+	switch {
+	case value.is("null"):
+		switch {
+		case field.nullable == false:
+			return nil, p.errorf(value, name.text+" is never null")
+		case operator == "eq":
+			return IsNull(field.column), nil
+		case operator == "ne":
+			return NotNull(field.column), nil
+		default:
+			return nil, p.errorf(op, "null is only compared with eq or ne")
+		}
+	case field.kind == filterTime:
+		if operator == "co" {
+			return nil, p.errorf(op, "co only compares strings")
+		}
+		t, ok := timeOf(value)
+		if ok == false {
+			return nil, p.errorf(value, "expected a RFC 3339 time or a date")
+		}
+		// the time is compared as the julian day of its utc time, whatever its offset
+		return nullSafe(field, operator, compare(field.column, t)), nil
+	case value.kind != tokenString:
+		return nil, p.errorf(value, "expected a double quoted string")
+	case operator == "co":
+		return nullSafe(field, operator, Contains(field.column, value.value)), nil
+	case field.nullable && len(value.value) == 0 && operator == "eq":
+		// the empty string is stored as null
+		return IsNull(field.column), nil
+	case field.nullable && len(value.value) == 0 && operator == "ne":
+		return NotNull(field.column), nil
+	default:
+		return nullSafe(field, operator, compare(field.column, value.value)), nil
+	}
+}
+
+// nullSafe makes the comparison of a nullable field true or false for a null instead of unknown,
+// so that ne and a not of the comparison match the rows without a value
+func nullSafe(field filterField, operator string, p Predicate) Predicate {
+	switch {
+	case field.nullable == false:
+		return p
+	case operator == "ne":
+		return Or(p, IsNull(field.column))
+	default:
+		return And(p, NotNull(field.column))
+	}
+}
+
+// timeOf returns the time of a word or string, a date is midnight UTC
+func timeOf(t filterToken) (time.Time, bool) {
+	s := t.text
+	if t.kind == tokenString {
+		s = t.value
+	} else if t.kind != tokenWord {
+		return time.Time{}, false
+	}
+	if parsed, err := time.Parse(time.RFC3339, s); err == nil {
+		return parsed, true
+	}
+	parsed, err := time.Parse("2006-01-02", s)
+	return parsed, err == nil
+}
+
+func (p *filterParser) fieldNames() []string {
+	names := make([]string, 0, len(p.fields))
+	for name := range p.fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
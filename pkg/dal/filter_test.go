@@ -0,0 +1,214 @@
+package dal
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/g8rswimmer/go-data-access-example/pkg/errorx"
+)
+
+func TestParseFilter(t *testing.T) {
+	// a time is bound as the utc text of its julian day comparison
+	day := "2026-01-01 00:00:00"
+	tests := []struct {
+		name      string
+		filter    string
+		wantWhere string
+		wantArgs  []interface{}
+	}{
+		{
+			name:      "comparisons",
+			filter:    `last_name eq "Smith" and created_at gt 2026-01-01`,
+			wantWhere: "(last_name = ? AND julianday(created_at) > julianday(?))",
+			wantArgs:  []interface{}{"Smith", day},
+		},
+		{
+			name:      "precedence",
+			filter:    `first_name eq "a" or first_name eq "b" and NOT status ne "active"`,
+			wantWhere: "(first_name = ? OR (first_name = ? AND NOT (status <> ?)))",
+			wantArgs:  []interface{}{"a", "b", "active"},
+		},
+		{
+			name:      "parentheses",
+			filter:    `(first_name eq "a" or first_name eq "b") and updated_at le "2026-01-01T00:00:00Z"`,
+			wantWhere: "((first_name = ? OR first_name = ?) AND julianday(updated_at) <= julianday(?))",
+			wantArgs:  []interface{}{"a", "b", day},
+		},
+		{
+			name:      "time offset",
+			filter:    `created_at lt "2026-01-01T00:00:00-05:00"`,
+			wantWhere: "julianday(created_at) < julianday(?)",
+			wantArgs:  []interface{}{"2026-01-01 05:00:00"},
+		},
+		{
+			name:      "escapes and contains",
+			filter:    `last_name co "O\"Neil\\50%"`,
+			wantWhere: `last_name LIKE ? ESCAPE '\'`,
+			wantArgs:  []interface{}{`%O"Neil\\50\%%`},
+		},
+		{
+			name:      "null",
+			filter:    `email eq null and manager_id ne null and username eq ""`,
+			wantWhere: "(email IS NULL AND manager_id IS NOT NULL AND username IS NULL)",
+		},
+		{
+			name:      "nullable ne",
+			filter:    `email ne "a@example.com"`,
+			wantWhere: "(email <> ? OR email IS NULL)",
+			wantArgs:  []interface{}{"a@example.com"},
+		},
+		{
+			name:      "nullable not",
+			filter:    `not username eq "test" and phone co "555"`,
+			wantWhere: `(NOT ((username = ? AND username IS NOT NULL)) AND (phone LIKE ? ESCAPE '\' AND phone IS NOT NULL))`,
+			wantArgs:  []interface{}{"test", "%555%"},
+		},
+		{
+			name:      "keywords in strings",
+			filter:    `first_name ge "and" and last_name lt "or"`,
+			wantWhere: "(first_name >= ? AND last_name < ?)",
+			wantArgs:  []interface{}{"and", "or"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			predicate, err := parseFilter(tt.filter, userFilterFields)
+			if err != nil {
+				t.Errorf("parseFilter() error = %v", err)
+				return
+			}
+			stmt, args, err := Select("id").From("user").Where(predicate).Build(SQLite)
+			if err != nil {
+				t.Errorf("parseFilter() build error = %v", err)
+				return
+			}
+			if want := "SELECT id FROM user WHERE " + tt.wantWhere; stmt != want {
+				t.Errorf("parseFilter() stmt = %q, want %q", stmt, want)
+			}
+			if reflect.DeepEqual(args, tt.wantArgs) == false {
+				t.Errorf("parseFilter() args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestParseFilter_Errors(t *testing.T) {
+	tests := []struct {
+		name         string
+		filter       string
+		wantPosition int
+		wantToken    string
+	}{
+		{
+			name:         "unknown field",
+			filter:       `last_name eq "a" and password eq "b"`,
+			wantPosition: 22,
+			wantToken:    "password",
+		},
+		{
+			name:         "deleted at is not a field",
+			filter:       `deleted_at eq null`,
+			wantPosition: 1,
+			wantToken:    "deleted_at",
+		},
+		{
+			name:         "operator",
+			filter:       `last_name like "a"`,
+			wantPosition: 11,
+			wantToken:    "like",
+		},
+		{
+			name:         "unquoted string",
+			filter:       `last_name eq Smith`,
+			wantPosition: 14,
+			wantToken:    "Smith",
+		},
+		{
+			name:         "time",
+			filter:       `created_at gt "yesterday"`,
+			wantPosition: 15,
+			wantToken:    `"yesterday"`,
+		},
+		{
+			name:         "contains time",
+			filter:       `created_at co 2026-01-01`,
+			wantPosition: 12,
+			wantToken:    "co",
+		},
+		{
+			name:         "never null",
+			filter:       `last_name eq null`,
+			wantPosition: 14,
+			wantToken:    "null",
+		},
+		{
+			name:         "null operator",
+			filter:       `email gt null`,
+			wantPosition: 7,
+			wantToken:    "gt",
+		},
+		{
+			name:         "missing value",
+			filter:       `last_name eq`,
+			wantPosition: 13,
+		},
+		{
+			name:         "unclosed parenthesis",
+			filter:       `(last_name eq "a"`,
+			wantPosition: 18,
+		},
+		{
+			name:         "trailing token",
+			filter:       `last_name eq "a" "b"`,
+			wantPosition: 18,
+			wantToken:    `"b"`,
+		},
+		{
+			name:         "unterminated string",
+			filter:       `last_name eq "a`,
+			wantPosition: 14,
+			wantToken:    `"a`,
+		},
+		{
+			name:         "escape",
+			filter:       `last_name eq "a\n"`,
+			wantPosition: 16,
+			wantToken:    `\n`,
+		},
+		{
+			name:         "empty parentheses",
+			filter:       `()`,
+			wantPosition: 2,
+			wantToken:    ")",
+		},
+		{
+			name:         "nested",
+			filter:       strings.Repeat("not ", maxFilterDepth+1) + `last_name eq "a"`,
+			wantPosition: 4*maxFilterDepth + 1,
+			wantToken:    "not",
+		},
+		{
+			name:         "long",
+			filter:       strings.Repeat(" ", maxFilterLength+1),
+			wantPosition: maxFilterLength + 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseFilter(tt.filter, userFilterFields)
+			filterErr := &errorx.FilterError{}
+			if errors.As(err, &filterErr) == false {
+				t.Errorf("parseFilter() error = %v, want a filter error", err)
+				return
+			}
+			if filterErr.Position != tt.wantPosition || filterErr.Token != tt.wantToken {
+				t.Errorf("parseFilter() error = %v, want position %d %q", err, tt.wantPosition, tt.wantToken)
+			}
+			if errors.Is(err, errorx.ErrInvalidSearch) == false {
+				t.Errorf("parseFilter() error = %v, want %v", err, errorx.ErrInvalidSearch)
+			}
+		})
+	}
+}
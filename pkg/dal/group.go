@@ -0,0 +1,221 @@
+package dal
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/g8rswimmer/go-data-access-example/pkg/errorx"
+	"github.com/g8rswimmer/go-data-access-example/pkg/logx"
+	"github.com/g8rswimmer/go-data-access-example/pkg/metrics"
+	"github.com/g8rswimmer/go-data-access-example/pkg/model"
+)
+
+// Group handles all of the group and membership database actions
+type Group struct {
+	DB            *sql.DB
+	GenerateUUID  GenerateUUID
+	QueryDuration *metrics.HistogramVec
+}
+
+// observe records the duration of a group method labeled by entity and method
+func (g *Group) observe(method string, start time.Time) {
+	g.QueryDuration.Observe(time.Since(start).Seconds(), "group", method)
+}
+
+// repository handles the group entity actions
+func (g *Group) repository() *Repository {
+	return &Repository{
+		DB:            g.DB,
+		GenerateUUID:  g.GenerateUUID,
+		QueryDuration: g.QueryDuration,
+		Name:          "group",
+		Table:         "user_group",
+		Order:         "name",
+		NoEntity:      errorx.ErrNoGroup,
+		DeletedEntity: errorx.ErrDeleteGroup,
+	}
+}
+
+// Create will insert a group into the database
+func (g *Group) Create(ctx context.Context, group *model.Group) (*model.GroupEntity, error) {
+	if group == nil {
+		return nil, errors.New("group can not be nil")
+	}
+
+	e := &model.GroupEntity{Group: *group}
+	if err := g.repository().Create(ctx, e); err != nil {
+		return nil, groupConflict(err)
+	}
+	return e, nil
+}
+
+// FetchByID returns a group by the id
+func (g *Group) FetchByID(ctx context.Context, id string) (*model.GroupEntity, error) {
+	e := &model.GroupEntity{}
+	if err := g.repository().FetchByID(ctx, id, e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// FetchAll returns all of the groups that have not been deleted
+func (g *Group) FetchAll(ctx context.Context) ([]*model.GroupEntity, error) {
+	entities := []*model.GroupEntity{}
+	if err := g.repository().List(ctx, &entities); err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+// UserGroups returns the groups of the user that have not been deleted
+func (g *Group) UserGroups(ctx context.Context, userID string) ([]*model.GroupEntity, error) {
+	defer g.observe("user_groups", time.Now())
+
+	if err := activeUser(ctx, g.DB, userID); err != nil {
+		return nil, err
+	}
+
+	const stmt = `SELECT g.id, g.name, g.description, g.created_at, g.updated_at FROM user_group g
+		JOIN group_member m ON m.group_id = g.id
+		WHERE m.user_id = ? AND g.deleted_at IS NULL ORDER BY g.name`
+	return g.query(ctx, "group.user_groups", stmt, userID)
+}
+
+// query returns the groups of the statement
+func (g *Group) query(ctx context.Context, name, stmt string, args ...interface{}) ([]*model.GroupEntity, error) {
+	ctx, span := startStatement(ctx, name, stmt)
+	defer span.End()
+
+	rows, err := g.DB.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		span.SetError(err)
+		return nil, fmt.Errorf("group fetch query %w", translate(err))
+	}
+	defer rows.Close()
+
+	entities := []*model.GroupEntity{}
+	for rows.Next() {
+		e := &model.GroupEntity{}
+		if err := rows.Scan(&e.ID, &e.Name, &e.Description, &e.CreatedAt, &e.UpdatedAt); err != nil {
+			span.SetError(err)
+			return nil, fmt.Errorf("group row scan error %w", translate(err))
+		}
+		entities = append(entities, e)
+	}
+	if err := rows.Err(); err != nil {
+		span.SetError(err)
+		return nil, fmt.Errorf("group rows error %w", translate(err))
+	}
+	span.SetAttribute(attrRowsReturned, len(entities))
+	return entities, nil
+}
+
+// Update will update a group with new information
+func (g *Group) Update(ctx context.Context, id string, group *model.Group) (*model.GroupEntity, error) {
+	if group == nil {
+		return nil, errors.New("group can not be nil")
+	}
+
+	e := &model.GroupEntity{}
+	if err := g.repository().Update(ctx, id, group, e); err != nil {
+		return nil, groupConflict(err)
+	}
+	return e, nil
+}
+
+// Delete will soft delete a group, the memberships are kept
+func (g *Group) Delete(ctx context.Context, id string) error {
+	return g.repository().SoftDelete(ctx, id)
+}
+
+// AddMember will add the user to the group with the role
+func (g *Group) AddMember(ctx context.Context, groupID, userID string, role model.MemberRole) (*model.Member, error) {
+	defer g.observe("add_member", time.Now())
+
+	if _, err := g.FetchByID(ctx, groupID); err != nil {
+		return nil, err
+	}
+	if err := activeUser(ctx, g.DB, userID); err != nil {
+		return nil, err
+	}
+	if len(role) == 0 {
+		role = model.MemberRegular
+	}
+
+	m := &model.Member{
+		GroupID:   groupID,
+		UserID:    userID,
+		Role:      role,
+		CreatedAt: time.Now(),
+	}
+	const stmt = `INSERT INTO group_member (group_id, user_id, role, created_at) VALUES (?, ?, ?, ?)`
+	if _, err := execContext(ctx, g.DB, "group.add_member", stmt, m.GroupID, m.UserID, m.Role, m.CreatedAt); err != nil {
+		if errors.Is(err, errorx.ErrConflict) {
+			return nil, errorx.ErrMemberConflict
+		}
+		return nil, fmt.Errorf("group member insert %w", err)
+	}
+	logx.FromContext(ctx).Debug("group member added", logx.Fields{"id": groupID, "user_id": userID})
+	return m, nil
+}
+
+// RemoveMember will remove the user from the group
+func (g *Group) RemoveMember(ctx context.Context, groupID, userID string) error {
+	defer g.observe("remove_member", time.Now())
+
+	if _, err := g.FetchByID(ctx, groupID); err != nil {
+		return err
+	}
+
+	const stmt = `DELETE FROM group_member WHERE group_id = ? AND user_id = ?`
+	result, err := execContext(ctx, g.DB, "group.remove_member", stmt, groupID, userID)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return errorx.ErrNoMember
+	}
+	logx.FromContext(ctx).Debug("group member removed", logx.Fields{"id": groupID, "user_id": userID})
+	return nil
+}
+
+// Members returns the members of the group that have not been deleted
+func (g *Group) Members(ctx context.Context, groupID string) ([]*model.Member, error) {
+	defer g.observe("members", time.Now())
+
+	if _, err := g.FetchByID(ctx, groupID); err != nil {
+		return nil, err
+	}
+
+	const stmt = `SELECT m.group_id, m.user_id, m.role, m.created_at FROM group_member m
+		JOIN user u ON u.id = m.user_id
+		WHERE m.group_id = ? AND u.deleted_at IS NULL ORDER BY m.created_at, m.user_id`
+	ctx, span := startStatement(ctx, "group.members", stmt)
+	defer span.End()
+
+	rows, err := g.DB.QueryContext(ctx, stmt, groupID)
+	if err != nil {
+		span.SetError(err)
+		return nil, fmt.Errorf("group members query %w", translate(err))
+	}
+	defer rows.Close()
+
+	members := []*model.Member{}
+	for rows.Next() {
+		m := &model.Member{}
+		if err := rows.Scan(&m.GroupID, &m.UserID, &m.Role, &m.CreatedAt); err != nil {
+			span.SetError(err)
+			return nil, fmt.Errorf("group member scan error %w", translate(err))
+		}
+		members = append(members, m)
+	}
+	if err := rows.Err(); err != nil {
+		span.SetError(err)
+		return nil, fmt.Errorf("group members rows error %w", translate(err))
+	}
+	span.SetAttribute(attrRowsReturned, len(members))
+	return members, nil
+}
@@ -0,0 +1,156 @@
+package dal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/g8rswimmer/go-data-access-example/pkg/errorx"
+	"github.com/g8rswimmer/go-data-access-example/pkg/model"
+	"github.com/mattn/go-sqlite3"
+)
+
+func testGroup(stmts ...string) *Group {
+	next := 0
+	return &Group{
+		DB: setupDB(append(Tables, stmts...)),
+		GenerateUUID: func() string {
+			next++
+			return fmt.Sprintf("g%035d", next)
+		},
+	}
+}
+
+const (
+	memberUserID  = "123456789012345678901234567890123456"
+	deletedUserID = "223456789012345678901234567890123456"
+)
+
+var groupUsers = []string{
+	`INSERT INTO user (id, first_name, last_name) VALUES ('123456789012345678901234567890123456', 'test', 'one')`,
+	`INSERT INTO user (id, first_name, last_name, deleted_at) VALUES ('223456789012345678901234567890123456', 'test', 'two', CURRENT_TIMESTAMP)`,
+}
+
+func TestGroup_CRUD(t *testing.T) {
+	g := testGroup()
+	defer g.DB.Close()
+	ctx := context.Background()
+
+	created, err := g.Create(ctx, &model.Group{Name: "Engineering", Description: "builders"})
+	if err != nil {
+		t.Errorf("Group.Create() error = %v", err)
+		return
+	}
+	_, err = g.Create(ctx, &model.Group{Name: "engineering"})
+	if errors.Is(err, errorx.ErrGroupConflict) == false {
+		t.Errorf("Group.Create() error = %v, want %v", err, errorx.ErrGroupConflict)
+	}
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) == false || sqliteErr.ExtendedCode != sqlite3.ErrConstraintUnique {
+		t.Errorf("Group.Create() error = %v, want the sqlite unique constraint error", err)
+	}
+
+	updated, err := g.Update(ctx, created.ID, &model.Group{Description: "makers"})
+	if err != nil {
+		t.Errorf("Group.Update() error = %v", err)
+		return
+	}
+	if updated.Name != "Engineering" || updated.Description != "makers" {
+		t.Errorf("Group.Update() = %v", updated.Group)
+	}
+
+	got, err := g.FetchByID(ctx, created.ID)
+	if err != nil {
+		t.Errorf("Group.FetchByID() error = %v", err)
+		return
+	}
+	if reflect.DeepEqual(got.Group, updated.Group) == false {
+		t.Errorf("Group.FetchByID() = %v, want %v", got.Group, updated.Group)
+	}
+
+	if err := g.Delete(ctx, created.ID); err != nil {
+		t.Errorf("Group.Delete() error = %v", err)
+		return
+	}
+	if _, err := g.FetchByID(ctx, created.ID); errors.Is(err, errorx.ErrDeleteGroup) == false {
+		t.Errorf("Group.FetchByID() error = %v, want %v", err, errorx.ErrDeleteGroup)
+	}
+	if _, err := g.FetchByID(ctx, "g99999999999999999999999999999999999"); errors.Is(err, errorx.ErrNoGroup) == false {
+		t.Errorf("Group.FetchByID() error = %v, want %v", err, errorx.ErrNoGroup)
+	}
+	all, err := g.FetchAll(ctx)
+	if err != nil || len(all) != 0 {
+		t.Errorf("Group.FetchAll() = %v, %v want no groups", all, err)
+	}
+
+	recreated, err := g.Create(ctx, &model.Group{Name: "engineering"})
+	if err != nil {
+		t.Errorf("Group.Create() deleted name error = %v", err)
+		return
+	}
+	if recreated.ID == created.ID {
+		t.Errorf("Group.Create() = %v, want a new group", recreated.ID)
+	}
+}
+
+func TestGroup_Members(t *testing.T) {
+	g := testGroup(groupUsers...)
+	defer g.DB.Close()
+	ctx := context.Background()
+
+	group, err := g.Create(ctx, &model.Group{Name: "Engineering"})
+	if err != nil {
+		t.Errorf("Group.Create() error = %v", err)
+		return
+	}
+
+	member, err := g.AddMember(ctx, group.ID, memberUserID, "")
+	if err != nil {
+		t.Errorf("Group.AddMember() error = %v", err)
+		return
+	}
+	if member.Role != model.MemberRegular {
+		t.Errorf("Group.AddMember() role = %v, want %v", member.Role, model.MemberRegular)
+	}
+
+	tests := []struct {
+		name    string
+		groupID string
+		userID  string
+		wantErr error
+	}{
+		{name: "again", groupID: group.ID, userID: memberUserID, wantErr: errorx.ErrMemberConflict},
+		{name: "deleted user", groupID: group.ID, userID: deletedUserID, wantErr: errorx.ErrDeleteUser},
+		{name: "no user", groupID: group.ID, userID: "323456789012345678901234567890123456", wantErr: errorx.ErrNoUser},
+		{name: "no group", groupID: "g99999999999999999999999999999999999", userID: memberUserID, wantErr: errorx.ErrNoGroup},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := g.AddMember(ctx, tt.groupID, tt.userID, model.MemberAdmin); errors.Is(err, tt.wantErr) == false {
+				t.Errorf("Group.AddMember() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	members, err := g.Members(ctx, group.ID)
+	if err != nil || len(members) != 1 || members[0].UserID != memberUserID {
+		t.Errorf("Group.Members() = %v, %v", members, err)
+	}
+	groups, err := g.UserGroups(ctx, memberUserID)
+	if err != nil || len(groups) != 1 || groups[0].ID != group.ID {
+		t.Errorf("Group.UserGroups() = %v, %v", groups, err)
+	}
+
+	if err := g.RemoveMember(ctx, group.ID, memberUserID); err != nil {
+		t.Errorf("Group.RemoveMember() error = %v", err)
+	}
+	if err := g.RemoveMember(ctx, group.ID, memberUserID); errors.Is(err, errorx.ErrNoMember) == false {
+		t.Errorf("Group.RemoveMember() error = %v, want %v", err, errorx.ErrNoMember)
+	}
+	groups, err = g.UserGroups(ctx, memberUserID)
+	if err != nil || len(groups) != 0 {
+		t.Errorf("Group.UserGroups() = %v, %v want no groups", groups, err)
+	}
+}
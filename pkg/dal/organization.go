@@ -0,0 +1,359 @@
+package dal
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/g8rswimmer/go-data-access-example/pkg/errorx"
+	"github.com/g8rswimmer/go-data-access-example/pkg/logx"
+	"github.com/g8rswimmer/go-data-access-example/pkg/metrics"
+	"github.com/g8rswimmer/go-data-access-example/pkg/model"
+)
+
+const organizationColumns = `o.id, o.name, o.kind, o.parent_id, o.created_at, o.updated_at`
+
+// maxOrganizationDepth stops the recursive tree queries should the tree ever contain a cycle
+const maxOrganizationDepth = 64
+
+// organizationSubtree is the common table expression of the organization with the id and the organizations
+// below it that have not been deleted, with their depth below the organization.
+// Its arguments are the id and maxOrganizationDepth.
+const organizationSubtree = `subtree(id, depth) AS (
+	SELECT id, 0 FROM organization WHERE id = ? AND deleted_at IS NULL
+	UNION ALL
+	SELECT o.id, s.depth + 1 FROM organization o JOIN subtree s ON o.parent_id = s.id
+	WHERE o.deleted_at IS NULL AND s.depth < ?
+)`
+
+// Organization handles all of the organization tree database actions
+type Organization struct {
+	DB            *sql.DB
+	GenerateUUID  GenerateUUID
+	QueryDuration *metrics.HistogramVec
+}
+
+// observe records the duration of an organization method labeled by entity and method
+func (o *Organization) observe(method string, start time.Time) {
+	o.QueryDuration.Observe(time.Since(start).Seconds(), "organization", method)
+}
+
+// Create will insert an organization under its parent
+func (o *Organization) Create(ctx context.Context, org *model.Organization) (*model.OrganizationEntity, error) {
+	defer o.observe("create", time.Now())
+
+	if org == nil {
+		return nil, errors.New("organization can not be nil")
+	}
+	if err := o.placeable(ctx, org.Kind, org.ParentID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	e := &model.OrganizationEntity{
+		Entity: model.Entity{
+			ID:        o.GenerateUUID(),
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+		Organization: *org,
+	}
+
+	const stmt = `INSERT INTO organization (id, name, kind, parent_id, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)`
+	if _, err := execContext(ctx, o.DB, "organization.create", stmt, e.ID, e.Name, e.Kind, nullIfEmpty(e.ParentID), e.CreatedAt, e.UpdatedAt); err != nil {
+		return nil, fmt.Errorf("organization create insert %w", err)
+	}
+	logx.FromContext(ctx).Debug("organization created", logx.Fields{"id": e.ID})
+	return e, nil
+}
+
+// FetchByID returns an organization by the id
+func (o *Organization) FetchByID(ctx context.Context, id string) (*model.OrganizationEntity, error) {
+	defer o.observe("fetch_by_id", time.Now())
+
+	if len(id) != uuidLength {
+		return nil, fmt.Errorf("organization fetch by id length %d", len(id))
+	}
+
+	const stmt = `SELECT ` + organizationColumns + `, o.deleted_at FROM organization o WHERE o.id = ?`
+	ctx, span := startStatement(ctx, "organization.fetch_by_id", stmt)
+	defer span.End()
+
+	e := &model.OrganizationEntity{}
+	err := o.DB.QueryRowContext(ctx, stmt, id).Scan(&e.ID, &e.Name, &e.Kind, &nullString{s: &e.ParentID}, &e.CreatedAt, &e.UpdatedAt, &e.DeletedAt)
+	if err == nil {
+		span.SetAttribute(attrRowsReturned, 1)
+	}
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, errorx.ErrNoOrganization
+	case err != nil:
+		span.SetError(err)
+		return nil, fmt.Errorf("organization fetch query %w", translate(err))
+	case e.DeletedAt.Valid:
+		return nil, errorx.ErrDeleteOrganization
+	default:
+		return e, nil
+	}
+}
+
+// Roots returns the organizations without a parent
+func (o *Organization) Roots(ctx context.Context) ([]*model.OrganizationNode, error) {
+	defer o.observe("roots", time.Now())
+
+	const stmt = `SELECT ` + organizationColumns + `, 0, 0 FROM organization o
+	WHERE o.parent_id IS NULL AND o.deleted_at IS NULL ORDER BY o.name`
+	return o.nodes(ctx, "organization.roots", stmt)
+}
+
+// Children returns the organizations directly under the organization
+func (o *Organization) Children(ctx context.Context, id string) ([]*model.OrganizationNode, error) {
+	defer o.observe("children", time.Now())
+
+	if _, err := o.FetchByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	const stmt = `SELECT ` + organizationColumns + `, 1, 0 FROM organization o
+	WHERE o.parent_id = ? AND o.deleted_at IS NULL ORDER BY o.name`
+	return o.nodes(ctx, "organization.children", stmt, id)
+}
+
+// Ancestors returns the organizations above the organization, the parent first and the root last
+func (o *Organization) Ancestors(ctx context.Context, id string) ([]*model.OrganizationNode, error) {
+	defer o.observe("ancestors", time.Now())
+
+	if _, err := o.FetchByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	const stmt = `WITH RECURSIVE ancestor(id, depth) AS (
+		SELECT parent_id, 1 FROM organization WHERE id = ?
+		UNION ALL
+		SELECT o.parent_id, a.depth + 1 FROM organization o JOIN ancestor a ON o.id = a.id
+		WHERE o.parent_id IS NOT NULL AND a.depth < ?
+	)
+	SELECT ` + organizationColumns + `, a.depth, 0 FROM ancestor a JOIN organization o ON o.id = a.id
+	ORDER BY a.depth`
+	return o.nodes(ctx, "organization.ancestors", stmt, id, maxOrganizationDepth)
+}
+
+// Descendants returns the organization and the organizations below it ordered by depth,
+// each with the number of users in its subtree
+func (o *Organization) Descendants(ctx context.Context, id string) ([]*model.OrganizationNode, error) {
+	defer o.observe("descendants", time.Now())
+
+	if _, err := o.FetchByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	const stmt = `WITH RECURSIVE ` + organizationSubtree + `,
+	closure(ancestor, descendant, depth) AS (
+		SELECT id, id, 0 FROM subtree
+		UNION ALL
+		SELECT c.ancestor, o.id, c.depth + 1 FROM closure c JOIN organization o ON o.parent_id = c.descendant
+		WHERE o.deleted_at IS NULL AND c.depth < ?
+	),
+	counts(id, users) AS (
+		SELECT c.ancestor, COUNT(u.id) FROM closure c
+		LEFT JOIN organization_user ou ON ou.organization_id = c.descendant
+		LEFT JOIN user u ON u.id = ou.user_id AND u.deleted_at IS NULL
+		GROUP BY c.ancestor
+	)
+	SELECT ` + organizationColumns + `, s.depth, n.users FROM subtree s
+	JOIN organization o ON o.id = s.id
+	JOIN counts n ON n.id = s.id
+	ORDER BY s.depth, o.name`
+	return o.nodes(ctx, "organization.descendants", stmt, id, maxOrganizationDepth, maxOrganizationDepth)
+}
+
+// nodes returns the organizations of the statement, the last columns are the depth and user count
+func (o *Organization) nodes(ctx context.Context, name, stmt string, args ...interface{}) ([]*model.OrganizationNode, error) {
+	ctx, span := startStatement(ctx, name, stmt)
+	defer span.End()
+
+	rows, err := o.DB.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		span.SetError(err)
+		return nil, fmt.Errorf("organization query %w", translate(err))
+	}
+	defer rows.Close()
+
+	nodes := []*model.OrganizationNode{}
+	for rows.Next() {
+		n := &model.OrganizationNode{}
+		if err := rows.Scan(&n.ID, &n.Name, &n.Kind, &nullString{s: &n.ParentID}, &n.CreatedAt, &n.UpdatedAt, &n.Depth, &n.UserCount); err != nil {
+			span.SetError(err)
+			return nil, fmt.Errorf("organization row scan error %w", translate(err))
+		}
+		nodes = append(nodes, n)
+	}
+	if err := rows.Err(); err != nil {
+		span.SetError(err)
+		return nil, fmt.Errorf("organization rows error %w", translate(err))
+	}
+	span.SetAttribute(attrRowsReturned, len(nodes))
+	return nodes, nil
+}
+
+// Rename will change the name of the organization
+func (o *Organization) Rename(ctx context.Context, id, name string) (*model.OrganizationEntity, error) {
+	defer o.observe("rename", time.Now())
+
+	e, err := o.FetchByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	e.Name = name
+	e.UpdatedAt = time.Now()
+
+	const stmt = `UPDATE organization SET name = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`
+	if _, err := execContext(ctx, o.DB, "organization.rename", stmt, e.Name, id); err != nil {
+		return nil, err
+	}
+	logx.FromContext(ctx).Debug("organization renamed", logx.Fields{"id": id})
+	return e, nil
+}
+
+// Move will place the organization and its subtree under the parent, an empty parent makes it a root.
+// An organization can not be moved under itself or one of its descendants.
+func (o *Organization) Move(ctx context.Context, id, parentID string) (*model.OrganizationEntity, error) {
+	defer o.observe("move", time.Now())
+
+	e, err := o.FetchByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(parentID) > 0 {
+		const cycle = `WITH RECURSIVE ` + organizationSubtree + ` SELECT COUNT(*) FROM subtree WHERE id = ?`
+		ctx, span := startStatement(ctx, "organization.move_cycle", cycle)
+		var within int
+		err := o.DB.QueryRowContext(ctx, cycle, id, maxOrganizationDepth, parentID).Scan(&within)
+		span.End()
+		switch {
+		case err != nil:
+			return nil, fmt.Errorf("organization cycle query %w", translate(err))
+		case within > 0:
+			return nil, errorx.ErrOrganizationCycle
+		}
+	}
+
+	if err := o.placeable(ctx, e.Kind, parentID); err != nil {
+		return nil, err
+	}
+
+	e.ParentID = parentID
+	e.UpdatedAt = time.Now()
+
+	// the update repeats the checks above so a concurrent delete or move can not leave an orphan or a cycle
+	const stmt = `WITH RECURSIVE ` + organizationSubtree + `
+	UPDATE organization SET parent_id = ?, updated_at = CURRENT_TIMESTAMP
+	WHERE id = ? AND deleted_at IS NULL
+	AND (? IS NULL OR EXISTS (SELECT 1 FROM organization WHERE id = ? AND deleted_at IS NULL))
+	AND NOT EXISTS (SELECT 1 FROM subtree WHERE id = ?)`
+	result, err := execContext(ctx, o.DB, "organization.move", stmt,
+		id, maxOrganizationDepth, nullIfEmpty(parentID), id, nullIfEmpty(parentID), parentID, parentID)
+	if err != nil {
+		return nil, err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return nil, o.unmoved(ctx, e.Kind, id, parentID)
+	}
+	logx.FromContext(ctx).Debug("organization moved", logx.Fields{"id": id, "parent_id": parentID})
+	return e, nil
+}
+
+// unmoved returns why the move did not update the organization, it or its parent was deleted or the move makes a cycle
+func (o *Organization) unmoved(ctx context.Context, kind model.OrganizationKind, id, parentID string) error {
+	if _, err := o.FetchByID(ctx, id); err != nil {
+		return err
+	}
+	if err := o.placeable(ctx, kind, parentID); err != nil {
+		return err
+	}
+	return errorx.ErrOrganizationCycle
+}
+
+// placeable returns an error when an organization of the kind can not be placed under the parent
+func (o *Organization) placeable(ctx context.Context, kind model.OrganizationKind, parentID string) error {
+	var parentKind model.OrganizationKind
+	if len(parentID) > 0 {
+		parent, err := o.FetchByID(ctx, parentID)
+		switch {
+		case errors.Is(err, errorx.ErrNoOrganization), errors.Is(err, errorx.ErrDeleteOrganization):
+			return fmt.Errorf("%w: parent %s %s", errorx.ErrInvalidOrganization, parentID, err.Error())
+		case err != nil:
+			return err
+		}
+		parentKind = parent.Kind
+	}
+	return kind.CanParent(parentKind)
+}
+
+// Delete will soft delete an organization without children
+func (o *Organization) Delete(ctx context.Context, id string) error {
+	defer o.observe("delete", time.Now())
+
+	if _, err := o.FetchByID(ctx, id); err != nil {
+		return err
+	}
+
+	// the children are checked by the update so a concurrent create or move can not leave an orphan
+	const stmt = `UPDATE organization SET deleted_at = CURRENT_TIMESTAMP
+	WHERE id = ? AND deleted_at IS NULL
+	AND NOT EXISTS (SELECT 1 FROM organization WHERE parent_id = ? AND deleted_at IS NULL)`
+	result, err := execContext(ctx, o.DB, "organization.delete", stmt, id, id)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		// the organization may have been deleted since it was fetched
+		if _, err := o.FetchByID(ctx, id); err != nil {
+			return err
+		}
+		return errorx.ErrOrganizationChildren
+	}
+	logx.FromContext(ctx).Debug("organization deleted", logx.Fields{"id": id})
+	return nil
+}
+
+// PlaceUser will place the user in the organization, moving the user from any other organization
+func (o *Organization) PlaceUser(ctx context.Context, id, userID string) error {
+	defer o.observe("place_user", time.Now())
+
+	if _, err := o.FetchByID(ctx, id); err != nil {
+		return err
+	}
+	if err := activeUser(ctx, o.DB, userID); err != nil {
+		return err
+	}
+
+	const stmt = `INSERT OR REPLACE INTO organization_user (user_id, organization_id) VALUES (?, ?)`
+	if _, err := execContext(ctx, o.DB, "organization.place_user", stmt, userID, id); err != nil {
+		return err
+	}
+	logx.FromContext(ctx).Debug("organization user placed", logx.Fields{"id": id, "user_id": userID})
+	return nil
+}
+
+// RemoveUser will remove the user from the organization
+func (o *Organization) RemoveUser(ctx context.Context, id, userID string) error {
+	defer o.observe("remove_user", time.Now())
+
+	if _, err := o.FetchByID(ctx, id); err != nil {
+		return err
+	}
+
+	const stmt = `DELETE FROM organization_user WHERE organization_id = ? AND user_id = ?`
+	result, err := execContext(ctx, o.DB, "organization.remove_user", stmt, id, userID)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return errorx.ErrNoUser
+	}
+	return nil
+}
@@ -0,0 +1,251 @@
+package dal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/g8rswimmer/go-data-access-example/pkg/errorx"
+	"github.com/g8rswimmer/go-data-access-example/pkg/model"
+)
+
+func testOrganization(stmts ...string) *Organization {
+	next := 0
+	return &Organization{
+		DB: setupDB(append(Tables, stmts...)),
+		GenerateUUID: func() string {
+			next++
+			return fmt.Sprintf("o%035d", next)
+		},
+	}
+}
+
+// organizationTree creates acme with the departments engineering and sales, and the team platform within engineering
+func organizationTree(t *testing.T, o *Organization) map[string]*model.OrganizationEntity {
+	t.Helper()
+	ctx := context.Background()
+	tree := map[string]*model.OrganizationEntity{}
+	create := func(name string, kind model.OrganizationKind, parent string) {
+		org := &model.Organization{Name: name, Kind: kind}
+		if len(parent) > 0 {
+			org.ParentID = tree[parent].ID
+		}
+		e, err := o.Create(ctx, org)
+		if err != nil {
+			t.Fatalf("Organization.Create(%s) error = %v", name, err)
+		}
+		tree[name] = e
+	}
+	create("acme", model.OrganizationCompany, "")
+	create("engineering", model.OrganizationDepartment, "acme")
+	create("sales", model.OrganizationDepartment, "acme")
+	create("platform", model.OrganizationTeam, "engineering")
+	return tree
+}
+
+func nodeNames(nodes []*model.OrganizationNode) []string {
+	names := []string{}
+	for _, n := range nodes {
+		names = append(names, n.Name)
+	}
+	return names
+}
+
+func TestOrganization_Tree(t *testing.T) {
+	o := testOrganization(groupUsers...)
+	defer o.DB.Close()
+	ctx := context.Background()
+	tree := organizationTree(t, o)
+
+	roots, err := o.Roots(ctx)
+	if err != nil {
+		t.Errorf("Organization.Roots() error = %v", err)
+		return
+	}
+	if got, want := nodeNames(roots), []string{"acme"}; reflect.DeepEqual(got, want) == false {
+		t.Errorf("Organization.Roots() = %v, want %v", got, want)
+	}
+
+	children, err := o.Children(ctx, tree["acme"].ID)
+	if err != nil {
+		t.Errorf("Organization.Children() error = %v", err)
+		return
+	}
+	if got, want := nodeNames(children), []string{"engineering", "sales"}; reflect.DeepEqual(got, want) == false {
+		t.Errorf("Organization.Children() = %v, want %v", got, want)
+	}
+
+	ancestors, err := o.Ancestors(ctx, tree["platform"].ID)
+	if err != nil {
+		t.Errorf("Organization.Ancestors() error = %v", err)
+		return
+	}
+	if got, want := nodeNames(ancestors), []string{"engineering", "acme"}; reflect.DeepEqual(got, want) == false {
+		t.Errorf("Organization.Ancestors() = %v, want %v", got, want)
+	}
+	if ancestors[1].Depth != 2 {
+		t.Errorf("Organization.Ancestors() depth = %d, want 2", ancestors[1].Depth)
+	}
+
+	if err := o.PlaceUser(ctx, tree["platform"].ID, memberUserID); err != nil {
+		t.Errorf("Organization.PlaceUser() error = %v", err)
+		return
+	}
+	if err := o.PlaceUser(ctx, tree["sales"].ID, deletedUserID); errors.Is(err, errorx.ErrDeleteUser) == false {
+		t.Errorf("Organization.PlaceUser() error = %v, want %v", err, errorx.ErrDeleteUser)
+	}
+
+	descendants, err := o.Descendants(ctx, tree["acme"].ID)
+	if err != nil {
+		t.Errorf("Organization.Descendants() error = %v", err)
+		return
+	}
+	got := map[string][2]int{}
+	for _, n := range descendants {
+		got[n.Name] = [2]int{n.Depth, n.UserCount}
+	}
+	want := map[string][2]int{
+		"acme":        {0, 1},
+		"engineering": {1, 1},
+		"sales":       {1, 0},
+		"platform":    {2, 1},
+	}
+	if reflect.DeepEqual(got, want) == false {
+		t.Errorf("Organization.Descendants() = %v, want %v", got, want)
+	}
+	if got, want := nodeNames(descendants), []string{"acme", "engineering", "sales", "platform"}; reflect.DeepEqual(got, want) == false {
+		t.Errorf("Organization.Descendants() order = %v, want %v", got, want)
+	}
+
+	if err := o.RemoveUser(ctx, tree["platform"].ID, memberUserID); err != nil {
+		t.Errorf("Organization.RemoveUser() error = %v", err)
+	}
+	if err := o.RemoveUser(ctx, tree["platform"].ID, memberUserID); errors.Is(err, errorx.ErrNoUser) == false {
+		t.Errorf("Organization.RemoveUser() error = %v, want %v", err, errorx.ErrNoUser)
+	}
+	if err := o.RemoveUser(ctx, fmt.Sprintf("o%035d", 99), memberUserID); errors.Is(err, errorx.ErrNoOrganization) == false {
+		t.Errorf("Organization.RemoveUser() error = %v, want %v", err, errorx.ErrNoOrganization)
+	}
+}
+
+func TestOrganization_Move(t *testing.T) {
+	o := testOrganization()
+	defer o.DB.Close()
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		org    string
+		parent string
+		err    error
+	}{
+		{
+			name:   "into own subtree",
+			org:    "engineering",
+			parent: "platform",
+			err:    errorx.ErrOrganizationCycle,
+		},
+		{
+			name:   "under itself",
+			org:    "engineering",
+			parent: "engineering",
+			err:    errorx.ErrOrganizationCycle,
+		},
+		{
+			name:   "department under a team",
+			org:    "sales",
+			parent: "platform",
+			err:    errorx.ErrInvalidOrganization,
+		},
+		{
+			name: "team to a root",
+			org:  "platform",
+			err:  errorx.ErrInvalidOrganization,
+		},
+		{
+			name:   "under a deleted department",
+			org:    "platform",
+			parent: "support",
+			err:    errorx.ErrInvalidOrganization,
+		},
+		{
+			name:   "deleted team",
+			org:    "helpdesk",
+			parent: "sales",
+			err:    errorx.ErrDeleteOrganization,
+		},
+		{
+			name:   "team to another department",
+			org:    "platform",
+			parent: "sales",
+		},
+	}
+	tree := organizationTree(t, o)
+	deleted := func(name string, kind model.OrganizationKind, parent string) {
+		e, err := o.Create(ctx, &model.Organization{Name: name, Kind: kind, ParentID: tree[parent].ID})
+		if err != nil {
+			t.Fatalf("Organization.Create(%s) error = %v", name, err)
+		}
+		if err := o.Delete(ctx, e.ID); err != nil {
+			t.Fatalf("Organization.Delete(%s) error = %v", name, err)
+		}
+		tree[name] = e
+	}
+	deleted("support", model.OrganizationDepartment, "acme")
+	deleted("helpdesk", model.OrganizationTeam, "sales")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parentID := ""
+			if len(tt.parent) > 0 {
+				parentID = tree[tt.parent].ID
+			}
+			got, err := o.Move(ctx, tree[tt.org].ID, parentID)
+			if errors.Is(err, tt.err) == false || (tt.err == nil && err != nil) {
+				t.Errorf("Organization.Move() error = %v, want %v", err, tt.err)
+				return
+			}
+			if tt.err == nil && got.ParentID != parentID {
+				t.Errorf("Organization.Move() parent = %v, want %v", got.ParentID, parentID)
+			}
+		})
+	}
+
+	ancestors, err := o.Ancestors(ctx, tree["platform"].ID)
+	if err != nil {
+		t.Errorf("Organization.Ancestors() error = %v", err)
+		return
+	}
+	if got, want := nodeNames(ancestors), []string{"sales", "acme"}; reflect.DeepEqual(got, want) == false {
+		t.Errorf("Organization.Ancestors() = %v, want %v", got, want)
+	}
+}
+
+func TestOrganization_Delete(t *testing.T) {
+	o := testOrganization()
+	defer o.DB.Close()
+	ctx := context.Background()
+	tree := organizationTree(t, o)
+
+	if err := o.Delete(ctx, tree["engineering"].ID); errors.Is(err, errorx.ErrOrganizationChildren) == false {
+		t.Errorf("Organization.Delete() error = %v, want %v", err, errorx.ErrOrganizationChildren)
+	}
+	if err := o.Delete(ctx, tree["platform"].ID); err != nil {
+		t.Errorf("Organization.Delete() error = %v", err)
+		return
+	}
+	if _, err := o.FetchByID(ctx, tree["platform"].ID); errors.Is(err, errorx.ErrDeleteOrganization) == false {
+		t.Errorf("Organization.FetchByID() error = %v, want %v", err, errorx.ErrDeleteOrganization)
+	}
+	if _, err := o.Create(ctx, &model.Organization{Name: "infra", Kind: model.OrganizationTeam, ParentID: tree["platform"].ID}); errors.Is(err, errorx.ErrInvalidOrganization) == false {
+		t.Errorf("Organization.Create() error = %v, want %v", err, errorx.ErrInvalidOrganization)
+	}
+	if err := o.Delete(ctx, tree["engineering"].ID); err != nil {
+		t.Errorf("Organization.Delete() error = %v", err)
+	}
+	renamed, err := o.Rename(ctx, tree["sales"].ID, "revenue")
+	if err != nil || renamed.Name != "revenue" {
+		t.Errorf("Organization.Rename() = %v, %v", renamed, err)
+	}
+}
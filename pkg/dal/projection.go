@@ -0,0 +1,101 @@
+package dal
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/g8rswimmer/go-data-access-example/pkg/errorx"
+	"github.com/g8rswimmer/go-data-access-example/pkg/model"
+)
+
+const deletedAtColumn = "deleted_at"
+
+// userColumns are the scan targets of the user columns, the column names are the json field names
+var userColumns = map[string]func(e *model.UserEntity) interface{}{
+	"id":            func(e *model.UserEntity) interface{} { return &e.ID },
+	"first_name":    func(e *model.UserEntity) interface{} { return &e.FirstName },
+	"last_name":     func(e *model.UserEntity) interface{} { return &e.LastName },
+	"email":         func(e *model.UserEntity) interface{} { return &nullString{s: &e.Email} },
+	"username":      func(e *model.UserEntity) interface{} { return &nullString{s: &e.Username} },
+	"phone":         func(e *model.UserEntity) interface{} { return &nullString{s: &e.Phone} },
+	"status":        func(e *model.UserEntity) interface{} { return &e.Status },
+	"locale":        func(e *model.UserEntity) interface{} { return &nullString{s: &e.Locale} },
+	"timezone":      func(e *model.UserEntity) interface{} { return &nullString{s: &e.Timezone} },
+	"attributes":    func(e *model.UserEntity) interface{} { return &e.Attributes },
+	"manager_id":    func(e *model.UserEntity) interface{} { return &nullString{s: &e.ManagerID} },
+	"created_at":    func(e *model.UserEntity) interface{} { return &e.CreatedAt },
+	"updated_at":    func(e *model.UserEntity) interface{} { return &e.UpdatedAt },
+	deletedAtColumn: func(e *model.UserEntity) interface{} { return &e.DeletedAt },
+}
+
+// projection is the columns selected for a read
+type projection []string
+
+// userProjection returns the columns of the fields, all of the columns when there are no fields.
+// The deleted at column is always selected so deleted users are not returned.
+func userProjection(fields []string) (projection, error) {
+	if len(fields) == 0 {
+		fields = model.UserFields
+	}
+	p := projection{}
+	for _, f := range fields {
+		if _, has := userColumns[f]; has == false {
+			return nil, fmt.Errorf("%w %q", errorx.ErrUnknownField, f)
+		}
+		if f != deletedAtColumn {
+			p = append(p, f)
+		}
+	}
+	return append(p, deletedAtColumn), nil
+}
+
+// with returns the projection with the columns that are not already selected
+func (p projection) with(columns ...string) projection {
+	for _, c := range columns {
+		has := false
+		for _, pc := range p {
+			has = has || pc == c
+		}
+		if has == false {
+			p = append(p, c)
+		}
+	}
+	return p
+}
+
+// columns returns the select list
+func (p projection) columns() string {
+	return strings.Join(p, ", ")
+}
+
+// targets returns the scan targets of the entity
+func (p projection) targets(e *model.UserEntity) []interface{} {
+	targets := make([]interface{}, 0, len(p))
+	for _, c := range p {
+		targets = append(targets, userColumns[c](e))
+	}
+	return targets
+}
+
+// nullString scans a nullable column into a string, null is the empty string
+type nullString struct {
+	s *string
+}
+
+func (n *nullString) Scan(src interface{}) error {
+	ns := sql.NullString{}
+	if err := ns.Scan(src); err != nil {
+		return err
+	}
+	*n.s = ns.String
+	return nil
+}
+
+// nullIfEmpty stores the empty string as null so that unique columns may be left empty
+func nullIfEmpty(s string) interface{} {
+	if len(s) == 0 {
+		return nil
+	}
+	return s
+}
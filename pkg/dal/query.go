@@ -0,0 +1,562 @@
+package dal
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Dialect renders the parts of a statement that differ between databases
+type Dialect struct {
+	// Placeholder renders the n-th placeholder of a statement, counting from one
+	Placeholder func(n int) string
+	// Time renders a time column or placeholder so that it compares and orders by its instant
+	Time func(expr string) string
+	// TimeArg returns the argument of a time placeholder
+	TimeArg func(t time.Time) interface{}
+}
+
+// julianTime is the utc text of a time that is compared as a julian day
+const julianTime = "2006-01-02 15:04:05.999999999"
+
+var (
+	// SQLite renders every placeholder as ?, a time is compared as a julian day
+	// since sqlite keeps times as text in more than one format and offset
+	SQLite = Dialect{
+		Placeholder: func(int) string { return "?" },
+		Time:        func(expr string) string { return "julianday(" + expr + ")" },
+		TimeArg:     func(t time.Time) interface{} { return t.UTC().Format(julianTime) },
+	}
+	// Postgres numbers the placeholders $1, $2 and so on, a timestamp compares as it is
+	Postgres = Dialect{
+		Placeholder: func(n int) string { return "$" + strconv.Itoa(n) },
+		Time:        func(expr string) string { return expr },
+		TimeArg:     func(t time.Time) interface{} { return t },
+	}
+)
+
+// identifierPattern is a column or table name, optionally qualified by a table
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// builder renders a statement, the values are only ever written as placeholders
+type builder struct {
+	sb      strings.Builder
+	args    []interface{}
+	dialect Dialect
+}
+
+func (b *builder) write(s string) {
+	b.sb.WriteString(s)
+}
+
+func (b *builder) identifier(name string) error {
+	if identifierPattern.MatchString(name) == false {
+		return fmt.Errorf("query identifier %q is not a column or table name", name)
+	}
+	b.sb.WriteString(name)
+	return nil
+}
+
+// timeIdentifier writes the time column as the dialect compares it
+func (b *builder) timeIdentifier(name string) error {
+	if identifierPattern.MatchString(name) == false {
+		return fmt.Errorf("query identifier %q is not a column or table name", name)
+	}
+	b.sb.WriteString(b.dialect.Time(name))
+	return nil
+}
+
+func (b *builder) value(v interface{}) {
+	b.args = append(b.args, v)
+	b.sb.WriteString(b.dialect.Placeholder(len(b.args)))
+}
+
+// timeValue writes the time placeholder as the dialect compares it
+func (b *builder) timeValue(t time.Time) {
+	b.args = append(b.args, b.dialect.TimeArg(t))
+	b.sb.WriteString(b.dialect.Time(b.dialect.Placeholder(len(b.args))))
+}
+
+// where writes the predicates joined by and
+func (b *builder) where(predicates []Predicate) error {
+	p := And(predicates...).(*junction)
+	if len(p.predicates) == 0 {
+		return nil
+	}
+	b.write(" WHERE ")
+	return p.build(b)
+}
+
+// Predicate is a condition of a where clause
+type Predicate interface {
+	build(b *builder) error
+}
+
+// comparison compares a column to a value, a time is compared as the dialect renders it
+type comparison struct {
+	column string
+	op     string
+	value  interface{}
+}
+
+func (c *comparison) build(b *builder) error {
+	t, ok := c.value.(time.Time)
+	if ok == false {
+		if err := b.identifier(c.column); err != nil {
+			return err
+		}
+		b.write(" " + c.op + " ")
+		b.value(c.value)
+		return nil
+	}
+	if err := b.timeIdentifier(c.column); err != nil {
+		return err
+	}
+	b.write(" " + c.op + " ")
+	b.timeValue(t)
+	return nil
+}
+
+// Eq is true when the column equals the value
+func Eq(column string, value interface{}) Predicate {
+	return &comparison{column: column, op: "=", value: value}
+}
+
+// Ne is true when the column does not equal the value
+func Ne(column string, value interface{}) Predicate {
+	return &comparison{column: column, op: "<>", value: value}
+}
+
+// Gt is true when the column is greater than the value
+func Gt(column string, value interface{}) Predicate {
+	return &comparison{column: column, op: ">", value: value}
+}
+
+// Gte is true when the column is greater than or equal to the value
+func Gte(column string, value interface{}) Predicate {
+	return &comparison{column: column, op: ">=", value: value}
+}
+
+// Lt is true when the column is less than the value
+func Lt(column string, value interface{}) Predicate {
+	return &comparison{column: column, op: "<", value: value}
+}
+
+// Lte is true when the column is less than or equal to the value
+func Lte(column string, value interface{}) Predicate {
+	return &comparison{column: column, op: "<=", value: value}
+}
+
+// in is true when the column is one of the values
+type in struct {
+	column string
+	values []interface{}
+}
+
+func (p *in) build(b *builder) error {
+	if len(p.values) == 0 {
+		b.write("1 = 0")
+		return nil
+	}
+	if err := b.identifier(p.column); err != nil {
+		return err
+	}
+	b.write(" IN (")
+	for i, v := range p.values {
+		if i > 0 {
+			b.write(", ")
+		}
+		b.value(v)
+	}
+	b.write(")")
+	return nil
+}
+
+// In is true when the column is one of the values, it is false without values
+func In(column string, values ...interface{}) Predicate {
+	return &in{column: column, values: values}
+}
+
+// like matches the column to a pattern, the backslash escapes the wildcards
+type like struct {
+	column  string
+	pattern string
+}
+
+func (p *like) build(b *builder) error {
+	if err := b.identifier(p.column); err != nil {
+		return err
+	}
+	b.write(" LIKE ")
+	b.value(p.pattern)
+	b.write(` ESCAPE '\'`)
+	return nil
+}
+
+// Like is true when the column matches the pattern, % matches any text and _ any character
+func Like(column, pattern string) Predicate {
+	return &like{column: column, pattern: pattern}
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// Contains is true when the column contains the text, the text has no wildcards
+func Contains(column, text string) Predicate {
+	return &like{column: column, pattern: "%" + likeEscaper.Replace(text) + "%"}
+}
+
+// Range is true when the column is from the first value up to, but not including, the second value.
+// A nil value leaves that end of the range open.
+func Range(column string, from, to interface{}) Predicate {
+	predicates := []Predicate{}
+	if from != nil {
+		predicates = append(predicates, Gte(column, from))
+	}
+	if to != nil {
+		predicates = append(predicates, Lt(column, to))
+	}
+	return And(predicates...)
+}
+
+// null is true when the column is or is not null
+type null struct {
+	column string
+	not    bool
+}
+
+func (p *null) build(b *builder) error {
+	if err := b.identifier(p.column); err != nil {
+		return err
+	}
+	if p.not {
+		b.write(" IS NOT NULL")
+	} else {
+		b.write(" IS NULL")
+	}
+	return nil
+}
+
+// IsNull is true when the column is null
+func IsNull(column string) Predicate {
+	return &null{column: column}
+}
+
+// NotNull is true when the column is not null
+func NotNull(column string) Predicate {
+	return &null{column: column, not: true}
+}
+
+// junction joins predicates with and or or
+type junction struct {
+	op         string
+	predicates []Predicate
+}
+
+func (j *junction) build(b *builder) error {
+	if len(j.predicates) == 0 {
+		// an empty and is true and an empty or is false
+		if j.op == "AND" {
+			b.write("1 = 1")
+		} else {
+			b.write("1 = 0")
+		}
+		return nil
+	}
+	if len(j.predicates) == 1 {
+		return j.predicates[0].build(b)
+	}
+	b.write("(")
+	for i, p := range j.predicates {
+		if i > 0 {
+			b.write(" " + j.op + " ")
+		}
+		if err := p.build(b); err != nil {
+			return err
+		}
+	}
+	b.write(")")
+	return nil
+}
+
+func junctionOf(op string, predicates []Predicate) Predicate {
+	j := &junction{op: op}
+	for _, p := range predicates {
+		if p != nil {
+			j.predicates = append(j.predicates, p)
+		}
+	}
+	return j
+}
+
+// And is true when all of the predicates are true, nil predicates are ignored
+func And(predicates ...Predicate) Predicate {
+	return junctionOf("AND", predicates)
+}
+
+// Or is true when any of the predicates is true, nil predicates are ignored
+func Or(predicates ...Predicate) Predicate {
+	return junctionOf("OR", predicates)
+}
+
+// not negates a predicate
+type not struct {
+	predicate Predicate
+}
+
+func (n *not) build(b *builder) error {
+	b.write("NOT (")
+	if err := n.predicate.build(b); err != nil {
+		return err
+	}
+	b.write(")")
+	return nil
+}
+
+// Not is true when the predicate is false
+func Not(predicate Predicate) Predicate {
+	return &not{predicate: predicate}
+}
+
+// matchesAll returns true when the predicate is true for every row, an and of no predicates
+// or of predicates that match all, or an or of any predicate that matches all
+func matchesAll(p Predicate) bool {
+	j, ok := p.(*junction)
+	if ok == false {
+		return false
+	}
+	for _, predicate := range j.predicates {
+		all := matchesAll(predicate)
+		switch {
+		case j.op == "AND" && all == false:
+			return false
+		case j.op == "OR" && all:
+			return true
+		}
+	}
+	return j.op == "AND"
+}
+
+// Order is a column of an order by clause, a time column is ordered as the dialect renders it
+type Order struct {
+	Column     string
+	Descending bool
+	Time       bool
+}
+
+// Asc orders by the column from the lowest value
+func Asc(column string) Order {
+	return Order{Column: column}
+}
+
+// Desc orders by the column from the highest value
+func Desc(column string) Order {
+	return Order{Column: column, Descending: true}
+}
+
+// SelectQuery builds a select statement
+type SelectQuery struct {
+	columns []string
+	table   string
+	where   []Predicate
+	order   []Order
+	limit   int
+	after   []interface{}
+}
+
+// Select starts a select statement of the columns
+func Select(columns ...string) *SelectQuery {
+	return &SelectQuery{columns: columns}
+}
+
+// From sets the table that is selected from
+func (q *SelectQuery) From(table string) *SelectQuery {
+	q.table = table
+	return q
+}
+
+// Where adds predicates that must all be true
+func (q *SelectQuery) Where(predicates ...Predicate) *SelectQuery {
+	q.where = append(q.where, predicates...)
+	return q
+}
+
+// OrderBy adds columns to the order of the rows
+func (q *SelectQuery) OrderBy(orders ...Order) *SelectQuery {
+	q.order = append(q.order, orders...)
+	return q
+}
+
+// Limit sets the most rows selected, zero is no limit
+func (q *SelectQuery) Limit(n int) *SelectQuery {
+	q.limit = n
+	return q
+}
+
+// After selects the rows that are after the values of the order columns, a keyset cursor.
+// There is a value for each order column and the last order column should be unique.
+func (q *SelectQuery) After(values ...interface{}) *SelectQuery {
+	q.after = values
+	return q
+}
+
+// keyset returns the predicate of the rows after the cursor values,
+// (a, b) after (x, y) is a > x or (a = x and b > y) for ascending columns
+func (q *SelectQuery) keyset() (Predicate, error) {
+	if len(q.after) != len(q.order) {
+		return nil, fmt.Errorf("query cursor has %d values for %d order columns", len(q.after), len(q.order))
+	}
+	or := []Predicate{}
+	for i, o := range q.order {
+		and := []Predicate{}
+		for j := 0; j < i; j++ {
+			and = append(and, Eq(q.order[j].Column, q.after[j]))
+		}
+		if o.Descending {
+			and = append(and, Lt(o.Column, q.after[i]))
+		} else {
+			and = append(and, Gt(o.Column, q.after[i]))
+		}
+		or = append(or, And(and...))
+	}
+	return Or(or...), nil
+}
+
+// Build returns the statement and its arguments with the placeholders of the dialect
+func (q *SelectQuery) Build(d Dialect) (string, []interface{}, error) {
+	switch {
+	case len(q.columns) == 0:
+		return "", nil, errors.New("query selects no columns")
+	case len(q.table) == 0:
+		return "", nil, errors.New("query selects from no table")
+	case q.limit < 0:
+		return "", nil, fmt.Errorf("query limit %d is negative", q.limit)
+	}
+
+	b := &builder{dialect: d}
+	b.write("SELECT ")
+	for i, c := range q.columns {
+		if i > 0 {
+			b.write(", ")
+		}
+		if err := b.identifier(c); err != nil {
+			return "", nil, err
+		}
+	}
+	b.write(" FROM ")
+	if err := b.identifier(q.table); err != nil {
+		return "", nil, err
+	}
+
+	where := q.where
+	if q.after != nil {
+		keyset, err := q.keyset()
+		if err != nil {
+			return "", nil, err
+		}
+		where = append(append([]Predicate{}, where...), keyset)
+	}
+	if err := b.where(where); err != nil {
+		return "", nil, err
+	}
+
+	for i, o := range q.order {
+		if i == 0 {
+			b.write(" ORDER BY ")
+		} else {
+			b.write(", ")
+		}
+		identifier := b.identifier
+		if o.Time {
+			identifier = b.timeIdentifier
+		}
+		if err := identifier(o.Column); err != nil {
+			return "", nil, err
+		}
+		if o.Descending {
+			b.write(" DESC")
+		}
+	}
+	if q.limit > 0 {
+		b.write(" LIMIT ")
+		b.value(q.limit)
+	}
+	return b.sb.String(), b.args, nil
+}
+
+// UpdateQuery builds an update statement
+type UpdateQuery struct {
+	table   string
+	columns []string
+	values  []interface{}
+	now     []string
+	where   []Predicate
+}
+
+// Update starts an update statement of the table
+func Update(table string) *UpdateQuery {
+	return &UpdateQuery{table: table}
+}
+
+// Set sets the column to the value
+func (q *UpdateQuery) Set(column string, value interface{}) *UpdateQuery {
+	q.columns = append(q.columns, column)
+	q.values = append(q.values, value)
+	return q
+}
+
+// SetNow sets the column to the current time of the database
+func (q *UpdateQuery) SetNow(column string) *UpdateQuery {
+	q.now = append(q.now, column)
+	return q
+}
+
+// Where adds predicates that must all be true
+func (q *UpdateQuery) Where(predicates ...Predicate) *UpdateQuery {
+	q.where = append(q.where, predicates...)
+	return q
+}
+
+// Build returns the statement and its arguments with the placeholders of the dialect.
+// An update without predicates, or with predicates that are true for every row, is an error
+// so that a table is never updated by mistake.
+func (q *UpdateQuery) Build(d Dialect) (string, []interface{}, error) {
+	switch {
+	case len(q.columns) == 0 && len(q.now) == 0:
+		return "", nil, errors.New("query sets no columns")
+	case matchesAll(And(q.where...)):
+		return "", nil, errors.New("query updates every row")
+	}
+
+	b := &builder{dialect: d}
+	b.write("UPDATE ")
+	if err := b.identifier(q.table); err != nil {
+		return "", nil, err
+	}
+	b.write(" SET ")
+	for i, c := range q.columns {
+		if i > 0 {
+			b.write(", ")
+		}
+		if err := b.identifier(c); err != nil {
+			return "", nil, err
+		}
+		b.write(" = ")
+		b.value(q.values[i])
+	}
+	for i, c := range q.now {
+		if i > 0 || len(q.columns) > 0 {
+			b.write(", ")
+		}
+		if err := b.identifier(c); err != nil {
+			return "", nil, err
+		}
+		b.write(" = CURRENT_TIMESTAMP")
+	}
+	if err := b.where(q.where); err != nil {
+		return "", nil, err
+	}
+	return b.sb.String(), b.args, nil
+}
@@ -0,0 +1,219 @@
+package dal
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSelectQuery_Build(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    *SelectQuery
+		dialect  Dialect
+		wantStmt string
+		wantArgs []interface{}
+		wantErr  bool
+	}{
+		{
+			name:     "columns",
+			query:    Select("id", "name").From("user"),
+			dialect:  SQLite,
+			wantStmt: "SELECT id, name FROM user",
+		},
+		{
+			name:     "predicates",
+			query:    Select("id").From("user").Where(Eq("status", "active"), IsNull("deleted_at"), In("last_name", "one", "two")),
+			dialect:  SQLite,
+			wantStmt: "SELECT id FROM user WHERE (status = ? AND deleted_at IS NULL AND last_name IN (?, ?))",
+			wantArgs: []interface{}{"active", "one", "two"},
+		},
+		{
+			name:     "postgres placeholders",
+			query:    Select("id").From("user").Where(Or(Eq("a", 1), Ne("b", 2)), NotNull("c")).Limit(10),
+			dialect:  Postgres,
+			wantStmt: "SELECT id FROM user WHERE ((a = $1 OR b <> $2) AND c IS NOT NULL) LIMIT $3",
+			wantArgs: []interface{}{1, 2, 10},
+		},
+		{
+			name:     "contains escapes wildcards",
+			query:    Select("id").From("user").Where(Contains("email", `50%_\`)),
+			dialect:  SQLite,
+			wantStmt: `SELECT id FROM user WHERE email LIKE ? ESCAPE '\'`,
+			wantArgs: []interface{}{`%50\%\_\\%`},
+		},
+		{
+			name:     "range",
+			query:    Select("id").From("user").Where(Range("created_at", 1, 2), Range("updated_at", nil, 3), Range("deleted_at", nil, nil)),
+			dialect:  SQLite,
+			wantStmt: "SELECT id FROM user WHERE ((created_at >= ? AND created_at < ?) AND updated_at < ? AND 1 = 1)",
+			wantArgs: []interface{}{1, 2, 3},
+		},
+		{
+			name:     "not and empty in",
+			query:    Select("id").From("user").Where(Not(Like("name", "a%")), In("id")),
+			dialect:  SQLite,
+			wantStmt: `SELECT id FROM user WHERE (NOT (name LIKE ? ESCAPE '\') AND 1 = 0)`,
+			wantArgs: []interface{}{"a%"},
+		},
+		{
+			name:     "keyset",
+			query:    Select("id").From("user").Where(IsNull("deleted_at")).OrderBy(Asc("created_at"), Desc("id")).After("t", "x").Limit(2),
+			dialect:  Postgres,
+			wantStmt: "SELECT id FROM user WHERE (deleted_at IS NULL AND (created_at > $1 OR (created_at = $2 AND id < $3))) ORDER BY created_at, id DESC LIMIT $4",
+			wantArgs: []interface{}{"t", "t", "x", 2},
+		},
+		{
+			name:     "keyset time",
+			query:    Select("id").From("user").OrderBy(Order{Column: "created_at", Time: true}, Asc("id")).After(time.Date(2026, 1, 1, 0, 0, 0, 500, time.FixedZone("EST", -5*60*60)), "x"),
+			dialect:  SQLite,
+			wantStmt: "SELECT id FROM user WHERE (julianday(created_at) > julianday(?) OR (julianday(created_at) = julianday(?) AND id > ?)) ORDER BY julianday(created_at), id",
+			wantArgs: []interface{}{"2026-01-01 05:00:00.0000005", "2026-01-01 05:00:00.0000005", "x"},
+		},
+		{
+			name:     "time range",
+			query:    Select("id").From("user").Where(Range("created_at", nil, time.Date(2026, 1, 1, 0, 0, 0, 0, time.FixedZone("EST", -5*60*60)))),
+			dialect:  SQLite,
+			wantStmt: "SELECT id FROM user WHERE julianday(created_at) < julianday(?)",
+			wantArgs: []interface{}{"2026-01-01 05:00:00"},
+		},
+		{
+			name: "postgres time range and order",
+			query: Select("id").From("user").
+				Where(Range("created_at", time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC))).
+				OrderBy(Order{Column: "created_at", Time: true, Descending: true}, Asc("id")),
+			dialect:  Postgres,
+			wantStmt: "SELECT id FROM user WHERE (created_at >= $1 AND created_at < $2) ORDER BY created_at DESC, id",
+			wantArgs: []interface{}{time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:    "keyset values",
+			query:   Select("id").From("user").OrderBy(Asc("created_at"), Asc("id")).After("t"),
+			dialect: SQLite,
+			wantErr: true,
+		},
+		{
+			name:    "column injection",
+			query:   Select("id").From("user").Where(Eq("id = id; DROP TABLE user; --", 1)),
+			dialect: SQLite,
+			wantErr: true,
+		},
+		{
+			name:    "order injection",
+			query:   Select("id").From("user").OrderBy(Asc("id; DROP TABLE user")),
+			dialect: SQLite,
+			wantErr: true,
+		},
+		{
+			name:    "no table",
+			query:   Select("id"),
+			dialect: SQLite,
+			wantErr: true,
+		},
+		{
+			name:    "negative limit",
+			query:   Select("id").From("user").Limit(-1),
+			dialect: SQLite,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, args, err := tt.query.Build(tt.dialect)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SelectQuery.Build() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if stmt != tt.wantStmt {
+				t.Errorf("SelectQuery.Build() stmt = %q, want %q", stmt, tt.wantStmt)
+			}
+			if reflect.DeepEqual(args, tt.wantArgs) == false {
+				t.Errorf("SelectQuery.Build() args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestUpdateQuery_Build(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    *UpdateQuery
+		dialect  Dialect
+		wantStmt string
+		wantArgs []interface{}
+		wantErr  bool
+	}{
+		{
+			name:     "set",
+			query:    Update("user").Set("first_name", "test").Set("email", nil).SetNow("updated_at").Where(Eq("id", "1")),
+			dialect:  Postgres,
+			wantStmt: "UPDATE user SET first_name = $1, email = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $3",
+			wantArgs: []interface{}{"test", nil, "1"},
+		},
+		{
+			name:     "set now",
+			query:    Update("user").SetNow("deleted_at").Where(Eq("id", "1"), IsNull("deleted_at")),
+			dialect:  SQLite,
+			wantStmt: "UPDATE user SET deleted_at = CURRENT_TIMESTAMP WHERE (id = ? AND deleted_at IS NULL)",
+			wantArgs: []interface{}{"1"},
+		},
+		{
+			name:    "every row",
+			query:   Update("user").Set("status", "inactive"),
+			dialect: SQLite,
+			wantErr: true,
+		},
+		{
+			name:    "nil predicate",
+			query:   Update("user").Set("status", "inactive").Where(nil),
+			dialect: SQLite,
+			wantErr: true,
+		},
+		{
+			name:    "empty and",
+			query:   Update("user").Set("status", "inactive").Where(And(), And(nil)),
+			dialect: SQLite,
+			wantErr: true,
+		},
+		{
+			name:    "or of an empty and",
+			query:   Update("user").Set("status", "inactive").Where(Or(Eq("id", "1"), And())),
+			dialect: SQLite,
+			wantErr: true,
+		},
+		{
+			name:     "empty or",
+			query:    Update("user").Set("status", "inactive").Where(And(), Or()),
+			dialect:  SQLite,
+			wantStmt: "UPDATE user SET status = ? WHERE (1 = 1 AND 1 = 0)",
+			wantArgs: []interface{}{"inactive"},
+		},
+		{
+			name:    "no columns",
+			query:   Update("user").Where(Eq("id", "1")),
+			dialect: SQLite,
+			wantErr: true,
+		},
+		{
+			name:    "table injection",
+			query:   Update("user SET status = 1 --").Set("status", "inactive").Where(Eq("id", "1")),
+			dialect: SQLite,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, args, err := tt.query.Build(tt.dialect)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("UpdateQuery.Build() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if stmt != tt.wantStmt {
+				t.Errorf("UpdateQuery.Build() stmt = %q, want %q", stmt, tt.wantStmt)
+			}
+			if reflect.DeepEqual(args, tt.wantArgs) == false {
+				t.Errorf("UpdateQuery.Build() args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
@@ -0,0 +1,431 @@
+package dal
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/g8rswimmer/go-data-access-example/pkg/logx"
+	"github.com/g8rswimmer/go-data-access-example/pkg/metrics"
+	"github.com/g8rswimmer/go-data-access-example/pkg/model"
+)
+
+// Repository handles the create, read, update and soft delete database actions of any entity
+// that embeds model.Entity, so a new entity only needs a model and a table.
+//
+// The columns are the db tags of the entity fields, or the json names when there is no db tag.
+// A field tagged db:"-" is not stored and the db tag option nullempty stores the empty string as null,
+// such as db:"email,nullempty" for an optional unique column.
+type Repository struct {
+	DB            *sql.DB
+	GenerateUUID  GenerateUUID
+	QueryDuration *metrics.HistogramVec
+	// Name labels the metrics, spans and logs of the entity
+	Name string
+	// Table is the table of the entity
+	Table string
+	// Order is the order by clause of List, the created at column when empty
+	Order string
+	// NoEntity is returned when no entity has the id, such as errorx.ErrNoGroup
+	NoEntity error
+	// DeletedEntity is returned when the entity has been deleted, such as errorx.ErrDeleteGroup
+	DeletedEntity error
+}
+
+// observe records the duration of a repository method labeled by entity and method
+func (r *Repository) observe(method string, start time.Time) {
+	r.QueryDuration.Observe(time.Since(start).Seconds(), r.Name, method)
+}
+
+// Create will insert the entity, a pointer to a struct embedding model.Entity, the id and times are set
+func (r *Repository) Create(ctx context.Context, entity interface{}) error {
+	defer r.observe("create", time.Now())
+
+	v, m, err := entityValue(entity)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	e := m.entity(v)
+	e.ID = r.GenerateUUID()
+	e.CreatedAt = now
+	e.UpdatedAt = now
+
+	cols := m.stored()
+	stmt := fmt.Sprintf(`INSERT INTO %s (%s) VALUES (%s)`, r.Table, cols.names(), placeholders(len(cols)))
+	if _, err := execContext(ctx, r.DB, r.Name+".create", stmt, cols.values(v)...); err != nil {
+		return fmt.Errorf("%s create insert %w", r.Name, err)
+	}
+	logx.FromContext(ctx).Debug(r.Name+" created", logx.Fields{"id": e.ID})
+	return nil
+}
+
+// FetchByID will read the entity with the id into the entity, a pointer to a struct embedding model.Entity
+func (r *Repository) FetchByID(ctx context.Context, id string, entity interface{}) error {
+	defer r.observe("fetch_by_id", time.Now())
+
+	v, m, err := entityValue(entity)
+	if err != nil {
+		return err
+	}
+	return r.fetch(ctx, id, v, m)
+}
+
+func (r *Repository) fetch(ctx context.Context, id string, v reflect.Value, m *mapping) error {
+	if len(id) != uuidLength {
+		return fmt.Errorf("%s fetch by id length %d", r.Name, len(id))
+	}
+
+	stmt := fmt.Sprintf(`SELECT %s FROM %s WHERE id = ?`, m.columns.names(), r.Table)
+	ctx, span := startStatement(ctx, r.Name+".fetch_by_id", stmt)
+	defer span.End()
+
+	err := r.DB.QueryRowContext(ctx, stmt, id).Scan(m.columns.targets(v)...)
+	if err == nil {
+		span.SetAttribute(attrRowsReturned, 1)
+	}
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return r.NoEntity
+	case err != nil:
+		span.SetError(err)
+		return fmt.Errorf("%s fetch query %w", r.Name, translate(err))
+	case m.entity(v).DeletedAt.Valid:
+		return r.DeletedEntity
+	default:
+		return nil
+	}
+}
+
+// List will read the entities that have not been deleted into the entities,
+// a pointer to a slice of pointers to a struct embedding model.Entity
+func (r *Repository) List(ctx context.Context, entities interface{}) error {
+	defer r.observe("list", time.Now())
+
+	s := reflect.ValueOf(entities)
+	if s.Kind() != reflect.Ptr || s.Elem().Kind() != reflect.Slice || s.Elem().Type().Elem().Kind() != reflect.Ptr {
+		return fmt.Errorf("%s list requires a pointer to a slice of entity pointers, not %T", r.Name, entities)
+	}
+	s = s.Elem()
+	t := s.Type().Elem().Elem()
+	m, err := mappingOf(t)
+	if err != nil {
+		return err
+	}
+	if m.entityIndex == nil {
+		return fmt.Errorf("entity %s does not embed model.Entity", t)
+	}
+
+	order := r.Order
+	if len(order) == 0 {
+		order = "created_at"
+	}
+	stmt := fmt.Sprintf(`SELECT %s FROM %s WHERE deleted_at IS NULL ORDER BY %s`, m.columns.names(), r.Table, order)
+	ctx, span := startStatement(ctx, r.Name+".list", stmt)
+	defer span.End()
+
+	rows, err := r.DB.QueryContext(ctx, stmt)
+	if err != nil {
+		span.SetError(err)
+		return fmt.Errorf("%s list query %w", r.Name, translate(err))
+	}
+	defer rows.Close()
+
+	list := reflect.MakeSlice(s.Type(), 0, 0)
+	for rows.Next() {
+		e := reflect.New(t)
+		if err := rows.Scan(m.columns.targets(e.Elem())...); err != nil {
+			span.SetError(err)
+			return fmt.Errorf("%s row scan error %w", r.Name, translate(err))
+		}
+		list = reflect.Append(list, e)
+	}
+	if err := rows.Err(); err != nil {
+		span.SetError(err)
+		return fmt.Errorf("%s rows error %w", r.Name, translate(err))
+	}
+	span.SetAttribute(attrRowsReturned, list.Len())
+	s.Set(list)
+	return nil
+}
+
+// Update will change the entity with the id by the fields of the patch that are not empty and read
+// the updated entity into the entity.  The patch is a struct, or a pointer to one, such as the model
+// the entity embeds; its fields are matched to the entity columns by name.
+func (r *Repository) Update(ctx context.Context, id string, patch, entity interface{}) error {
+	defer r.observe("update", time.Now())
+
+	v, m, err := entityValue(entity)
+	if err != nil {
+		return err
+	}
+	p := reflect.Indirect(reflect.ValueOf(patch))
+	if p.Kind() != reflect.Struct {
+		return fmt.Errorf("%s update patch must be a struct, not %T", r.Name, patch)
+	}
+	pm, err := mappingOf(p.Type())
+	if err != nil {
+		return err
+	}
+
+	if err := r.fetch(ctx, id, v, m); err != nil {
+		return err
+	}
+	cols := m.changeable()
+	for _, pc := range pm.columns {
+		c, has := cols.column(pc.name)
+		if has == false {
+			continue
+		}
+		field := p.FieldByIndex(pc.index)
+		if field.IsZero() {
+			continue
+		}
+		target := v.FieldByIndex(c.index)
+		if field.Type().AssignableTo(target.Type()) == false {
+			return fmt.Errorf("%s update patch %s is %s, not %s", r.Name, pc.name, field.Type(), target.Type())
+		}
+		target.Set(field)
+	}
+	m.entity(v).UpdatedAt = time.Now()
+
+	sets := make([]string, 0, len(cols))
+	for _, c := range cols {
+		sets = append(sets, c.name+" = ?")
+	}
+	stmt := fmt.Sprintf(`UPDATE %s SET %s, updated_at = CURRENT_TIMESTAMP WHERE id = ?`, r.Table, strings.Join(sets, ", "))
+	if _, err := execContext(ctx, r.DB, r.Name+".update", stmt, append(cols.values(v), id)...); err != nil {
+		return err
+	}
+	logx.FromContext(ctx).Debug(r.Name+" updated", logx.Fields{"id": id})
+	return nil
+}
+
+// SoftDelete will mark the entity with the id as deleted
+func (r *Repository) SoftDelete(ctx context.Context, id string) error {
+	defer r.observe("delete", time.Now())
+
+	if len(id) != uuidLength {
+		return fmt.Errorf("%s fetch by id length %d", r.Name, len(id))
+	}
+
+	query := fmt.Sprintf(`SELECT deleted_at FROM %s WHERE id = ?`, r.Table)
+	qctx, span := startStatement(ctx, r.Name+".active", query)
+	deletedAt := sql.NullTime{}
+	err := r.DB.QueryRowContext(qctx, query, id).Scan(&deletedAt)
+	span.End()
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return r.NoEntity
+	case err != nil:
+		return fmt.Errorf("%s fetch query %w", r.Name, translate(err))
+	case deletedAt.Valid:
+		return r.DeletedEntity
+	}
+
+	stmt := fmt.Sprintf(`UPDATE %s SET deleted_at = CURRENT_TIMESTAMP WHERE id = ?`, r.Table)
+	if _, err := execContext(ctx, r.DB, r.Name+".delete", stmt, id); err != nil {
+		return err
+	}
+	logx.FromContext(ctx).Debug(r.Name+" deleted", logx.Fields{"id": id})
+	return nil
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+var entityType = reflect.TypeOf(model.Entity{})
+
+// column is a struct field stored in a column
+type column struct {
+	name      string
+	index     []int
+	nullEmpty bool
+	// entity is a column of the embedded model.Entity
+	entity bool
+}
+
+type columns []column
+
+func (cs columns) names() string {
+	names := make([]string, 0, len(cs))
+	for _, c := range cs {
+		names = append(names, c.name)
+	}
+	return strings.Join(names, ", ")
+}
+
+func (cs columns) column(name string) (column, bool) {
+	for _, c := range cs {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return column{}, false
+}
+
+// values returns the arguments of the columns
+func (cs columns) values(v reflect.Value) []interface{} {
+	values := make([]interface{}, 0, len(cs))
+	for _, c := range cs {
+		field := v.FieldByIndex(c.index)
+		if c.nullEmpty && field.IsZero() {
+			values = append(values, nil)
+			continue
+		}
+		values = append(values, field.Interface())
+	}
+	return values
+}
+
+// targets returns the scan targets of the columns
+func (cs columns) targets(v reflect.Value) []interface{} {
+	targets := make([]interface{}, 0, len(cs))
+	for _, c := range cs {
+		field := v.FieldByIndex(c.index)
+		if c.nullEmpty {
+			targets = append(targets, &nullable{v: field})
+			continue
+		}
+		targets = append(targets, field.Addr().Interface())
+	}
+	return targets
+}
+
+// nullable scans a nullable column into a field, null is the empty value of the field
+type nullable struct {
+	v reflect.Value
+}
+
+func (n *nullable) Scan(src interface{}) error {
+	if src == nil {
+		n.v.Set(reflect.Zero(n.v.Type()))
+		return nil
+	}
+	ns := sql.NullString{}
+	if err := ns.Scan(src); err != nil {
+		return err
+	}
+	n.v.SetString(ns.String)
+	return nil
+}
+
+// mapping is the columns of an entity type
+type mapping struct {
+	columns columns
+	// entityIndex is the index of the embedded model.Entity, nil when there is none
+	entityIndex []int
+}
+
+func (m *mapping) entity(v reflect.Value) *model.Entity {
+	return v.FieldByIndex(m.entityIndex).Addr().Interface().(*model.Entity)
+}
+
+// stored returns the columns that are written on create, deleted at is only written by a soft delete
+func (m *mapping) stored() columns {
+	cs := columns{}
+	for _, c := range m.columns {
+		if c.name != deletedAtColumn {
+			cs = append(cs, c)
+		}
+	}
+	return cs
+}
+
+// changeable returns the columns that are written on update
+func (m *mapping) changeable() columns {
+	cs := columns{}
+	for _, c := range m.columns {
+		if c.entity == false {
+			cs = append(cs, c)
+		}
+	}
+	return cs
+}
+
+var mappings sync.Map
+
+// entityValue returns the struct the entity points to and its mapping
+func entityValue(entity interface{}) (reflect.Value, *mapping, error) {
+	v := reflect.ValueOf(entity)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, nil, fmt.Errorf("entity must be a pointer to a struct, not %T", entity)
+	}
+	v = v.Elem()
+	m, err := mappingOf(v.Type())
+	if err != nil {
+		return reflect.Value{}, nil, err
+	}
+	if m.entityIndex == nil {
+		return reflect.Value{}, nil, fmt.Errorf("entity %s does not embed model.Entity", v.Type())
+	}
+	return v, m, nil
+}
+
+// mappingOf returns the mapping of the struct type, mappings are cached by type
+func mappingOf(t reflect.Type) (*mapping, error) {
+	if m, ok := mappings.Load(t); ok {
+		return m.(*mapping), nil
+	}
+	m := &mapping{}
+	if err := m.add(t, nil, false); err != nil {
+		return nil, err
+	}
+	mappings.Store(t, m)
+	return m, nil
+}
+
+// add adds the columns of the struct fields, embedded structs without a db tag are flattened
+func (m *mapping) add(t reflect.Type, index []int, entity bool) error {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		fieldIndex := append(append([]int{}, index...), i)
+		tag, hasTag := f.Tag.Lookup("db")
+		// the exported fields of an embedded struct are promoted even when its type is not exported
+		if f.Anonymous && hasTag == false && f.Type.Kind() == reflect.Struct {
+			isEntity := f.Type == entityType
+			if isEntity {
+				m.entityIndex = fieldIndex
+			}
+			if err := m.add(f.Type, fieldIndex, entity || isEntity); err != nil {
+				return err
+			}
+			continue
+		}
+		if len(f.PkgPath) > 0 {
+			continue
+		}
+
+		name, opts := tag, ""
+		if comma := strings.Index(tag, ","); comma >= 0 {
+			name, opts = tag[:comma], tag[comma+1:]
+		}
+		if len(name) == 0 {
+			name = strings.Split(f.Tag.Get("json"), ",")[0]
+		}
+		if name == "-" || len(name) == 0 {
+			continue
+		}
+		c := column{
+			name:      name,
+			index:     fieldIndex,
+			nullEmpty: opts == "nullempty",
+			entity:    entity,
+		}
+		if c.nullEmpty && f.Type.Kind() != reflect.String {
+			return fmt.Errorf("column %s of %s is nullempty but not a string", name, t)
+		}
+		if _, dup := m.columns.column(name); dup {
+			return fmt.Errorf("column %s of %s is mapped more than once", name, t)
+		}
+		m.columns = append(m.columns, c)
+	}
+	return nil
+}
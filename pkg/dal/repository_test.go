@@ -0,0 +1,184 @@
+package dal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/g8rswimmer/go-data-access-example/pkg/model"
+)
+
+// gadget is an entity that only has a model and a table
+type gadget struct {
+	Name   string `json:"name"`
+	Serial string `json:"serial" db:"serial_number,nullempty"`
+	Weight int    `json:"weight"`
+	Note   string `json:"note" db:"-"`
+}
+
+type gadgetEntity struct {
+	model.Entity
+	gadget
+}
+
+const gadgetTable = `
+CREATE TABLE gadget (
+	id CHAR(36) NOT NULL,
+	name VARCHAR(100) NOT NULL,
+	serial_number VARCHAR(36) UNIQUE,
+	weight INTEGER NOT NULL DEFAULT 0,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	deleted_at DATETIME,
+	PRIMARY KEY (id)
+);
+`
+
+var (
+	errNoGadget     = errors.New("gadget is not present")
+	errDeleteGadget = errors.New("gadget has been deleted")
+)
+
+func testRepository() *Repository {
+	next := 0
+	return &Repository{
+		DB: setupDB([]string{gadgetTable}),
+		GenerateUUID: func() string {
+			next++
+			return fmt.Sprintf("%036d", next)
+		},
+		Name:          "gadget",
+		Table:         "gadget",
+		Order:         "name",
+		NoEntity:      errNoGadget,
+		DeletedEntity: errDeleteGadget,
+	}
+}
+
+func TestRepository(t *testing.T) {
+	r := testRepository()
+	defer r.DB.Close()
+	ctx := context.Background()
+
+	for _, g := range []gadget{{Name: "widget", Weight: 2}, {Name: "sprocket", Serial: "s-1", Note: "not stored"}} {
+		e := &gadgetEntity{gadget: g}
+		if err := r.Create(ctx, e); err != nil {
+			t.Errorf("Repository.Create() error = %v", err)
+			return
+		}
+		if len(e.ID) != uuidLength || e.CreatedAt.IsZero() {
+			t.Errorf("Repository.Create() entity = %v", e.Entity)
+		}
+	}
+
+	got := &gadgetEntity{}
+	if err := r.FetchByID(ctx, fmt.Sprintf("%036d", 2), got); err != nil {
+		t.Errorf("Repository.FetchByID() error = %v", err)
+		return
+	}
+	if want := (gadget{Name: "sprocket", Serial: "s-1"}); reflect.DeepEqual(got.gadget, want) == false {
+		t.Errorf("Repository.FetchByID() = %v, want %v", got.gadget, want)
+	}
+
+	updated := &gadgetEntity{}
+	if err := r.Update(ctx, fmt.Sprintf("%036d", 1), &gadget{Serial: "w-1", Weight: 3}, updated); err != nil {
+		t.Errorf("Repository.Update() error = %v", err)
+		return
+	}
+	if want := (gadget{Name: "widget", Serial: "w-1", Weight: 3}); reflect.DeepEqual(updated.gadget, want) == false {
+		t.Errorf("Repository.Update() = %v, want %v", updated.gadget, want)
+	}
+
+	list := []*gadgetEntity{}
+	if err := r.List(ctx, &list); err != nil {
+		t.Errorf("Repository.List() error = %v", err)
+		return
+	}
+	if len(list) != 2 || list[0].Name != "sprocket" || list[1].Serial != "w-1" {
+		t.Errorf("Repository.List() = %v", list)
+	}
+
+	if err := r.SoftDelete(ctx, fmt.Sprintf("%036d", 2)); err != nil {
+		t.Errorf("Repository.SoftDelete() error = %v", err)
+		return
+	}
+	if err := r.List(ctx, &list); err != nil || len(list) != 1 {
+		t.Errorf("Repository.List() = %v, %v want one gadget", list, err)
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		fn   func() error
+	}{
+		{
+			name: "fetch deleted",
+			err:  errDeleteGadget,
+			fn:   func() error { return r.FetchByID(ctx, fmt.Sprintf("%036d", 2), &gadgetEntity{}) },
+		},
+		{
+			name: "update deleted",
+			err:  errDeleteGadget,
+			fn:   func() error { return r.Update(ctx, fmt.Sprintf("%036d", 2), gadget{Weight: 1}, &gadgetEntity{}) },
+		},
+		{
+			name: "delete deleted",
+			err:  errDeleteGadget,
+			fn:   func() error { return r.SoftDelete(ctx, fmt.Sprintf("%036d", 2)) },
+		},
+		{
+			name: "fetch missing",
+			err:  errNoGadget,
+			fn:   func() error { return r.FetchByID(ctx, fmt.Sprintf("%036d", 9), &gadgetEntity{}) },
+		},
+		{
+			name: "delete missing",
+			err:  errNoGadget,
+			fn:   func() error { return r.SoftDelete(ctx, fmt.Sprintf("%036d", 9)) },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.fn(); errors.Is(err, tt.err) == false {
+				t.Errorf("Repository error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestRepository_Mapping(t *testing.T) {
+	r := testRepository()
+	defer r.DB.Close()
+	ctx := context.Background()
+
+	type duplicate struct {
+		model.Entity
+		Name  string `json:"name"`
+		Other string `db:"name"`
+	}
+	type nullInt struct {
+		model.Entity
+		Weight int `db:"weight,nullempty"`
+	}
+	tests := []struct {
+		name   string
+		entity interface{}
+	}{
+		{name: "not a pointer", entity: gadgetEntity{}},
+		{name: "without model.Entity", entity: &gadget{}},
+		{name: "duplicate column", entity: &duplicate{}},
+		{name: "nullempty not a string", entity: &nullInt{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := r.Create(ctx, tt.entity); err == nil {
+				t.Errorf("Repository.Create() error = nil, want an error")
+			}
+		})
+	}
+	if err := r.List(ctx, &[]gadgetEntity{}); err == nil {
+		t.Errorf("Repository.List() error = nil, want an error")
+	}
+}
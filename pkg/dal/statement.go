@@ -0,0 +1,47 @@
+package dal
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/g8rswimmer/go-data-access-example/pkg/trace"
+)
+
+const (
+	dbSystem          = "sqlite"
+	attrDBSystem      = "db.system"
+	attrDBStatement   = "db.statement"
+	attrRowsAffected  = "db.rows_affected"
+	attrRowsReturned  = "db.rows_returned"
+	statementSpanName = "dal."
+)
+
+// queryer runs the statements on a database or within a transaction
+type queryer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// startStatement starts a span for a single sql statement
+func startStatement(ctx context.Context, name, stmt string) (context.Context, *trace.Span) {
+	ctx, span := trace.Start(ctx, statementSpanName+name)
+	span.SetAttribute(attrDBSystem, dbSystem)
+	span.SetAttribute(attrDBStatement, stmt)
+	return ctx, span
+}
+
+// execContext executes the statement within a span recording the rows affected, errors are classified
+func execContext(ctx context.Context, db queryer, name, stmt string, args ...interface{}) (sql.Result, error) {
+	ctx, span := startStatement(ctx, name, stmt)
+	defer span.End()
+
+	result, err := db.ExecContext(ctx, stmt, args...)
+	if err != nil {
+		span.SetError(err)
+		return nil, translate(err)
+	}
+	if n, err := result.RowsAffected(); err == nil {
+		span.SetAttribute(attrRowsAffected, n)
+	}
+	return result, nil
+}
@@ -12,3 +12,84 @@ CREATE TABLE IF NOT EXISTS user (
 	PRIMARY KEY (id)
 )
 `
+
+// UserProfileColumns adds the profile columns to the user table, the email is unique without regard to case
+const UserProfileColumns = `
+ALTER TABLE user ADD COLUMN email VARCHAR(254) COLLATE NOCASE;
+ALTER TABLE user ADD COLUMN username VARCHAR(64);
+ALTER TABLE user ADD COLUMN phone VARCHAR(16);
+ALTER TABLE user ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'active';
+ALTER TABLE user ADD COLUMN locale VARCHAR(35);
+ALTER TABLE user ADD COLUMN timezone VARCHAR(64);
+ALTER TABLE user ADD COLUMN attributes TEXT;
+CREATE UNIQUE INDEX user_email ON user (email);
+CREATE UNIQUE INDEX user_username ON user (username);
+`
+
+// GroupTable defines the group table for the dal, named user_group as group is a keyword.
+// Group names are unique without regard to case.
+const GroupTable = `
+CREATE TABLE IF NOT EXISTS user_group (
+	id CHAR(36) NOT NULL,
+	name VARCHAR(100) NOT NULL COLLATE NOCASE,
+	description TEXT NOT NULL DEFAULT '',
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	deleted_at DATETIME,
+	PRIMARY KEY (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS user_group_name ON user_group (name);
+`
+
+// GroupMemberTable defines the membership of users in groups
+const GroupMemberTable = `
+CREATE TABLE IF NOT EXISTS group_member (
+	group_id CHAR(36) NOT NULL REFERENCES user_group (id),
+	user_id CHAR(36) NOT NULL REFERENCES user (id),
+	role VARCHAR(16) NOT NULL DEFAULT 'member',
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (group_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS group_member_user ON group_member (user_id);
+`
+
+// OrganizationTable defines the organization tree, a root has no parent
+const OrganizationTable = `
+CREATE TABLE IF NOT EXISTS organization (
+	id CHAR(36) NOT NULL,
+	name VARCHAR(100) NOT NULL,
+	kind VARCHAR(16) NOT NULL,
+	parent_id CHAR(36) REFERENCES organization (id),
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	deleted_at DATETIME,
+	PRIMARY KEY (id)
+);
+CREATE INDEX IF NOT EXISTS organization_parent ON organization (parent_id);
+`
+
+// OrganizationUserTable places each user in at most one organization
+const OrganizationUserTable = `
+CREATE TABLE IF NOT EXISTS organization_user (
+	user_id CHAR(36) NOT NULL REFERENCES user (id),
+	organization_id CHAR(36) NOT NULL REFERENCES organization (id),
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (user_id)
+);
+CREATE INDEX IF NOT EXISTS organization_user_organization ON organization_user (organization_id);
+`
+
+// UserManagerColumn adds the manager an user reports to
+const UserManagerColumn = `
+ALTER TABLE user ADD COLUMN manager_id CHAR(36) REFERENCES user (id);
+CREATE INDEX IF NOT EXISTS user_manager ON user (manager_id);
+`
+
+// GroupNameIndex releases the name of a deleted group, the names are unique among the groups that have not been deleted
+const GroupNameIndex = `
+DROP INDEX IF EXISTS user_group_name;
+CREATE UNIQUE INDEX user_group_name ON user_group (name) WHERE deleted_at IS NULL;
+`
+
+// Tables are the statements that create the dal tables in the order they are applied
+var Tables = []string{UserTable, UserProfileColumns, GroupTable, GroupMemberTable, OrganizationTable, OrganizationUserTable, UserManagerColumn, GroupNameIndex}
@@ -5,9 +5,12 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/g8rswimmer/go-data-access-example/pkg/errorx"
+	"github.com/g8rswimmer/go-data-access-example/pkg/logx"
+	"github.com/g8rswimmer/go-data-access-example/pkg/metrics"
 	"github.com/g8rswimmer/go-data-access-example/pkg/model"
 )
 
@@ -15,12 +18,20 @@ const uuidLength = 36
 
 // User handles all of the database actions
 type User struct {
-	DB           *sql.DB
-	GenerateUUID GenerateUUID
+	DB            *sql.DB
+	GenerateUUID  GenerateUUID
+	QueryDuration *metrics.HistogramVec
+}
+
+// observe records the duration of a user method labeled by entity and method
+func (u *User) observe(method string, start time.Time) {
+	u.QueryDuration.Observe(time.Since(start).Seconds(), "user", method)
 }
 
 // Create will insert a user into the database
 func (u *User) Create(ctx context.Context, user *model.User) (*model.UserEntity, error) {
+	defer u.observe("create", time.Now())
+
 	if user == nil {
 		return nil, errors.New("user can not be nil")
 	}
@@ -33,35 +44,57 @@ func (u *User) Create(ctx context.Context, user *model.User) (*model.UserEntity,
 			CreatedAt: now,
 			UpdatedAt: now,
 		},
-		User: model.User{
-			FirstName: user.FirstName,
-			LastName:  user.LastName,
-		},
+		User: *user,
+	}
+	if len(e.Status) == 0 {
+		e.Status = model.UserActive
+	}
+	if len(e.ManagerID) > 0 {
+		if err := activeManager(ctx, u.DB, e.ManagerID); err != nil {
+			return nil, err
+		}
 	}
 
-	const stmt = `INSERT INTO user (id, first_name, last_name, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`
-	if _, err := u.DB.ExecContext(ctx, stmt, e.ID, e.FirstName, e.LastName, e.CreatedAt, e.UpdatedAt); err != nil {
-		return nil, fmt.Errorf("user create insert %w", err)
+	const stmt = `INSERT INTO user (id, first_name, last_name, email, username, phone, status, locale, timezone, attributes, manager_id, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := execContext(ctx, u.DB, "user.create", stmt,
+		e.ID, e.FirstName, e.LastName, nullIfEmpty(e.Email), nullIfEmpty(e.Username), nullIfEmpty(e.Phone),
+		e.Status, nullIfEmpty(e.Locale), nullIfEmpty(e.Timezone), e.Attributes, nullIfEmpty(e.ManagerID), e.CreatedAt, e.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("user create insert %w", userConflict(err))
 	}
+	logx.FromContext(ctx).Debug("user created", logx.Fields{"id": e.ID})
 	return e, nil
 }
 
-// FetchByID returns an entity by the id
-func (u *User) FetchByID(ctx context.Context, id string) (*model.UserEntity, error) {
+// FetchByID returns an entity by the id, only the fields are selected when present
+func (u *User) FetchByID(ctx context.Context, id string, fields ...string) (*model.UserEntity, error) {
+	defer u.observe("fetch_by_id", time.Now())
+
 	if len(id) != uuidLength {
 		return nil, fmt.Errorf("user fetch by id length %d", len(id))
 	}
+	p, err := userProjection(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := `SELECT ` + p.columns() + ` FROM user WHERE id = ?`
+	ctx, span := startStatement(ctx, "user.fetch_by_id", stmt)
+	defer span.End()
 
-	const stmt = `SELECT id, first_name, last_name, created_at, updated_at, deleted_at FROM user WHERE id = ?`
 	row := u.DB.QueryRowContext(ctx, stmt, id)
 
 	e := &model.UserEntity{}
-	err := row.Scan(&e.ID, &e.FirstName, &e.LastName, &e.CreatedAt, &e.UpdatedAt, &e.DeletedAt)
+	err = row.Scan(p.targets(e)...)
+	if err == nil {
+		span.SetAttribute(attrRowsReturned, 1)
+	}
 	switch {
 	case errors.Is(err, sql.ErrNoRows):
 		return nil, errorx.ErrNoUser
 	case err != nil:
-		return nil, fmt.Errorf("user fetch query %w", err)
+		span.SetError(err)
+		return nil, fmt.Errorf("user fetch query %w", translate(err))
 	case e.DeletedAt.Valid:
 		return nil, errorx.ErrDeleteUser
 	default:
@@ -70,37 +103,155 @@ func (u *User) FetchByID(ctx context.Context, id string) (*model.UserEntity, err
 
 }
 
-// FetchAll returns all entities
-func (u *User) FetchAll(ctx context.Context) ([]*model.UserEntity, error) {
+// FetchAll returns all entities, only the fields are selected when present
+func (u *User) FetchAll(ctx context.Context, fields ...string) ([]*model.UserEntity, error) {
+	defer u.observe("fetch_all", time.Now())
 
-	const stmt = `SELECT id, first_name, last_name, created_at, updated_at, deleted_at FROM user`
-	rows, err := u.DB.QueryContext(ctx, stmt)
-	switch {
-	case errors.Is(err, sql.ErrNoRows):
-		return nil, errorx.ErrNoUser
-	case err != nil:
-		return nil, fmt.Errorf("user fetch query %w", err)
-	default:
+	entities, _, err := u.search(ctx, "user.fetch_all", &model.UserSearch{}, fields)
+	return entities, err
+}
+
+// Search returns a page of the entities that match the search and the cursor of the next page,
+// the cursor is empty on the last page.  Only the fields are selected when present.
+func (u *User) Search(ctx context.Context, search *model.UserSearch, fields ...string) ([]*model.UserEntity, string, error) {
+	defer u.observe("search", time.Now())
+
+	if search == nil {
+		return nil, "", errors.New("user search can not be nil")
+	}
+	return u.search(ctx, "user.search", search, fields)
+}
+
+// userOrder is the order of the users, the id makes it unique for the cursor
+var userOrder = []Order{{Column: "created_at", Time: true}, Asc("id")}
+
+func (u *User) search(ctx context.Context, name string, search *model.UserSearch, fields []string) ([]*model.UserEntity, string, error) {
+	p, err := userProjection(fields)
+	if err != nil {
+		return nil, "", err
+	}
+	// the order columns are selected for the cursor of the next page
+	p = p.with("created_at", "id")
+
+	predicates, err := userPredicates(search)
+	if err != nil {
+		return nil, "", err
+	}
+	q := Select(p...).From("user").Where(predicates...).OrderBy(userOrder...)
+	if len(search.Cursor) > 0 {
+		after, err := decodeCursor(search.Cursor, len(userOrder))
+		if err != nil {
+			return nil, "", err
+		}
+		q.After(after...)
+	}
+	if search.Limit > 0 {
+		// one more user is selected to know if there is a next page
+		q.Limit(search.Limit + 1)
+	}
+	stmt, args, err := q.Build(SQLite)
+	if err != nil {
+		return nil, "", err
+	}
+
+	ctx, span := startStatement(ctx, name, stmt)
+	defer span.End()
+
+	rows, err := u.DB.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		span.SetError(err)
+		return nil, "", fmt.Errorf("user fetch query %w", translate(err))
 	}
 	defer rows.Close()
 
 	entities := []*model.UserEntity{}
 	for rows.Next() {
 		e := &model.UserEntity{}
-		deletedAt := sql.NullTime{}
-		if err := rows.Scan(&e.ID, &e.FirstName, &e.LastName, &e.CreatedAt, &e.UpdatedAt, &deletedAt); err != nil {
-			return nil, fmt.Errorf("user row scan error %w", err)
-		}
-		if deletedAt.Valid == false {
-			entities = append(entities, e)
+		if err := rows.Scan(p.targets(e)...); err != nil {
+			span.SetError(err)
+			return nil, "", fmt.Errorf("user row scan error %w", translate(err))
 		}
+		entities = append(entities, e)
 	}
+	if err := rows.Err(); err != nil {
+		span.SetError(err)
+		return nil, "", fmt.Errorf("user rows error %w", translate(err))
+	}
+	span.SetAttribute(attrRowsReturned, len(entities))
 
-	return entities, nil
+	if search.Limit == 0 || len(entities) <= search.Limit {
+		return entities, "", nil
+	}
+	entities = entities[:search.Limit]
+	last := entities[len(entities)-1]
+	next, err := encodeCursor(last.CreatedAt, last.ID)
+	if err != nil {
+		return nil, "", err
+	}
+	return entities, next, nil
+}
+
+// userFilterFields are the fields of an user search filter, the json names of the user entity fields
+var userFilterFields = map[string]filterField{
+	"id":         {column: "id"},
+	"first_name": {column: "first_name"},
+	"last_name":  {column: "last_name"},
+	"email":      {column: "email", nullable: true},
+	"username":   {column: "username", nullable: true},
+	"phone":      {column: "phone", nullable: true},
+	"status":     {column: "status"},
+	"locale":     {column: "locale", nullable: true},
+	"timezone":   {column: "timezone", nullable: true},
+	"manager_id": {column: "manager_id", nullable: true},
+	"created_at": {column: "created_at", kind: filterTime},
+	"updated_at": {column: "updated_at", kind: filterTime},
+}
+
+// userPredicates returns the predicates of the search, deleted users are never selected
+func userPredicates(search *model.UserSearch) ([]Predicate, error) {
+	predicates := []Predicate{IsNull(deletedAtColumn)}
+	if len(search.Status) > 0 {
+		predicates = append(predicates, Eq("status", string(search.Status)))
+	}
+	if len(search.LastName) > 0 {
+		predicates = append(predicates, Eq("last_name", search.LastName))
+	}
+	if len(search.Email) > 0 {
+		predicates = append(predicates, Eq("email", search.Email))
+	}
+	if len(search.Username) > 0 {
+		predicates = append(predicates, Eq("username", search.Username))
+	}
+	if len(search.Text) > 0 {
+		predicates = append(predicates, Or(
+			Contains("first_name", search.Text),
+			Contains("last_name", search.Text),
+			Contains("email", search.Text),
+			Contains("username", search.Text),
+		))
+	}
+	var from, to interface{}
+	if search.CreatedAfter.IsZero() == false {
+		from = search.CreatedAfter
+	}
+	if search.CreatedBefore.IsZero() == false {
+		to = search.CreatedBefore
+	}
+	predicates = append(predicates, Range("created_at", from, to))
+	if len(strings.TrimSpace(search.Filter)) > 0 {
+		filter, err := parseFilter(search.Filter, userFilterFields)
+		if err != nil {
+			return nil, err
+		}
+		predicates = append(predicates, filter)
+	}
+	return predicates, nil
 }
 
 // Update will update an entity with new information
 func (u *User) Update(ctx context.Context, id string, user *model.User) (*model.UserEntity, error) {
+	defer u.observe("update", time.Now())
+
 	switch {
 	case len(id) != uuidLength:
 		return nil, fmt.Errorf("user fetch by id length %d", len(id))
@@ -120,17 +271,94 @@ func (u *User) Update(ctx context.Context, id string, user *model.User) (*model.
 	if len(user.LastName) > 0 {
 		e.LastName = user.LastName
 	}
+	if len(user.Email) > 0 {
+		e.Email = user.Email
+	}
+	if len(user.Username) > 0 {
+		e.Username = user.Username
+	}
+	if len(user.Phone) > 0 {
+		e.Phone = user.Phone
+	}
+	if len(user.Status) > 0 {
+		e.Status = user.Status
+	}
+	if len(user.Locale) > 0 {
+		e.Locale = user.Locale
+	}
+	if len(user.Timezone) > 0 {
+		e.Timezone = user.Timezone
+	}
+	if user.Attributes != nil {
+		e.Attributes = user.Attributes
+	}
+	manager := len(user.ManagerID) > 0 && user.ManagerID != e.ManagerID
+	if manager {
+		e.ManagerID = user.ManagerID
+	}
 	e.UpdatedAt = time.Now()
 
-	const stmt = `UPDATE user SET first_name = ?, last_name = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`
-	if _, err := u.DB.ExecContext(ctx, stmt, e.FirstName, e.LastName, id); err != nil {
+	stmt, args, err := Update("user").
+		Set("first_name", e.FirstName).
+		Set("last_name", e.LastName).
+		Set("email", nullIfEmpty(e.Email)).
+		Set("username", nullIfEmpty(e.Username)).
+		Set("phone", nullIfEmpty(e.Phone)).
+		Set("status", e.Status).
+		Set("locale", nullIfEmpty(e.Locale)).
+		Set("timezone", nullIfEmpty(e.Timezone)).
+		Set("attributes", e.Attributes).
+		Set("manager_id", nullIfEmpty(e.ManagerID)).
+		SetNow("updated_at").
+		Where(Eq("id", id)).
+		Build(SQLite)
+	if err != nil {
 		return nil, err
 	}
+
+	// the manager is checked in the transaction of the update so a concurrent update can not create a cycle
+	tx, err := u.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("user update begin %w", translate(err))
+	}
+	defer tx.Rollback()
+
+	if manager {
+		if err := u.reportable(ctx, tx, id, e.ManagerID); err != nil {
+			return nil, err
+		}
+	}
+	_, err = execContext(ctx, tx, "user.update", stmt, args...)
+	if err != nil {
+		return nil, userConflict(err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("user update commit %w", translate(err))
+	}
+	logx.FromContext(ctx).Debug("user updated", logx.Fields{"id": id})
 	return e, nil
 }
 
+// RemoveManager will remove the manager the user reports to, a user without a manager is left as is
+func (u *User) RemoveManager(ctx context.Context, id string) error {
+	defer u.observe("remove_manager", time.Now())
+
+	if _, err := u.FetchByID(ctx, id, "id"); err != nil {
+		return err
+	}
+
+	const stmt = `UPDATE user SET manager_id = NULL, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND manager_id IS NOT NULL`
+	if _, err := execContext(ctx, u.DB, "user.remove_manager", stmt, id); err != nil {
+		return err
+	}
+	logx.FromContext(ctx).Debug("user manager removed", logx.Fields{"id": id})
+	return nil
+}
+
 // Delete will soft delete an entity
 func (u *User) Delete(ctx context.Context, id string) error {
+	defer u.observe("delete", time.Now())
+
 	if len(id) != uuidLength {
 		return fmt.Errorf("user fetch by id length %d", len(id))
 	}
@@ -141,9 +369,157 @@ func (u *User) Delete(ctx context.Context, id string) error {
 	}
 
 	const stmt = `UPDATE user SET deleted_at = CURRENT_TIMESTAMP WHERE id = ?`
-	if _, err := u.DB.ExecContext(ctx, stmt, id); err != nil {
+	if _, err := execContext(ctx, u.DB, "user.delete", stmt, id); err != nil {
+		return err
+	}
+	logx.FromContext(ctx).Debug("user deleted", logx.Fields{"id": id})
+	return nil
+
+}
+
+// maxReportDepth stops the reporting line queries, it also stops them should the line ever contain a cycle
+const maxReportDepth = 64
+
+// Reports returns the users that report to the user up to the depth of the reporting line, ordered by depth.
+// A depth outside of 1 to 64 returns all of the reports.  Deleted users and their reports are not returned.
+func (u *User) Reports(ctx context.Context, id string, depth int) ([]*model.UserNode, error) {
+	defer u.observe("reports", time.Now())
+
+	if _, err := u.FetchByID(ctx, id, "id"); err != nil {
+		return nil, err
+	}
+	if depth < 1 || depth > maxReportDepth {
+		depth = maxReportDepth
+	}
+	p, _ := userProjection(nil)
+
+	stmt := `WITH RECURSIVE report(report_id, depth) AS (
+		SELECT id, 1 FROM user WHERE manager_id = ? AND deleted_at IS NULL
+		UNION ALL
+		SELECT u.id, r.depth + 1 FROM user u JOIN report r ON u.manager_id = r.report_id
+		WHERE u.deleted_at IS NULL AND r.depth < ?
+	)
+	SELECT ` + p.columns() + `, depth FROM report JOIN user ON id = report_id
+	ORDER BY depth, last_name, first_name`
+	return u.nodes(ctx, "user.reports", stmt, p, id, depth)
+}
+
+// Chain returns the managers of the user from its manager to the top of the reporting line.
+// The line stops at a deleted manager.
+func (u *User) Chain(ctx context.Context, id string) ([]*model.UserNode, error) {
+	defer u.observe("chain", time.Now())
+
+	if _, err := u.FetchByID(ctx, id, "id"); err != nil {
+		return nil, err
+	}
+	p, _ := userProjection(nil)
+
+	stmt := `WITH RECURSIVE chain(manager, depth) AS (
+		SELECT manager_id, 1 FROM user WHERE id = ? AND manager_id IS NOT NULL
+		UNION ALL
+		SELECT u.manager_id, c.depth + 1 FROM user u JOIN chain c ON u.id = c.manager
+		WHERE u.deleted_at IS NULL AND u.manager_id IS NOT NULL AND c.depth < ?
+	)
+	SELECT ` + p.columns() + `, depth FROM chain JOIN user ON id = manager
+	WHERE deleted_at IS NULL ORDER BY depth`
+	return u.nodes(ctx, "user.chain", stmt, p, id, maxReportDepth)
+}
+
+// nodes returns the users of the statement, the last column is the depth
+func (u *User) nodes(ctx context.Context, name, stmt string, p projection, args ...interface{}) ([]*model.UserNode, error) {
+	ctx, span := startStatement(ctx, name, stmt)
+	defer span.End()
+
+	rows, err := u.DB.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		span.SetError(err)
+		return nil, fmt.Errorf("user query %w", translate(err))
+	}
+	defer rows.Close()
+
+	nodes := []*model.UserNode{}
+	for rows.Next() {
+		n := &model.UserNode{}
+		if err := rows.Scan(append(p.targets(&n.UserEntity), &n.Depth)...); err != nil {
+			span.SetError(err)
+			return nil, fmt.Errorf("user row scan error %w", translate(err))
+		}
+		nodes = append(nodes, n)
+	}
+	if err := rows.Err(); err != nil {
+		span.SetError(err)
+		return nil, fmt.Errorf("user rows error %w", translate(err))
+	}
+	span.SetAttribute(attrRowsReturned, len(nodes))
+	return nodes, nil
+}
+
+// reportable returns an error when the user can not report to the manager,
+// the manager must be active and can not be the user or one of its reports
+func (u *User) reportable(ctx context.Context, db queryer, id, managerID string) error {
+	if id == managerID {
+		return errorx.ErrManagerCycle
+	}
+	if err := activeManager(ctx, db, managerID); err != nil {
 		return err
 	}
+
+	// deleted users are followed so a line through them can not become a cycle
+	const stmt = `WITH RECURSIVE report(report_id, depth) AS (
+		SELECT id, 0 FROM user WHERE id = ?
+		UNION ALL
+		SELECT u.id, r.depth + 1 FROM user u JOIN report r ON u.manager_id = r.report_id
+		WHERE r.depth < ?
+	)
+	SELECT COUNT(*) FROM report WHERE report_id = ?`
+	ctx, span := startStatement(ctx, "user.manager_cycle", stmt)
+	defer span.End()
+
+	var within int
+	if err := db.QueryRowContext(ctx, stmt, id, maxReportDepth, managerID).Scan(&within); err != nil {
+		span.SetError(err)
+		return fmt.Errorf("user manager cycle query %w", translate(err))
+	}
+	if within > 0 {
+		return errorx.ErrManagerCycle
+	}
 	return nil
+}
+
+// activeManager returns an error when the manager does not exist or has been deleted
+func activeManager(ctx context.Context, db queryer, managerID string) error {
+	err := activeUser(ctx, db, managerID)
+	switch {
+	case errors.Is(err, errorx.ErrNoUser):
+		return errorx.ErrNoManager
+	case errors.Is(err, errorx.ErrDeleteUser):
+		return errorx.ErrDeleteManager
+	default:
+		return err
+	}
+}
 
+// activeUser returns an error when the user does not exist or has been deleted
+func activeUser(ctx context.Context, db queryer, userID string) error {
+	if len(userID) != uuidLength {
+		return fmt.Errorf("user fetch by id length %d", len(userID))
+	}
+
+	const stmt = `SELECT deleted_at FROM user WHERE id = ?`
+	ctx, span := startStatement(ctx, "user.active", stmt)
+	defer span.End()
+
+	deletedAt := sql.NullTime{}
+	err := db.QueryRowContext(ctx, stmt, userID).Scan(&deletedAt)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return errorx.ErrNoUser
+	case err != nil:
+		span.SetError(err)
+		return fmt.Errorf("user fetch query %w", translate(err))
+	case deletedAt.Valid:
+		return errorx.ErrDeleteUser
+	default:
+		return nil
+	}
 }
@@ -0,0 +1,187 @@
+package dal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/g8rswimmer/go-data-access-example/pkg/model"
+)
+
+// sqliteTime is the UTC time format of the SQLite date functions
+const sqliteTime = "2006-01-02 15:04:05"
+
+// Stats returns the counts of the users by status and deletion, the created histogram of the query
+// and the most common last names
+func (u *User) Stats(ctx context.Context, query *model.UserStatsQuery) (*model.UserStats, error) {
+	defer u.observe("stats", time.Now())
+
+	if query == nil {
+		return nil, errors.New("user stats query can not be nil")
+	}
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
+	stats := &model.UserStats{
+		Statuses: map[model.UserStatus]int{},
+		Interval: query.Interval,
+		Timezone: query.Location.String(),
+	}
+	if err := u.statusCounts(ctx, stats); err != nil {
+		return nil, err
+	}
+	if err := u.createdHistogram(ctx, query, stats); err != nil {
+		return nil, err
+	}
+	if err := u.lastNames(ctx, query.LastNames, stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// statusCounts counts the active users by status and the deleted users
+func (u *User) statusCounts(ctx context.Context, stats *model.UserStats) error {
+	const stmt = `SELECT status, deleted_at IS NOT NULL AS deleted, COUNT(*) FROM user GROUP BY status, deleted`
+	return u.aggregate(ctx, "user.stats_status", stmt, nil, func(scan func(...interface{}) error) error {
+		var status model.UserStatus
+		var deleted bool
+		var count int
+		if err := scan(&status, &deleted, &count); err != nil {
+			return err
+		}
+		if deleted {
+			stats.Deleted += count
+			return nil
+		}
+		stats.Active += count
+		stats.Statuses[status] += count
+		return nil
+	})
+}
+
+// createdHistogram counts the users by the bucket of their created time.  The users are grouped by their
+// local day, shifted by the offset of the time zone at their created time so a day is the local day across
+// daylight saving changes, and the days are summed into the buckets so that empty buckets are counted.
+func (u *User) createdHistogram(ctx context.Context, query *model.UserStatsQuery, stats *model.UserStats) error {
+	bounds := query.Buckets()
+	from, to := bounds[0], bounds[len(bounds)-1]
+
+	offsets := zoneOffsets(query.Location, from, to)
+	shift := strings.Builder{}
+	args := make([]interface{}, 0, 2*len(offsets)+1)
+	shift.WriteString("CASE")
+	for _, o := range offsets[:len(offsets)-1] {
+		shift.WriteString(" WHEN julianday(created_at) < julianday(?) THEN ?")
+		args = append(args, o.until.UTC().Format(sqliteTime), fmt.Sprintf("%+d seconds", o.offset))
+	}
+	shift.WriteString(" ELSE ? END")
+	args = append(args, fmt.Sprintf("%+d seconds", offsets[len(offsets)-1].offset), from.UTC().Format(sqliteTime), to.UTC().Format(sqliteTime))
+
+	stmt := `SELECT strftime('%Y-%m-%d', created_at, ` + shift.String() + `) AS day, COUNT(*) FROM user
+	WHERE julianday(created_at) >= julianday(?) AND julianday(created_at) < julianday(?)
+	GROUP BY day`
+	stats.Created = make([]model.UserStatsBucket, len(bounds)-1)
+	for i := range stats.Created {
+		stats.Created[i].Start = bounds[i]
+	}
+	return u.aggregate(ctx, "user.stats_created", stmt, args, func(scan func(...interface{}) error) error {
+		var day string
+		var count int
+		if err := scan(&day, &count); err != nil {
+			return err
+		}
+		local, err := time.ParseInLocation("2006-01-02", day, query.Location)
+		if err != nil {
+			return err
+		}
+		// the bucket is the last one that starts at or before the local day
+		n := sort.Search(len(bounds), func(i int) bool { return bounds[i].After(local) }) - 1
+		if n >= 0 && n < len(stats.Created) {
+			stats.Created[n].Count += count
+		}
+		return nil
+	})
+}
+
+// zoneOffset is the utc offset in seconds of a time zone until a transition
+type zoneOffset struct {
+	until  time.Time
+	offset int
+}
+
+// zoneOffsets returns the offsets of the location from the start to the end in order, the last offset holds
+// until the end.  The transitions are found to the second by a search within each day that the offset changes.
+func zoneOffsets(loc *time.Location, start, end time.Time) []zoneOffset {
+	offsetAt := func(t time.Time) int {
+		_, offset := t.In(loc).Zone()
+		return offset
+	}
+	offsets := []zoneOffset{}
+	current := offsetAt(start)
+	for t := start; t.Before(end); {
+		next := t.Add(24 * time.Hour)
+		if next.After(end) {
+			next = end
+		}
+		if offsetAt(next) == current {
+			t = next
+			continue
+		}
+		first := t.Unix()
+		i := sort.Search(int(next.Unix()-first), func(i int) bool { return offsetAt(time.Unix(first+int64(i), 0)) != current })
+		t = time.Unix(first+int64(i), 0)
+		offsets = append(offsets, zoneOffset{until: t, offset: current})
+		current = offsetAt(t)
+	}
+	return append(offsets, zoneOffset{until: end, offset: current})
+}
+
+// lastNames returns the most common last names of the active users, ties are ordered by name
+func (u *User) lastNames(ctx context.Context, top int, stats *model.UserStats) error {
+	stats.LastNames = []model.UserStatsLastName{}
+	if top == 0 {
+		return nil
+	}
+	const stmt = `SELECT last_name, COUNT(*) AS users FROM user WHERE deleted_at IS NULL AND last_name <> ''
+	GROUP BY last_name ORDER BY users DESC, last_name LIMIT ?`
+	return u.aggregate(ctx, "user.stats_last_names", stmt, []interface{}{top}, func(scan func(...interface{}) error) error {
+		name := model.UserStatsLastName{}
+		if err := scan(&name.LastName, &name.Count); err != nil {
+			return err
+		}
+		stats.LastNames = append(stats.LastNames, name)
+		return nil
+	})
+}
+
+// aggregate runs the statement and calls row for each of its rows
+func (u *User) aggregate(ctx context.Context, name, stmt string, args []interface{}, row func(scan func(...interface{}) error) error) error {
+	ctx, span := startStatement(ctx, name, stmt)
+	defer span.End()
+
+	rows, err := u.DB.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		span.SetError(err)
+		return fmt.Errorf("user stats query %w", translate(err))
+	}
+	defer rows.Close()
+
+	returned := 0
+	for rows.Next() {
+		if err := row(rows.Scan); err != nil {
+			span.SetError(err)
+			return fmt.Errorf("user stats scan error %w", translate(err))
+		}
+		returned++
+	}
+	if err := rows.Err(); err != nil {
+		span.SetError(err)
+		return fmt.Errorf("user stats rows error %w", translate(err))
+	}
+	span.SetAttribute(attrRowsReturned, returned)
+	return nil
+}
@@ -3,11 +3,17 @@ package dal
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"reflect"
 	"testing"
 	"time"
 
+	"github.com/g8rswimmer/go-data-access-example/pkg/errorx"
+	"github.com/g8rswimmer/go-data-access-example/pkg/metrics"
 	"github.com/g8rswimmer/go-data-access-example/pkg/model"
+	"github.com/g8rswimmer/go-data-access-example/pkg/trace"
+	"github.com/mattn/go-sqlite3"
 )
 
 func TestUser_Create(t *testing.T) {
@@ -28,7 +34,7 @@ func TestUser_Create(t *testing.T) {
 		{
 			name: "Create",
 			fields: fields{
-				DB: setupDB([]string{UserTable}),
+				DB: setupDB(Tables),
 				GenerateUUID: func() string {
 					return "1234"
 				},
@@ -46,6 +52,7 @@ func TestUser_Create(t *testing.T) {
 				User: model.User{
 					FirstName: "test",
 					LastName:  "one",
+					Status:    model.UserActive,
 				},
 			},
 		},
@@ -78,7 +85,8 @@ func TestUser_FetchByID(t *testing.T) {
 		GenerateUUID GenerateUUID
 	}
 	type args struct {
-		id string
+		id     string
+		fields []string
 	}
 	tests := []struct {
 		name    string
@@ -92,6 +100,8 @@ func TestUser_FetchByID(t *testing.T) {
 			fields: fields{
 				DB: setupDB([]string{
 					UserTable,
+					UserProfileColumns,
+					UserManagerColumn,
 					`INSERT INTO user (id, first_name, last_name) VALUES ('123456789012345678901234567890123456', 'test', 'one')`,
 				}),
 			},
@@ -105,9 +115,44 @@ func TestUser_FetchByID(t *testing.T) {
 				User: model.User{
 					FirstName: "test",
 					LastName:  "one",
+					Status:    model.UserActive,
 				},
 			},
 		},
+		{
+			name: "Fetch by id fields",
+			fields: fields{
+				DB: setupDB([]string{
+					UserTable,
+					UserProfileColumns,
+					UserManagerColumn,
+					`INSERT INTO user (id, first_name, last_name) VALUES ('123456789012345678901234567890123456', 'test', 'one')`,
+				}),
+			},
+			args: args{
+				id:     "123456789012345678901234567890123456",
+				fields: []string{"id", "last_name"},
+			},
+			want: &model.UserEntity{
+				Entity: model.Entity{
+					ID: "123456789012345678901234567890123456",
+				},
+				User: model.User{
+					LastName: "one",
+				},
+			},
+		},
+		{
+			name: "Fetch by id unknown field",
+			fields: fields{
+				DB: setupDB(Tables),
+			},
+			args: args{
+				id:     "123456789012345678901234567890123456",
+				fields: []string{"id", "password"},
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -117,11 +162,14 @@ func TestUser_FetchByID(t *testing.T) {
 			}
 			defer u.DB.Close()
 
-			got, err := u.FetchByID(context.Background(), tt.args.id)
+			got, err := u.FetchByID(context.Background(), tt.args.id, tt.args.fields...)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("User.FetchByID() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
+			if tt.wantErr {
+				return
+			}
 			got.CreatedAt = time.Time{}
 			got.UpdatedAt = time.Time{}
 			if !reflect.DeepEqual(got, tt.want) {
@@ -147,6 +195,8 @@ func TestUser_FetchAll(t *testing.T) {
 			fields: fields{
 				DB: setupDB([]string{
 					UserTable,
+					UserProfileColumns,
+					UserManagerColumn,
 					`INSERT INTO user (id, first_name, last_name) VALUES ('123456789012345678901234567890123456', 'test', 'one')`,
 					`INSERT INTO user (id, first_name, last_name) VALUES ('123456789012345678901234567890123457', 'test', 'two')`,
 				}),
@@ -159,6 +209,7 @@ func TestUser_FetchAll(t *testing.T) {
 					User: model.User{
 						FirstName: "test",
 						LastName:  "one",
+						Status:    model.UserActive,
 					},
 				},
 				{
@@ -168,6 +219,7 @@ func TestUser_FetchAll(t *testing.T) {
 					User: model.User{
 						FirstName: "test",
 						LastName:  "two",
+						Status:    model.UserActive,
 					},
 				},
 			},
@@ -219,6 +271,8 @@ func TestUser_Update(t *testing.T) {
 			fields: fields{
 				DB: setupDB([]string{
 					UserTable,
+					UserProfileColumns,
+					UserManagerColumn,
 					`INSERT INTO user (id, first_name, last_name) VALUES ('123456789012345678901234567890123456', 'test', 'one')`,
 				}),
 			},
@@ -236,6 +290,7 @@ func TestUser_Update(t *testing.T) {
 				User: model.User{
 					FirstName: "testy",
 					LastName:  "two",
+					Status:    model.UserActive,
 				},
 			},
 		},
@@ -296,6 +351,8 @@ func TestUser_Delete(t *testing.T) {
 			fields: fields{
 				DB: setupDB([]string{
 					UserTable,
+					UserProfileColumns,
+					UserManagerColumn,
 					`INSERT INTO user (id, first_name, last_name) VALUES ('123456789012345678901234567890123456', 'test', 'one')`,
 				}),
 			},
@@ -318,3 +375,549 @@ func TestUser_Delete(t *testing.T) {
 		})
 	}
 }
+
+func TestUser_QueryDuration(t *testing.T) {
+	registry := metrics.NewRegistry()
+	u := &User{
+		DB: setupDB(Tables),
+		GenerateUUID: func() string {
+			return "123456789012345678901234567890123456"
+		},
+		QueryDuration: registry.NewHistogramVec("dal_query_duration_seconds", "", metrics.DefBuckets, "entity", "method"),
+	}
+	defer u.DB.Close()
+
+	if _, err := u.Create(context.Background(), &model.User{FirstName: "test", LastName: "one"}); err != nil {
+		t.Errorf("User.Create() error = %v", err)
+		return
+	}
+	if _, err := u.FetchAll(context.Background()); err != nil {
+		t.Errorf("User.FetchAll() error = %v", err)
+		return
+	}
+
+	if got := u.QueryDuration.Count("user", "create"); got != 1 {
+		t.Errorf("User.QueryDuration create count = %v, want 1", got)
+	}
+	if got := u.QueryDuration.Count("user", "fetch_all"); got != 1 {
+		t.Errorf("User.QueryDuration fetch_all count = %v, want 1", got)
+	}
+}
+
+func TestUser_UpdateSpans(t *testing.T) {
+	exporter := &trace.InMemoryExporter{}
+	ctx := trace.NewContext(context.Background(), trace.NewTracer(exporter))
+
+	u := &User{
+		DB: setupDB([]string{
+			UserTable,
+			UserProfileColumns,
+			UserManagerColumn,
+			`INSERT INTO user (id, first_name, last_name) VALUES ('123456789012345678901234567890123456', 'test', 'one')`,
+		}),
+	}
+	defer u.DB.Close()
+
+	if _, err := u.Update(ctx, "123456789012345678901234567890123456", &model.User{FirstName: "testy"}); err != nil {
+		t.Errorf("User.Update() error = %v", err)
+		return
+	}
+
+	spans := exporter.Spans()
+	want := []struct {
+		name string
+		rows string
+	}{
+		{name: "dal.user.fetch_by_id", rows: attrRowsReturned},
+		{name: "dal.user.update", rows: attrRowsAffected},
+	}
+	if len(spans) != len(want) {
+		t.Errorf("User.Update() spans = %d, want %d", len(spans), len(want))
+		return
+	}
+	for i, w := range want {
+		if spans[i].Name != w.name {
+			t.Errorf("User.Update() span = %v, want %v", spans[i].Name, w.name)
+		}
+		if _, has := spans[i].Attributes[attrDBStatement]; has == false {
+			t.Errorf("User.Update() span %s missing statement", w.name)
+		}
+		if _, has := spans[i].Attributes[w.rows]; has == false {
+			t.Errorf("User.Update() span %s missing %s", w.name, w.rows)
+		}
+	}
+}
+
+func TestUser_Profile(t *testing.T) {
+	next := 0
+	u := &User{
+		DB: setupDB(Tables),
+		GenerateUUID: func() string {
+			next++
+			return fmt.Sprintf("%036d", next)
+		},
+	}
+	defer u.DB.Close()
+
+	profile := &model.User{
+		FirstName:  "test",
+		LastName:   "one",
+		Email:      "test@example.com",
+		Username:   "test",
+		Phone:      "+14155550123",
+		Locale:     "en-US",
+		Timezone:   "America/Chicago",
+		Attributes: model.Attributes{"team": "data", "level": 3.0},
+	}
+	if _, err := u.Create(context.Background(), profile); err != nil {
+		t.Errorf("User.Create() error = %v", err)
+		return
+	}
+	got, err := u.FetchByID(context.Background(), fmt.Sprintf("%036d", 1))
+	if err != nil {
+		t.Errorf("User.FetchByID() error = %v", err)
+		return
+	}
+	want := *profile
+	want.Status = model.UserActive
+	if reflect.DeepEqual(got.User, want) == false {
+		t.Errorf("User.FetchByID() = %v, want %v", got.User, want)
+	}
+
+	// users without an email or username do not conflict
+	if _, err := u.Create(context.Background(), &model.User{FirstName: "test", LastName: "two"}); err != nil {
+		t.Errorf("User.Create() error = %v", err)
+		return
+	}
+
+	tests := []struct {
+		name string
+		user *model.User
+	}{
+		{name: "email without regard to case", user: &model.User{Email: "TEST@example.com"}},
+		{name: "username", user: &model.User{Username: "test"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := u.Create(context.Background(), tt.user)
+			if errors.Is(err, errorx.ErrUserConflict) == false {
+				t.Errorf("User.Create() error = %v, want %v", err, errorx.ErrUserConflict)
+			}
+			var sqliteErr sqlite3.Error
+			if errors.As(err, &sqliteErr) == false || sqliteErr.ExtendedCode != sqlite3.ErrConstraintUnique {
+				t.Errorf("User.Create() error = %v, want the sqlite unique constraint error", err)
+			}
+			if _, err := u.Update(context.Background(), fmt.Sprintf("%036d", 2), tt.user); errors.Is(err, errorx.ErrUserConflict) == false {
+				t.Errorf("User.Update() error = %v, want %v", err, errorx.ErrUserConflict)
+			}
+		})
+	}
+}
+
+func TestUser_Manager(t *testing.T) {
+	next := 0
+	u := &User{
+		DB: setupDB(Tables),
+		GenerateUUID: func() string {
+			next++
+			return fmt.Sprintf("%036d", next)
+		},
+	}
+	defer u.DB.Close()
+	ctx := context.Background()
+
+	// ceo <- vp <- lead <- engineer, and ceo <- cfo
+	ids := map[string]string{}
+	for _, name := range []string{"ceo", "vp", "lead", "engineer", "cfo"} {
+		manager := map[string]string{"vp": "ceo", "lead": "vp", "engineer": "lead", "cfo": "ceo"}[name]
+		e, err := u.Create(ctx, &model.User{FirstName: name, LastName: "test", ManagerID: ids[manager]})
+		if err != nil {
+			t.Errorf("User.Create(%s) error = %v", name, err)
+			return
+		}
+		ids[name] = e.ID
+	}
+	names := func(nodes []*model.UserNode) []string {
+		got := []string{}
+		for _, n := range nodes {
+			got = append(got, fmt.Sprintf("%s:%d", n.FirstName, n.Depth))
+		}
+		return got
+	}
+
+	reports, err := u.Reports(ctx, ids["ceo"], 2)
+	if err != nil {
+		t.Errorf("User.Reports() error = %v", err)
+		return
+	}
+	if got, want := names(reports), []string{"cfo:1", "vp:1", "lead:2"}; reflect.DeepEqual(got, want) == false {
+		t.Errorf("User.Reports() = %v, want %v", got, want)
+	}
+	chain, err := u.Chain(ctx, ids["engineer"])
+	if err != nil {
+		t.Errorf("User.Chain() error = %v", err)
+		return
+	}
+	if got, want := names(chain), []string{"lead:1", "vp:2", "ceo:3"}; reflect.DeepEqual(got, want) == false {
+		t.Errorf("User.Chain() = %v, want %v", got, want)
+	}
+
+	tests := []struct {
+		name    string
+		user    string
+		manager string
+		err     error
+	}{
+		{name: "itself", user: "vp", manager: "vp", err: errorx.ErrManagerCycle},
+		{name: "a report", user: "vp", manager: "engineer", err: errorx.ErrManagerCycle},
+		{name: "missing", user: "vp", manager: fmt.Sprintf("%036d", 99), err: errorx.ErrNoManager},
+		{name: "another line", user: "lead", manager: "cfo"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := ids[tt.manager]
+			if len(manager) == 0 {
+				manager = tt.manager
+			}
+			_, err := u.Update(ctx, ids[tt.user], &model.User{ManagerID: manager})
+			if errors.Is(err, tt.err) == false || (tt.err == nil && err != nil) {
+				t.Errorf("User.Update() error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+
+	if err := u.Delete(ctx, ids["cfo"]); err != nil {
+		t.Errorf("User.Delete() error = %v", err)
+		return
+	}
+	chain, err = u.Chain(ctx, ids["engineer"])
+	if err != nil {
+		t.Errorf("User.Chain() error = %v", err)
+		return
+	}
+	if got, want := names(chain), []string{"lead:1"}; reflect.DeepEqual(got, want) == false {
+		t.Errorf("User.Chain() = %v, want %v", got, want)
+	}
+	reports, err = u.Reports(ctx, ids["ceo"], 0)
+	if err != nil {
+		t.Errorf("User.Reports() error = %v", err)
+		return
+	}
+	if got, want := names(reports), []string{"vp:1"}; reflect.DeepEqual(got, want) == false {
+		t.Errorf("User.Reports() = %v, want %v", got, want)
+	}
+	_, err = u.Update(ctx, ids["engineer"], &model.User{ManagerID: ids["cfo"]})
+	if errors.Is(err, errorx.ErrDeleteManager) == false || errors.Is(err, errorx.ErrDeleteUser) == false {
+		t.Errorf("User.Update() error = %v, want %v", err, errorx.ErrDeleteManager)
+	}
+	if _, err := u.Reports(ctx, ids["cfo"], 1); errors.Is(err, errorx.ErrDeleteUser) == false {
+		t.Errorf("User.Reports() error = %v, want %v", err, errorx.ErrDeleteUser)
+	}
+
+	if err := u.RemoveManager(ctx, ids["lead"]); err != nil {
+		t.Errorf("User.RemoveManager() error = %v", err)
+		return
+	}
+	if lead, err := u.FetchByID(ctx, ids["lead"]); err != nil || len(lead.ManagerID) > 0 {
+		t.Errorf("User.FetchByID() = %v, %v want no manager", lead, err)
+	}
+	if err := u.RemoveManager(ctx, ids["lead"]); err != nil {
+		t.Errorf("User.RemoveManager() without a manager error = %v", err)
+	}
+	if err := u.RemoveManager(ctx, ids["cfo"]); errors.Is(err, errorx.ErrDeleteUser) == false {
+		t.Errorf("User.RemoveManager() error = %v, want %v", err, errorx.ErrDeleteUser)
+	}
+}
+
+func TestUser_Search(t *testing.T) {
+	next := 0
+	u := &User{
+		DB: setupDB(Tables),
+		GenerateUUID: func() string {
+			next++
+			return fmt.Sprintf("%036d", next)
+		},
+	}
+	defer u.DB.Close()
+	ctx := context.Background()
+
+	users := []model.User{
+		{FirstName: "ann", LastName: "smith", Email: "ann@example.com"},
+		{FirstName: "bob", LastName: "jones", Status: model.UserInactive},
+		{FirstName: "cat", LastName: "smith", Username: "cat_100%"},
+		{FirstName: "dan", LastName: "smith"},
+		{FirstName: "eve", LastName: "brown"},
+	}
+	for i := range users {
+		if _, err := u.Create(ctx, &users[i]); err != nil {
+			t.Errorf("User.Create() error = %v", err)
+			return
+		}
+	}
+	if err := u.Delete(ctx, fmt.Sprintf("%036d", 4)); err != nil {
+		t.Errorf("User.Delete() error = %v", err)
+		return
+	}
+	names := func(entities []*model.UserEntity) []string {
+		got := []string{}
+		for _, e := range entities {
+			got = append(got, e.FirstName)
+		}
+		return got
+	}
+
+	pages := [][]string{}
+	search := &model.UserSearch{Limit: 2}
+	for {
+		entities, cursor, err := u.Search(ctx, search, "first_name")
+		if err != nil {
+			t.Errorf("User.Search() error = %v", err)
+			return
+		}
+		pages = append(pages, names(entities))
+		if len(cursor) == 0 {
+			break
+		}
+		search.Cursor = cursor
+	}
+	if want := [][]string{{"ann", "bob"}, {"cat", "eve"}}; reflect.DeepEqual(pages, want) == false {
+		t.Errorf("User.Search() pages = %v, want %v", pages, want)
+	}
+
+	tests := []struct {
+		name    string
+		search  *model.UserSearch
+		want    []string
+		wantErr error
+	}{
+		{
+			name:   "last name",
+			search: &model.UserSearch{LastName: "smith"},
+			want:   []string{"ann", "cat"},
+		},
+		{
+			name:   "status",
+			search: &model.UserSearch{Status: model.UserInactive},
+			want:   []string{"bob"},
+		},
+		{
+			name:   "text",
+			search: &model.UserSearch{Text: "example"},
+			want:   []string{"ann"},
+		},
+		{
+			name:   "text wildcard is literal",
+			search: &model.UserSearch{Text: "0%"},
+			want:   []string{"cat"},
+		},
+		{
+			name:   "created range",
+			search: &model.UserSearch{CreatedAfter: time.Now().Add(time.Hour)},
+			want:   []string{},
+		},
+		{
+			name:   "filter",
+			search: &model.UserSearch{Filter: `last_name eq "smith" and not (first_name eq "ann" or email ne null)`},
+			want:   []string{"cat"},
+		},
+		{
+			name:   "filter ne matches null",
+			search: &model.UserSearch{Filter: `email ne "ann@example.com"`},
+			want:   []string{"bob", "cat", "eve"},
+		},
+		{
+			name:   "filter not matches null",
+			search: &model.UserSearch{Filter: `not username eq "cat_100%" and not email co "example"`},
+			want:   []string{"bob", "eve"},
+		},
+		{
+			name:    "filter field",
+			search:  &model.UserSearch{Filter: `deleted_at ne null`},
+			wantErr: errorx.ErrInvalidSearch,
+		},
+		{
+			name:    "cursor",
+			search:  &model.UserSearch{Cursor: "not-a-cursor"},
+			wantErr: errorx.ErrInvalidSearch,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := u.Search(ctx, tt.search)
+			if errors.Is(err, tt.wantErr) == false {
+				t.Errorf("User.Search() error = %v, want %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr == nil && reflect.DeepEqual(names(got), tt.want) == false {
+				t.Errorf("User.Search() = %v, want %v", names(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestUser_SearchTimes(t *testing.T) {
+	u := &User{
+		DB: setupDB(append(Tables,
+			`INSERT INTO user (id, first_name, last_name, created_at) VALUES ('000000000000000000000000000000000001', 'ann', 'tied', '2026-01-01 00:00:00')`,
+			`INSERT INTO user (id, first_name, last_name, created_at) VALUES ('000000000000000000000000000000000002', 'bob', 'tied', '2026-01-01 00:00:00')`,
+			`INSERT INTO user (id, first_name, last_name, created_at) VALUES ('000000000000000000000000000000000003', 'cat', 'tied', '2026-01-01 00:00:00')`,
+			`INSERT INTO user (id, first_name, last_name, created_at) VALUES ('000000000000000000000000000000000004', 'dan', 'offset', '2026-01-01 03:00:00')`,
+			`INSERT INTO user (id, first_name, last_name, created_at) VALUES ('000000000000000000000000000000000005', 'eve', 'offset', '2026-01-01 01:30:00-05:00')`,
+		)),
+	}
+	defer u.DB.Close()
+	ctx := context.Background()
+	names := func(entities []*model.UserEntity) []string {
+		got := []string{}
+		for _, e := range entities {
+			got = append(got, e.FirstName)
+		}
+		return got
+	}
+
+	pages := [][]string{}
+	search := &model.UserSearch{Limit: 1}
+	for len(pages) < 10 {
+		entities, cursor, err := u.Search(ctx, search, "first_name")
+		if err != nil {
+			t.Errorf("User.Search() error = %v", err)
+			return
+		}
+		pages = append(pages, names(entities))
+		if len(cursor) == 0 {
+			break
+		}
+		search.Cursor = cursor
+	}
+	if want := [][]string{{"ann"}, {"bob"}, {"cat"}, {"dan"}, {"eve"}}; reflect.DeepEqual(pages, want) == false {
+		t.Errorf("User.Search() pages = %v, want %v", pages, want)
+	}
+
+	est := time.FixedZone("EST", -5*60*60)
+	tests := []struct {
+		name   string
+		search *model.UserSearch
+		want   []string
+	}{
+		{
+			name:   "created before with an offset",
+			search: &model.UserSearch{CreatedBefore: time.Date(2026, 1, 1, 0, 0, 0, 0, est)},
+			want:   []string{"ann", "bob", "cat", "dan"},
+		},
+		{
+			name:   "created after with an offset",
+			search: &model.UserSearch{CreatedAfter: time.Date(2025, 12, 31, 22, 0, 0, 0, est)},
+			want:   []string{"dan", "eve"},
+		},
+		{
+			name:   "filter with an offset",
+			search: &model.UserSearch{Filter: `last_name eq "offset" and created_at lt "2026-01-01T00:00:00-05:00"`},
+			want:   []string{"dan"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := u.Search(ctx, tt.search)
+			if err != nil {
+				t.Errorf("User.Search() error = %v", err)
+				return
+			}
+			if reflect.DeepEqual(names(got), tt.want) == false {
+				t.Errorf("User.Search() = %v, want %v", names(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestUser_Stats(t *testing.T) {
+	u := &User{
+		DB: setupDB(append(Tables,
+			`INSERT INTO user (id, first_name, last_name, status, created_at) VALUES ('000000000000000000000000000000000001', 'a', 'smith', 'active', '2026-03-07 05:59:59')`,
+			`INSERT INTO user (id, first_name, last_name, status, created_at) VALUES ('000000000000000000000000000000000002', 'b', 'smith', 'inactive', '2026-03-07 06:00:00.5+00:00')`,
+			`INSERT INTO user (id, first_name, last_name, status, created_at) VALUES ('000000000000000000000000000000000003', 'c', 'jones', 'active', '2026-03-08 23:30:00-05:00')`,
+			`INSERT INTO user (id, first_name, last_name, status, created_at, deleted_at) VALUES ('000000000000000000000000000000000004', 'd', 'brown', 'active', '2026-03-08 12:00:00', '2026-03-10 00:00:00')`,
+			`INSERT INTO user (id, first_name, last_name, status, created_at) VALUES ('000000000000000000000000000000000005', 'e', 'adams', 'suspended', '2026-04-01 00:00:00')`,
+		)),
+	}
+	defer u.DB.Close()
+
+	chicago, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		t.Skipf("time zone database error %v", err)
+	}
+	query := &model.UserStatsQuery{
+		From:      time.Date(2026, 3, 6, 0, 0, 0, 0, chicago),
+		To:        time.Date(2026, 3, 9, 0, 0, 0, 0, chicago),
+		Interval:  model.UserStatsDay,
+		Location:  chicago,
+		LastNames: 2,
+	}
+	got, err := u.Stats(context.Background(), query)
+	if err != nil {
+		t.Errorf("User.Stats() error = %v", err)
+		return
+	}
+	want := &model.UserStats{
+		Active:   4,
+		Deleted:  1,
+		Statuses: map[model.UserStatus]int{model.UserActive: 2, model.UserInactive: 1, model.UserSuspended: 1},
+		Interval: model.UserStatsDay,
+		Timezone: "America/Chicago",
+		Created: []model.UserStatsBucket{
+			// the first user is created the evening before in Chicago
+			{Start: time.Date(2026, 3, 6, 0, 0, 0, 0, chicago), Count: 1},
+			{Start: time.Date(2026, 3, 7, 0, 0, 0, 0, chicago), Count: 1},
+			// the day that daylight saving starts has the deleted user and one created at 23:30 CDT
+			{Start: time.Date(2026, 3, 8, 0, 0, 0, 0, chicago), Count: 2},
+		},
+		LastNames: []model.UserStatsLastName{{LastName: "smith", Count: 2}, {LastName: "adams", Count: 1}},
+	}
+	if reflect.DeepEqual(got, want) == false {
+		t.Errorf("User.Stats() = %+v, want %+v", got, want)
+	}
+
+	query.From, query.To, query.Interval = time.Date(2026, 3, 15, 0, 0, 0, 0, chicago), time.Date(2026, 4, 15, 0, 0, 0, 0, chicago), model.UserStatsMonth
+	got, err = u.Stats(context.Background(), query)
+	if err != nil {
+		t.Errorf("User.Stats() error = %v", err)
+		return
+	}
+	// the last user is created on the evening of march 31 in Chicago, april is an empty bucket
+	created := []model.UserStatsBucket{
+		{Start: time.Date(2026, 3, 1, 0, 0, 0, 0, chicago), Count: 5},
+		{Start: time.Date(2026, 4, 1, 0, 0, 0, 0, chicago), Count: 0},
+	}
+	if reflect.DeepEqual(got.Created, created) == false {
+		t.Errorf("User.Stats() created = %+v, want %+v", got.Created, created)
+	}
+
+	query.Interval = "year"
+	if _, err := u.Stats(context.Background(), query); errors.Is(err, errorx.ErrInvalidStats) == false {
+		t.Errorf("User.Stats() error = %v, want %v", err, errorx.ErrInvalidStats)
+	}
+}
+
+func TestZoneOffsets(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		t.Skipf("time zone database error %v", err)
+	}
+	start, end := time.Date(2026, 3, 1, 0, 0, 0, 0, chicago), time.Date(2026, 4, 1, 0, 0, 0, 0, chicago)
+	got := zoneOffsets(chicago, start, end)
+	want := []zoneOffset{
+		{until: time.Date(2026, 3, 8, 8, 0, 0, 0, time.UTC), offset: -6 * 60 * 60},
+		{until: end, offset: -5 * 60 * 60},
+	}
+	if len(got) != len(want) {
+		t.Errorf("zoneOffsets() = %+v, want %+v", got, want)
+		return
+	}
+	for i := range want {
+		if got[i].until.Equal(want[i].until) == false || got[i].offset != want[i].offset {
+			t.Errorf("zoneOffsets() = %+v, want %+v", got, want)
+		}
+	}
+	if got := zoneOffsets(time.UTC, start, end); len(got) != 1 || got[0].offset != 0 {
+		t.Errorf("zoneOffsets() = %+v, want a single utc offset", got)
+	}
+}
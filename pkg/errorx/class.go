@@ -0,0 +1,90 @@
+package errorx
+
+import "errors"
+
+// The error classes, an error is matched to its class with errors.Is
+var (
+	// ErrNotFound when the entity does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrConflict when the change conflicts with an existing entity
+	ErrConflict = errors.New("conflict")
+	// ErrConstraint when the change violates a constraint of the datastore
+	ErrConstraint = errors.New("constraint violation")
+	// ErrTimeout when the operation did not complete before its deadline
+	ErrTimeout = errors.New("timeout")
+	// ErrCanceled when the operation was canceled by the caller
+	ErrCanceled = errors.New("canceled")
+	// ErrUnavailable when the datastore can not be used at the moment, the operation may be retried
+	ErrUnavailable = errors.New("unavailable")
+)
+
+// classified is an error that also matches its class
+type classified struct {
+	class error
+	err   error
+}
+
+func (c *classified) Error() string {
+	return c.err.Error()
+}
+
+func (c *classified) Unwrap() error {
+	return c.err
+}
+
+func (c *classified) Is(target error) bool {
+	return target == c.class
+}
+
+// New returns an error with the message that matches the class
+func New(class error, msg string) error {
+	return &classified{class: class, err: errors.New(msg)}
+}
+
+// Wrap returns an error that matches the class and the wrapped error, a nil error is not wrapped
+func Wrap(class, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &classified{class: class, err: err}
+}
+
+// caused is an error that also matches its cause
+type caused struct {
+	err   error
+	cause error
+}
+
+func (c *caused) Error() string {
+	return c.err.Error()
+}
+
+func (c *caused) Unwrap() error {
+	return c.err
+}
+
+func (c *caused) Is(target error) bool {
+	return errors.Is(c.cause, target)
+}
+
+func (c *caused) As(target interface{}) bool {
+	return errors.As(c.cause, target)
+}
+
+// WithCause returns the error that also matches the cause with errors.Is and errors.As, the message is the error's
+func WithCause(err, cause error) error {
+	if cause == nil {
+		return err
+	}
+	return &caused{err: err, cause: cause}
+}
+
+// Class returns the class of the error or nil when it is not classified
+func Class(err error) error {
+	for _, class := range []error{ErrNotFound, ErrConflict, ErrConstraint, ErrTimeout, ErrCanceled, ErrUnavailable} {
+		if errors.Is(err, class) {
+			return class
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package errorx
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestClass(t *testing.T) {
+	cause := errors.New("database is locked")
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{name: "sentinel", err: ErrNoUser, want: ErrNotFound},
+		{name: "wrapped sentinel", err: fmt.Errorf("user create insert %w", ErrUserConflict), want: ErrConflict},
+		{name: "wrap", err: Wrap(ErrUnavailable, cause), want: ErrUnavailable},
+		{name: "class", err: ErrTimeout, want: ErrTimeout},
+		{name: "unclassified", err: cause, want: nil},
+		{name: "nil", err: nil, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Class(tt.err); got != tt.want {
+				t.Errorf("Class() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWrap(t *testing.T) {
+	cause := errors.New("database is locked")
+	err := fmt.Errorf("user fetch query %w", Wrap(ErrUnavailable, cause))
+	if errors.Is(err, cause) == false {
+		t.Errorf("Wrap() does not match the cause")
+	}
+	if errors.Is(err, ErrNotFound) {
+		t.Errorf("Wrap() matches another class")
+	}
+	if err.Error() != "user fetch query database is locked" {
+		t.Errorf("Wrap() = %v", err)
+	}
+	if Wrap(ErrUnavailable, nil) != nil {
+		t.Errorf("Wrap() of nil is not nil")
+	}
+}
+
+func TestWithCause(t *testing.T) {
+	cause := Wrap(ErrConflict, &os.PathError{Op: "open", Path: "user.db", Err: errors.New("locked")})
+	err := fmt.Errorf("user create insert %w", WithCause(ErrUserConflict, cause))
+	if errors.Is(err, ErrUserConflict) == false {
+		t.Errorf("WithCause() does not match the error")
+	}
+	if errors.Is(err, cause) == false {
+		t.Errorf("WithCause() does not match the cause")
+	}
+	var pathErr *os.PathError
+	if errors.As(err, &pathErr) == false {
+		t.Errorf("WithCause() does not match the cause type")
+	}
+	if Class(err) != ErrConflict {
+		t.Errorf("Class() = %v, want %v", Class(err), ErrConflict)
+	}
+	if err.Error() != "user create insert user already exists" {
+		t.Errorf("WithCause() = %v", err)
+	}
+	if WithCause(ErrUserConflict, nil) != ErrUserConflict {
+		t.Errorf("WithCause() of a nil cause is not the error")
+	}
+}
@@ -1,10 +1,59 @@
 package errorx
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	// ErrNoUser when no user entity is found
-	ErrNoUser = errors.New("user is not present")
+	ErrNoUser = New(ErrNotFound, "user is not present")
 	// ErrDeleteUser when the user has been deleted
 	ErrDeleteUser = errors.New("user has been deleted")
-)
\ No newline at end of file
+	// ErrInvalidUser when an user field is not valid
+	ErrInvalidUser = errors.New("user is not valid")
+	// ErrUserConflict when an unique user field is already used by another user
+	ErrUserConflict = New(ErrConflict, "user already exists")
+	// ErrNoManager when the manager of an user is not present
+	ErrNoManager = New(ErrConstraint, "manager is not present")
+	// ErrDeleteManager when the manager of an user has been deleted, it is also an ErrDeleteUser
+	ErrDeleteManager = Wrap(ErrConstraint, fmt.Errorf("manager %w", ErrDeleteUser))
+	// ErrManagerCycle when an user would report to itself or one of its reports
+	ErrManagerCycle = New(ErrConstraint, "user can not report to itself or one of its reports")
+	// ErrInvalidSearch when an user search parameter or cursor is not valid
+	ErrInvalidSearch = errors.New("user search is not valid")
+	// ErrInvalidStats when an user statistics parameter is not valid
+	ErrInvalidStats = errors.New("user stats query is not valid")
+	// ErrNoGroup when no group entity is found
+	ErrNoGroup = New(ErrNotFound, "group is not present")
+	// ErrDeleteGroup when the group has been deleted
+	ErrDeleteGroup = errors.New("group has been deleted")
+	// ErrInvalidGroup when a group field is not valid
+	ErrInvalidGroup = errors.New("group is not valid")
+	// ErrGroupConflict when the group name is already used by another group
+	ErrGroupConflict = New(ErrConflict, "group already exists")
+	// ErrNoMember when the user is not a member of the group
+	ErrNoMember = New(ErrNotFound, "user is not a member of the group")
+	// ErrMemberConflict when the user is already a member of the group
+	ErrMemberConflict = New(ErrConflict, "user is already a member of the group")
+	// ErrNoOrganization when no organization entity is found
+	ErrNoOrganization = New(ErrNotFound, "organization is not present")
+	// ErrDeleteOrganization when the organization has been deleted
+	ErrDeleteOrganization = errors.New("organization has been deleted")
+	// ErrInvalidOrganization when an organization field or placement is not valid
+	ErrInvalidOrganization = errors.New("organization is not valid")
+	// ErrOrganizationCycle when a move would place an organization within its own subtree
+	ErrOrganizationCycle = New(ErrConstraint, "organization can not be moved within its own subtree")
+	// ErrOrganizationChildren when an organization with children is deleted
+	ErrOrganizationChildren = New(ErrConstraint, "organization has children")
+	// ErrBodyTooLarge when the request body is larger than allowed
+	ErrBodyTooLarge = errors.New("request body is too large")
+	// ErrUnsupportedMediaType when the request body is not in a supported media type
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+	// ErrNotAcceptable when no response media type is acceptable to the client
+	ErrNotAcceptable = errors.New("not acceptable")
+	// ErrUnknownField when the request body has a field that is not known
+	ErrUnknownField = errors.New("unknown field")
+	// ErrTrailingData when the request body has data after the json value
+	ErrTrailingData = errors.New("trailing data after json value")
+)
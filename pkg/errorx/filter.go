@@ -0,0 +1,24 @@
+package errorx
+
+import "fmt"
+
+// FilterError is a filter expression that is not valid, it points at the offending token
+type FilterError struct {
+	// Position is the character of the filter that the token starts at, counting from one
+	Position int
+	// Token is the offending token, it is empty at the end of the filter
+	Token   string
+	Message string
+}
+
+func (e *FilterError) Error() string {
+	if len(e.Token) == 0 {
+		return fmt.Sprintf("filter position %d: %s", e.Position, e.Message)
+	}
+	return fmt.Sprintf("filter position %d %q: %s", e.Position, e.Token, e.Message)
+}
+
+// Unwrap makes a filter error an ErrInvalidSearch
+func (e *FilterError) Unwrap() error {
+	return ErrInvalidSearch
+}
@@ -0,0 +1,18 @@
+package logx
+
+import "context"
+
+type contextKey struct{}
+
+// NewContext returns a context carrying the logger
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the logger in the context or the default logger
+func FromContext(ctx context.Context) *Logger {
+	if l, ok := ctx.Value(contextKey{}).(*Logger); ok && l != nil {
+		return l
+	}
+	return Default()
+}
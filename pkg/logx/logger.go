@@ -0,0 +1,162 @@
+package logx
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Level is the severity of a log entry
+type Level int32
+
+const (
+	// DebugLevel is for verbose diagnostic entries
+	DebugLevel Level = iota
+	// InfoLevel is for general operational entries
+	InfoLevel
+	// WarnLevel is for entries that may need attention
+	WarnLevel
+	// ErrorLevel is for entries that need attention
+	ErrorLevel
+)
+
+var levelNames = map[Level]string{
+	DebugLevel: "debug",
+	InfoLevel:  "info",
+	WarnLevel:  "warn",
+	ErrorLevel: "error",
+}
+
+func (l Level) String() string {
+	if name, has := levelNames[l]; has {
+		return name
+	}
+	return fmt.Sprintf("level(%d)", l)
+}
+
+// ParseLevel will return the level from its name
+func ParseLevel(name string) (Level, error) {
+	for level, n := range levelNames {
+		if strings.EqualFold(n, name) {
+			return level, nil
+		}
+	}
+	return InfoLevel, fmt.Errorf("log level %s is not supported", name)
+}
+
+// Fields are the structured key values of a log entry
+type Fields map[string]interface{}
+
+// Logger writes leveled json log entries
+type Logger struct {
+	out    *output
+	level  *int32
+	fields Fields
+}
+
+type output struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// New creates a logger writing to w at the level
+func New(w io.Writer, level Level) *Logger {
+	lvl := int32(level)
+	return &Logger{
+		out:    &output{w: w},
+		level:  &lvl,
+		fields: Fields{},
+	}
+}
+
+var std = New(os.Stderr, InfoLevel)
+
+// Default returns the process wide logger
+func Default() *Logger {
+	return std
+}
+
+// SetLevel will change the level of the logger and all of its children
+func (l *Logger) SetLevel(level Level) {
+	atomic.StoreInt32(l.level, int32(level))
+}
+
+// Level returns the current level of the logger
+func (l *Logger) Level() Level {
+	return Level(atomic.LoadInt32(l.level))
+}
+
+// Enabled returns if entries at the level will be written
+func (l *Logger) Enabled(level Level) bool {
+	return level >= l.Level()
+}
+
+// With returns a child logger that adds the fields to every entry
+func (l *Logger) With(fields Fields) *Logger {
+	child := &Logger{
+		out:    l.out,
+		level:  l.level,
+		fields: make(Fields, len(l.fields)+len(fields)),
+	}
+	for k, v := range l.fields {
+		child.fields[k] = v
+	}
+	for k, v := range fields {
+		child.fields[k] = v
+	}
+	return child
+}
+
+// Debug will write a debug entry
+func (l *Logger) Debug(msg string, fields Fields) {
+	l.log(DebugLevel, msg, fields)
+}
+
+// Info will write an info entry
+func (l *Logger) Info(msg string, fields Fields) {
+	l.log(InfoLevel, msg, fields)
+}
+
+// Warn will write a warning entry
+func (l *Logger) Warn(msg string, fields Fields) {
+	l.log(WarnLevel, msg, fields)
+}
+
+// Error will write an error entry
+func (l *Logger) Error(msg string, fields Fields) {
+	l.log(ErrorLevel, msg, fields)
+}
+
+func (l *Logger) log(level Level, msg string, fields Fields) {
+	if l.Enabled(level) == false {
+		return
+	}
+
+	entry := make(Fields, len(l.fields)+len(fields)+3)
+	for k, v := range l.fields {
+		entry[k] = v
+	}
+	for k, v := range fields {
+		if err, ok := v.(error); ok {
+			v = err.Error()
+		}
+		entry[k] = v
+	}
+	entry["time"] = time.Now().UTC().Format(time.RFC3339Nano)
+	entry["level"] = level.String()
+	entry["msg"] = msg
+
+	enc, err := json.Marshal(entry)
+	if err != nil {
+		enc = []byte(fmt.Sprintf(`{"level":"error","msg":"log entry encode error %s"}`, err.Error()))
+	}
+
+	l.out.mu.Lock()
+	defer l.out.mu.Unlock()
+	_, _ = l.out.w.Write(append(enc, '\n'))
+}
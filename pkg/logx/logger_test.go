@@ -0,0 +1,156 @@
+package logx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestLogger_Levels(t *testing.T) {
+	type args struct {
+		level Level
+		log   func(l *Logger)
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    map[string]interface{}
+		written bool
+	}{
+		{
+			name: "info written",
+			args: args{
+				level: InfoLevel,
+				log: func(l *Logger) {
+					l.Info("hello", Fields{"a": "b"})
+				},
+			},
+			want: map[string]interface{}{
+				"level": "info",
+				"msg":   "hello",
+				"a":     "b",
+			},
+			written: true,
+		},
+		{
+			name: "debug filtered",
+			args: args{
+				level: InfoLevel,
+				log: func(l *Logger) {
+					l.Debug("hello", nil)
+				},
+			},
+			written: false,
+		},
+		{
+			name: "child fields and errors",
+			args: args{
+				level: DebugLevel,
+				log: func(l *Logger) {
+					l.With(Fields{"request_id": "1234"}).Error("oops", Fields{"error": errors.New("bad")})
+				},
+			},
+			want: map[string]interface{}{
+				"level":      "error",
+				"msg":        "oops",
+				"request_id": "1234",
+				"error":      "bad",
+			},
+			written: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			l := New(buf, tt.args.level)
+
+			tt.args.log(l)
+
+			if (buf.Len() > 0) != tt.written {
+				t.Errorf("Logger written = %v, want %v", buf.Len() > 0, tt.written)
+				return
+			}
+			if tt.written == false {
+				return
+			}
+
+			var got map[string]interface{}
+			if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+				t.Errorf("Logger entry decode error %v", err)
+				return
+			}
+			for k, v := range tt.want {
+				if got[k] != v {
+					t.Errorf("Logger entry %s = %v, want %v", k, got[k], v)
+				}
+			}
+			if _, has := got["time"]; has == false {
+				t.Errorf("Logger entry missing time")
+			}
+		})
+	}
+}
+
+func TestLogger_SetLevel(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := New(buf, InfoLevel)
+	child := l.With(Fields{"a": "b"})
+
+	l.SetLevel(DebugLevel)
+	child.Debug("hello", nil)
+
+	if buf.Len() == 0 {
+		t.Errorf("Logger.SetLevel() child did not follow parent level")
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		name    string
+		level   string
+		want    Level
+		wantErr bool
+	}{
+		{
+			name:  "debug",
+			level: "DEBUG",
+			want:  DebugLevel,
+		},
+		{
+			name:  "warn",
+			level: "warn",
+			want:  WarnLevel,
+		},
+		{
+			name:    "unknown",
+			level:   "loud",
+			want:    InfoLevel,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLevel(tt.level)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseLevel() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseLevel() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	l := New(&bytes.Buffer{}, InfoLevel)
+
+	if got := FromContext(context.Background()); got != Default() {
+		t.Errorf("FromContext() = %v, want default", got)
+	}
+	if got := FromContext(NewContext(context.Background(), l)); got != l {
+		t.Errorf("FromContext() = %v, want %v", got, l)
+	}
+}
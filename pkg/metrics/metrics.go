@@ -0,0 +1,166 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"sync"
+)
+
+// CounterVec is a monotonically increasing value partitioned by labels
+type CounterVec struct {
+	desc
+	mu     sync.Mutex
+	values map[string]*counterValue
+}
+
+type counterValue struct {
+	labels []string
+	value  float64
+}
+
+// Inc will add one to the counter with the label values
+func (c *CounterVec) Inc(labels ...string) {
+	c.Add(1, labels...)
+}
+
+// Add will add the delta to the counter with the label values, a nil counter is ignored
+func (c *CounterVec) Add(delta float64, labels ...string) {
+	if c == nil {
+		return
+	}
+	if delta < 0 {
+		panic(fmt.Sprintf("counter %s can not decrease", c.name))
+	}
+	key := c.key(labels)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	v, has := c.values[key]
+	if has == false {
+		v = &counterValue{labels: append([]string{}, labels...)}
+		c.values[key] = v
+	}
+	v.value += delta
+}
+
+// Value returns the current value of the counter with the label values
+func (c *CounterVec) Value(labels ...string) float64 {
+	if c == nil {
+		return 0
+	}
+	key := c.key(labels)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if v, has := c.values[key]; has {
+		return v.value
+	}
+	return 0
+}
+
+func (c *CounterVec) write(w *bufio.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.header(w, "counter")
+	keys := make([]string, 0, len(c.values))
+	for k := range c.values {
+		keys = append(keys, k)
+	}
+	for _, k := range sortedKeys(keys) {
+		v := c.values[k]
+		fmt.Fprintf(w, "%s%s %s\n", c.name, c.pairs(v.labels), formatFloat(v.value))
+	}
+}
+
+// HistogramVec samples observations into buckets partitioned by labels
+type HistogramVec struct {
+	desc
+	buckets []float64
+	mu      sync.Mutex
+	values  map[string]*histogramValue
+}
+
+type histogramValue struct {
+	labels []string
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// Observe will add the value to the histogram with the label values, a nil histogram is ignored
+func (h *HistogramVec) Observe(value float64, labels ...string) {
+	if h == nil {
+		return
+	}
+	key := h.key(labels)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	v, has := h.values[key]
+	if has == false {
+		v = &histogramValue{
+			labels: append([]string{}, labels...),
+			counts: make([]uint64, len(h.buckets)),
+		}
+		h.values[key] = v
+	}
+	for i, upper := range h.buckets {
+		if value <= upper {
+			v.counts[i]++
+		}
+	}
+	v.count++
+	v.sum += value
+}
+
+// Count returns the number of observations with the label values
+func (h *HistogramVec) Count(labels ...string) uint64 {
+	if h == nil {
+		return 0
+	}
+	key := h.key(labels)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if v, has := h.values[key]; has {
+		return v.count
+	}
+	return 0
+}
+
+func (h *HistogramVec) write(w *bufio.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.header(w, "histogram")
+	keys := make([]string, 0, len(h.values))
+	for k := range h.values {
+		keys = append(keys, k)
+	}
+	for _, k := range sortedKeys(keys) {
+		v := h.values[k]
+		for i, upper := range h.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.pairs(v.labels, "le", formatFloat(upper)), v.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.pairs(v.labels, "le", formatFloat(math.Inf(1))), v.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, h.pairs(v.labels), formatFloat(v.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, h.pairs(v.labels), v.count)
+	}
+}
+
+type gaugeFunc struct {
+	desc
+	fn      func() float64
+	counter bool
+}
+
+func (g *gaugeFunc) write(w *bufio.Writer) {
+	kind := "gauge"
+	if g.counter {
+		kind = "counter"
+	}
+	g.header(w, kind)
+	fmt.Fprintf(w, "%s %s\n", g.name, formatFloat(g.fn()))
+}
@@ -0,0 +1,126 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistry_WriteText(t *testing.T) {
+	tests := []struct {
+		name    string
+		collect func(r *Registry)
+		want    []string
+	}{
+		{
+			name: "counter",
+			collect: func(r *Registry) {
+				c := r.NewCounterVec("requests_total", "Total requests.", "route", "status")
+				c.Inc("user-fetch", "200")
+				c.Add(2, "user-fetch", "200")
+				c.Inc("user-create", "201")
+			},
+			want: []string{
+				"# HELP requests_total Total requests.",
+				"# TYPE requests_total counter",
+				`requests_total{route="user-create",status="201"} 1`,
+				`requests_total{route="user-fetch",status="200"} 3`,
+			},
+		},
+		{
+			name: "histogram",
+			collect: func(r *Registry) {
+				h := r.NewHistogramVec("latency_seconds", "Latency.", []float64{0.5, 0.1}, "method")
+				h.Observe(0.05, "create")
+				h.Observe(0.3, "create")
+				h.Observe(2, "create")
+			},
+			want: []string{
+				"# TYPE latency_seconds histogram",
+				`latency_seconds_bucket{method="create",le="0.1"} 1`,
+				`latency_seconds_bucket{method="create",le="0.5"} 2`,
+				`latency_seconds_bucket{method="create",le="+Inf"} 3`,
+				`latency_seconds_sum{method="create"} 2.35`,
+				`latency_seconds_count{method="create"} 3`,
+			},
+		},
+		{
+			name: "gauge func",
+			collect: func(r *Registry) {
+				r.NewGaugeFunc("open_connections", "Open connections.", func() float64 { return 4 })
+			},
+			want: []string{
+				"# TYPE open_connections gauge",
+				"open_connections 4",
+			},
+		},
+		{
+			name: "escaped label",
+			collect: func(r *Registry) {
+				c := r.NewCounterVec("escaped_total", "Escaped.", "value")
+				c.Inc(`a"b`)
+			},
+			want: []string{
+				`escaped_total{value="a\"b"} 1`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry()
+			tt.collect(r)
+
+			buf := &bytes.Buffer{}
+			if err := r.WriteText(buf); err != nil {
+				t.Errorf("Registry.WriteText() error = %v", err)
+				return
+			}
+
+			lines := strings.Split(buf.String(), "\n")
+			for _, want := range tt.want {
+				found := false
+				for _, line := range lines {
+					if line == want {
+						found = true
+						break
+					}
+				}
+				if found == false {
+					t.Errorf("Registry.WriteText() missing %q in\n%s", want, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestRegistry_Handler(t *testing.T) {
+	r := NewRegistry()
+	r.NewCounterVec("requests_total", "Total requests.").Inc()
+
+	writer := httptest.NewRecorder()
+	r.Handler().ServeHTTP(writer, httptest.NewRequest(http.MethodGet, "http://localhost:8080/metrics", nil))
+
+	if writer.Result().StatusCode != http.StatusOK {
+		t.Errorf("Registry.Handler() status = %v, want %v", writer.Result().StatusCode, http.StatusOK)
+	}
+	if ct := writer.Header().Get("Content-Type"); ct != ContentType {
+		t.Errorf("Registry.Handler() content type = %v, want %v", ct, ContentType)
+	}
+	if strings.Contains(writer.Body.String(), "requests_total 1") == false {
+		t.Errorf("Registry.Handler() body = %v", writer.Body.String())
+	}
+}
+
+func TestNilCollectors(t *testing.T) {
+	var c *CounterVec
+	var h *HistogramVec
+
+	c.Inc("a")
+	h.Observe(1, "a")
+
+	if c.Value("a") != 0 || h.Count("a") != 0 {
+		t.Errorf("nil collectors should be ignored")
+	}
+}
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// Attributes are free-form values stored as a json object
+type Attributes map[string]interface{}
+
+// Value stores the attributes as json, no attributes are stored as null
+func (a Attributes) Value() (driver.Value, error) {
+	if a == nil {
+		return nil, nil
+	}
+	enc, err := json.Marshal(a)
+	if err != nil {
+		return nil, err
+	}
+	return string(enc), nil
+}
+
+// Scan reads the attributes from json text
+func (a *Attributes) Scan(src interface{}) error {
+	var data []byte
+	switch t := src.(type) {
+	case nil:
+		*a = nil
+		return nil
+	case string:
+		data = []byte(t)
+	case []byte:
+		data = t
+	default:
+		return fmt.Errorf("attributes can not be scanned from %T", src)
+	}
+	attrs := Attributes{}
+	if err := json.Unmarshal(data, &attrs); err != nil {
+		return fmt.Errorf("attributes json %w", err)
+	}
+	*a = attrs
+	return nil
+}
@@ -0,0 +1,63 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/g8rswimmer/go-data-access-example/pkg/errorx"
+)
+
+// MemberRole is the role of an user within a group
+type MemberRole string
+
+const (
+	// MemberOwner may change and delete the group
+	MemberOwner MemberRole = "owner"
+	// MemberAdmin may add and remove members
+	MemberAdmin MemberRole = "admin"
+	// MemberRegular is a member without privileges, it is the role of a new member
+	MemberRegular MemberRole = "member"
+)
+
+// MemberRoles are the valid member roles
+var MemberRoles = []MemberRole{MemberOwner, MemberAdmin, MemberRegular}
+
+const maxGroupNameLength = 100
+
+// Group is the structure for a group of users
+type Group struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// GroupEntity is the group entity for the database
+type GroupEntity struct {
+	Entity
+	Group
+}
+
+// Member is an user within a group
+type Member struct {
+	GroupID   string     `json:"group_id"`
+	UserID    string     `json:"user_id"`
+	Role      MemberRole `json:"role"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// Validate returns an error when the name is too long or a field is not valid, an empty name is valid
+func (g *Group) Validate() error {
+	if len(g.Name) > maxGroupNameLength {
+		return fmt.Errorf("%w: name must be at most %d characters", errorx.ErrInvalidGroup, maxGroupNameLength)
+	}
+	return nil
+}
+
+// Valid returns if the role is one of the member roles
+func (r MemberRole) Valid() bool {
+	for _, role := range MemberRoles {
+		if role == r {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package model
+
+import (
+	"fmt"
+
+	"github.com/g8rswimmer/go-data-access-example/pkg/errorx"
+)
+
+// OrganizationKind is the level of an organization in the tree
+type OrganizationKind string
+
+const (
+	// OrganizationCompany is the root of a tree
+	OrganizationCompany OrganizationKind = "company"
+	// OrganizationDepartment is within a company or another department
+	OrganizationDepartment OrganizationKind = "department"
+	// OrganizationTeam is within a department or another team
+	OrganizationTeam OrganizationKind = "team"
+)
+
+// OrganizationKinds are the valid kinds from the root down
+var OrganizationKinds = []OrganizationKind{OrganizationCompany, OrganizationDepartment, OrganizationTeam}
+
+const maxOrganizationNameLength = 100
+
+// Organization is a node of the organization tree, a company has no parent
+type Organization struct {
+	Name     string           `json:"name"`
+	Kind     OrganizationKind `json:"kind"`
+	ParentID string           `json:"parent_id"`
+}
+
+// OrganizationEntity is the organization entity for the database
+type OrganizationEntity struct {
+	Entity
+	Organization
+}
+
+// OrganizationNode is an organization with its distance from the node that was queried
+// and the number of users in its subtree
+type OrganizationNode struct {
+	OrganizationEntity
+	Depth     int `json:"depth"`
+	UserCount int `json:"user_count"`
+}
+
+// Validate returns an error when the name or kind is not valid
+func (o *Organization) Validate() error {
+	if err := ValidateOrganizationName(o.Name); err != nil {
+		return err
+	}
+	if o.Kind.level() < 0 {
+		return fmt.Errorf("%w: kind %q must be one of %v", errorx.ErrInvalidOrganization, o.Kind, OrganizationKinds)
+	}
+	return nil
+}
+
+// ValidateOrganizationName returns an error when the name is empty or too long
+func ValidateOrganizationName(name string) error {
+	switch {
+	case len(name) == 0:
+		return fmt.Errorf("%w: name is required", errorx.ErrInvalidOrganization)
+	case len(name) > maxOrganizationNameLength:
+		return fmt.Errorf("%w: name must be at most %d characters", errorx.ErrInvalidOrganization, maxOrganizationNameLength)
+	}
+	return nil
+}
+
+// CanParent returns an error when an organization of the kind can not be placed under the parent kind,
+// the parent is the same kind or the kind one level up and an empty parent kind is the root of the tree
+func (k OrganizationKind) CanParent(parent OrganizationKind) error {
+	switch {
+	case len(parent) == 0 && k != OrganizationCompany:
+		return fmt.Errorf("%w: a %s must have a parent", errorx.ErrInvalidOrganization, k)
+	case len(parent) > 0 && k == OrganizationCompany:
+		return fmt.Errorf("%w: a company can not have a parent", errorx.ErrInvalidOrganization)
+	case len(parent) > 0 && (parent.level() > k.level() || parent.level() < k.level()-1):
+		return fmt.Errorf("%w: a %s can not be within a %s", errorx.ErrInvalidOrganization, k, parent)
+	}
+	return nil
+}
+
+func (k OrganizationKind) level() int {
+	for i, kind := range OrganizationKinds {
+		if kind == k {
+			return i
+		}
+	}
+	return -1
+}
@@ -1,9 +1,34 @@
 package model
 
+import "time"
+
+// UserStatus is the state of an user account
+type UserStatus string
+
+const (
+	// UserActive is an user that may sign in, it is the status of a new user
+	UserActive UserStatus = "active"
+	// UserInactive is an user that has not been activated or has been deactivated
+	UserInactive UserStatus = "inactive"
+	// UserSuspended is an user that has been blocked
+	UserSuspended UserStatus = "suspended"
+)
+
+// UserStatuses are the valid user statuses
+var UserStatuses = []UserStatus{UserActive, UserInactive, UserSuspended}
+
 // User is the structure for an user
 type User struct {
-	FirstName string `json:"first_name"`
-	LastName  string `json:"last_name"`
+	FirstName  string     `json:"first_name"`
+	LastName   string     `json:"last_name"`
+	Email      string     `json:"email"`
+	Username   string     `json:"username"`
+	Phone      string     `json:"phone"`
+	Status     UserStatus `json:"status"`
+	Locale     string     `json:"locale"`
+	Timezone   string     `json:"timezone"`
+	Attributes Attributes `json:"attributes"`
+	ManagerID  string     `json:"manager_id"`
 }
 
 // UserEntity is the user entity for the database
@@ -11,3 +36,137 @@ type UserEntity struct {
 	Entity
 	User
 }
+
+// UserNode is an user with its distance in the reporting line from the user that was queried
+type UserNode struct {
+	UserEntity
+	Depth int `json:"depth"`
+}
+
+// UserFields are the json names of the user entity fields in the order they are projected
+var UserFields = []string{
+	"id",
+	"first_name",
+	"last_name",
+	"email",
+	"username",
+	"phone",
+	"status",
+	"locale",
+	"timezone",
+	"attributes",
+	"manager_id",
+	"created_at",
+	"updated_at",
+	"deleted_at",
+}
+
+// MaxSearchLimit is the most users that a search returns in a page
+const MaxSearchLimit = 1000
+
+// UserSearch selects a page of the users that have not been deleted, the empty fields do not filter
+type UserSearch struct {
+	Status   UserStatus
+	LastName string
+	Email    string
+	Username string
+	// Text is contained in the first name, last name, email or username
+	Text string
+	// CreatedAfter and CreatedBefore are the range of the created time, CreatedAfter is included
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	// Filter is an expression of comparisons of the user fields, such as last_name eq "Smith"
+	Filter string
+	// Limit is the most users of the page, zero is all of the users
+	Limit int
+	// Cursor continues after the last user of the previous page
+	Cursor string
+}
+
+// UserStatsInterval is the width of a bucket of the created histogram
+type UserStatsInterval string
+
+const (
+	// UserStatsDay buckets by the day
+	UserStatsDay UserStatsInterval = "day"
+	// UserStatsWeek buckets by the week, a week starts on Monday
+	UserStatsWeek UserStatsInterval = "week"
+	// UserStatsMonth buckets by the month
+	UserStatsMonth UserStatsInterval = "month"
+)
+
+// UserStatsIntervals are the valid histogram intervals
+var UserStatsIntervals = []UserStatsInterval{UserStatsDay, UserStatsWeek, UserStatsMonth}
+
+const (
+	// MaxStatsBuckets is the most buckets of the created histogram
+	MaxStatsBuckets = 366
+	// MaxStatsLastNames is the most last names of the statistics
+	MaxStatsLastNames = 100
+)
+
+// UserStatsQuery is the range and buckets of the user statistics
+type UserStatsQuery struct {
+	// From and To are the range of the created histogram, From is included
+	From time.Time
+	To   time.Time
+	// Interval is the width of the buckets
+	Interval UserStatsInterval
+	// Location is the time zone of the days, weeks and months of the buckets
+	Location *time.Location
+	// LastNames is the number of the most common last names
+	LastNames int
+}
+
+// Buckets returns the bounds of the buckets that cover the range, the first bucket starts at or before From
+// and the last bucket ends at or after To.  There is one more bound than there are buckets.
+func (q *UserStatsQuery) Buckets() []time.Time {
+	from := q.From.In(q.Location)
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, q.Location)
+	switch q.Interval {
+	case UserStatsWeek:
+		start = start.AddDate(0, 0, -(int(start.Weekday())+6)%7)
+	case UserStatsMonth:
+		start = start.AddDate(0, 0, 1-start.Day())
+	}
+
+	bounds := []time.Time{start}
+	for start.Before(q.To) && len(bounds) <= MaxStatsBuckets+1 {
+		switch q.Interval {
+		case UserStatsWeek:
+			start = start.AddDate(0, 0, 7)
+		case UserStatsMonth:
+			start = start.AddDate(0, 1, 0)
+		default:
+			start = start.AddDate(0, 0, 1)
+		}
+		bounds = append(bounds, start)
+	}
+	return bounds
+}
+
+// UserStats are the aggregate statistics of the users
+type UserStats struct {
+	// Active are the users that have not been deleted, Statuses counts them by their status
+	Active   int                `json:"active"`
+	Deleted  int                `json:"deleted"`
+	Statuses map[UserStatus]int `json:"statuses"`
+	Interval UserStatsInterval  `json:"interval"`
+	Timezone string             `json:"timezone"`
+	// Created counts the users, deleted or not, by the bucket of their created time
+	Created []UserStatsBucket `json:"created"`
+	// LastNames are the most common last names of the active users
+	LastNames []UserStatsLastName `json:"top_last_names"`
+}
+
+// UserStatsBucket is a bucket of the created histogram
+type UserStatsBucket struct {
+	Start time.Time `json:"start"`
+	Count int       `json:"count"`
+}
+
+// UserStatsLastName is a last name and the number of users with it
+type UserStatsLastName struct {
+	LastName string `json:"last_name"`
+	Count    int    `json:"count"`
+}
@@ -0,0 +1,111 @@
+package model
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"time"
+
+	"github.com/g8rswimmer/go-data-access-example/pkg/errorx"
+)
+
+const (
+	maxEmailLength = 254
+	idLength       = 36
+)
+
+var (
+	usernamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{2,63}$`)
+	phonePattern    = regexp.MustCompile(`^\+[1-9][0-9]{1,14}$`)
+	localePattern   = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+)
+
+// Validate returns an error naming the first field that is not valid, empty fields are valid
+func (u *User) Validate() error {
+	switch {
+	case len(u.Email) > 0 && validEmail(u.Email) == false:
+		return fmt.Errorf("%w: email %q is not an address", errorx.ErrInvalidUser, u.Email)
+	case len(u.Username) > 0 && usernamePattern.MatchString(u.Username) == false:
+		return fmt.Errorf("%w: username %q must be 3 to 64 letters, digits, '.', '_' or '-'", errorx.ErrInvalidUser, u.Username)
+	case len(u.Phone) > 0 && phonePattern.MatchString(u.Phone) == false:
+		return fmt.Errorf("%w: phone %q is not an E.164 number", errorx.ErrInvalidUser, u.Phone)
+	case len(u.Status) > 0 && validStatus(u.Status) == false:
+		return fmt.Errorf("%w: status %q must be one of %v", errorx.ErrInvalidUser, u.Status, UserStatuses)
+	case len(u.Locale) > 0 && localePattern.MatchString(u.Locale) == false:
+		return fmt.Errorf("%w: locale %q is not a language tag", errorx.ErrInvalidUser, u.Locale)
+	case len(u.Timezone) > 0 && validTimezone(u.Timezone) == false:
+		return fmt.Errorf("%w: timezone %q is not an IANA time zone", errorx.ErrInvalidUser, u.Timezone)
+	case len(u.ManagerID) > 0 && len(u.ManagerID) != idLength:
+		return fmt.Errorf("%w: manager_id %q is not an user id", errorx.ErrInvalidUser, u.ManagerID)
+	}
+	for k := range u.Attributes {
+		if len(k) == 0 {
+			return fmt.Errorf("%w: attributes can not have an empty key", errorx.ErrInvalidUser)
+		}
+	}
+	return nil
+}
+
+// Validate returns an error naming the first search field that is not valid
+func (s *UserSearch) Validate() error {
+	switch {
+	case len(s.Status) > 0 && validStatus(s.Status) == false:
+		return fmt.Errorf("%w: status %q must be one of %v", errorx.ErrInvalidSearch, s.Status, UserStatuses)
+	case s.CreatedAfter.IsZero() == false && s.CreatedBefore.IsZero() == false && s.CreatedBefore.After(s.CreatedAfter) == false:
+		return fmt.Errorf("%w: created_before must be after created_after", errorx.ErrInvalidSearch)
+	case s.Limit < 0 || s.Limit > MaxSearchLimit:
+		return fmt.Errorf("%w: limit %d must be from 1 to %d", errorx.ErrInvalidSearch, s.Limit, MaxSearchLimit)
+	}
+	return nil
+}
+
+// Validate returns an error naming the first statistics field that is not valid
+func (q *UserStatsQuery) Validate() error {
+	switch {
+	case validInterval(q.Interval) == false:
+		return fmt.Errorf("%w: interval %q must be one of %v", errorx.ErrInvalidStats, q.Interval, UserStatsIntervals)
+	case q.Location == nil:
+		return fmt.Errorf("%w: time zone is required", errorx.ErrInvalidStats)
+	case q.To.After(q.From) == false:
+		return fmt.Errorf("%w: to must be after from", errorx.ErrInvalidStats)
+	case q.LastNames < 0 || q.LastNames > MaxStatsLastNames:
+		return fmt.Errorf("%w: top %d must be from 0 to %d", errorx.ErrInvalidStats, q.LastNames, MaxStatsLastNames)
+	case len(q.Buckets()) > MaxStatsBuckets+1:
+		return fmt.Errorf("%w: the range has more than %d %s buckets", errorx.ErrInvalidStats, MaxStatsBuckets, q.Interval)
+	}
+	return nil
+}
+
+func validInterval(interval UserStatsInterval) bool {
+	for _, i := range UserStatsIntervals {
+		if i == interval {
+			return true
+		}
+	}
+	return false
+}
+
+func validEmail(email string) bool {
+	if len(email) > maxEmailLength {
+		return false
+	}
+	addr, err := mail.ParseAddress(email)
+	return err == nil && addr.Address == email
+}
+
+func validStatus(status UserStatus) bool {
+	for _, s := range UserStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+func validTimezone(tz string) bool {
+	if tz == "Local" {
+		return false
+	}
+	_, err := time.LoadLocation(tz)
+	return err == nil
+}
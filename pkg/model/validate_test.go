@@ -0,0 +1,295 @@
+package model
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/g8rswimmer/go-data-access-example/pkg/errorx"
+)
+
+func TestUser_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		user    User
+		wantErr bool
+	}{
+		{
+			name: "empty",
+			user: User{},
+		},
+		{
+			name: "valid",
+			user: User{
+				FirstName:  "test",
+				Email:      "Test.Testison@example.com",
+				Username:   "test_1",
+				Phone:      "+14155550123",
+				Status:     UserSuspended,
+				Locale:     "en-US",
+				Timezone:   "America/Chicago",
+				Attributes: Attributes{"team": "data"},
+			},
+		},
+		{
+			name:    "email display name",
+			user:    User{Email: "Test <test@example.com>"},
+			wantErr: true,
+		},
+		{
+			name:    "email no domain",
+			user:    User{Email: "test"},
+			wantErr: true,
+		},
+		{
+			name:    "username short",
+			user:    User{Username: "ab"},
+			wantErr: true,
+		},
+		{
+			name:    "username space",
+			user:    User{Username: "test user"},
+			wantErr: true,
+		},
+		{
+			name:    "phone national",
+			user:    User{Phone: "4155550123"},
+			wantErr: true,
+		},
+		{
+			name:    "phone long",
+			user:    User{Phone: "+1234567890123456"},
+			wantErr: true,
+		},
+		{
+			name:    "status",
+			user:    User{Status: "deleted"},
+			wantErr: true,
+		},
+		{
+			name:    "locale",
+			user:    User{Locale: "en_US"},
+			wantErr: true,
+		},
+		{
+			name:    "timezone",
+			user:    User{Timezone: "Mars/Olympus"},
+			wantErr: true,
+		},
+		{
+			name:    "manager id",
+			user:    User{ManagerID: "1234"},
+			wantErr: true,
+		},
+		{
+			name:    "attributes key",
+			user:    User{Attributes: Attributes{"": 1}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.user.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("User.Validate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr && errors.Is(err, errorx.ErrInvalidUser) == false {
+				t.Errorf("User.Validate() error = %v, want %v", err, errorx.ErrInvalidUser)
+			}
+		})
+	}
+}
+
+func TestUserSearch_Validate(t *testing.T) {
+	day := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		search  UserSearch
+		wantErr bool
+	}{
+		{
+			name:   "empty",
+			search: UserSearch{},
+		},
+		{
+			name: "valid",
+			search: UserSearch{
+				Status:        UserInactive,
+				CreatedAfter:  day,
+				CreatedBefore: day.AddDate(0, 1, 0),
+				Limit:         MaxSearchLimit,
+			},
+		},
+		{
+			name:    "status",
+			search:  UserSearch{Status: "deleted"},
+			wantErr: true,
+		},
+		{
+			name:    "created range",
+			search:  UserSearch{CreatedAfter: day, CreatedBefore: day},
+			wantErr: true,
+		},
+		{
+			name:    "limit negative",
+			search:  UserSearch{Limit: -1},
+			wantErr: true,
+		},
+		{
+			name:    "limit large",
+			search:  UserSearch{Limit: MaxSearchLimit + 1},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.search.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("UserSearch.Validate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr && errors.Is(err, errorx.ErrInvalidSearch) == false {
+				t.Errorf("UserSearch.Validate() error = %v, want %v", err, errorx.ErrInvalidSearch)
+			}
+		})
+	}
+}
+
+func TestUserStatsQuery_Validate(t *testing.T) {
+	day := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		query   UserStatsQuery
+		wantErr bool
+	}{
+		{
+			name:  "valid",
+			query: UserStatsQuery{From: day, To: day.AddDate(1, 0, 0), Interval: UserStatsDay, Location: time.UTC, LastNames: 10},
+		},
+		{
+			name:    "interval",
+			query:   UserStatsQuery{From: day, To: day.AddDate(0, 1, 0), Interval: "year", Location: time.UTC},
+			wantErr: true,
+		},
+		{
+			name:    "location",
+			query:   UserStatsQuery{From: day, To: day.AddDate(0, 1, 0), Interval: UserStatsDay},
+			wantErr: true,
+		},
+		{
+			name:    "range",
+			query:   UserStatsQuery{From: day, To: day, Interval: UserStatsDay, Location: time.UTC},
+			wantErr: true,
+		},
+		{
+			name:    "last names",
+			query:   UserStatsQuery{From: day, To: day.AddDate(0, 1, 0), Interval: UserStatsDay, Location: time.UTC, LastNames: MaxStatsLastNames + 1},
+			wantErr: true,
+		},
+		{
+			name:    "buckets",
+			query:   UserStatsQuery{From: day, To: day.AddDate(1, 0, 2), Interval: UserStatsDay, Location: time.UTC},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.query.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("UserStatsQuery.Validate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr && errors.Is(err, errorx.ErrInvalidStats) == false {
+				t.Errorf("UserStatsQuery.Validate() error = %v, want %v", err, errorx.ErrInvalidStats)
+			}
+		})
+	}
+}
+
+func TestUserStatsQuery_Buckets(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		t.Skipf("time zone database error %v", err)
+	}
+	tests := []struct {
+		name  string
+		query UserStatsQuery
+		want  []string
+	}{
+		{
+			name: "days across daylight saving",
+			query: UserStatsQuery{
+				From:     time.Date(2026, 3, 7, 12, 0, 0, 0, chicago),
+				To:       time.Date(2026, 3, 9, 0, 0, 0, 0, chicago),
+				Interval: UserStatsDay,
+				Location: chicago,
+			},
+			want: []string{"2026-03-07T06:00:00Z", "2026-03-08T06:00:00Z", "2026-03-09T05:00:00Z"},
+		},
+		{
+			name: "weeks start on monday",
+			query: UserStatsQuery{
+				From:     time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+				To:       time.Date(2026, 1, 6, 0, 0, 0, 0, time.UTC),
+				Interval: UserStatsWeek,
+				Location: time.UTC,
+			},
+			want: []string{"2025-12-29T00:00:00Z", "2026-01-05T00:00:00Z", "2026-01-12T00:00:00Z"},
+		},
+		{
+			name: "months in the location",
+			query: UserStatsQuery{
+				From:     time.Date(2026, 2, 1, 3, 0, 0, 0, time.UTC),
+				To:       time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+				Interval: UserStatsMonth,
+				Location: chicago,
+			},
+			want: []string{"2026-01-01T06:00:00Z", "2026-02-01T06:00:00Z", "2026-03-01T06:00:00Z"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, b := range tt.query.Buckets() {
+				got = append(got, b.UTC().Format(time.RFC3339))
+			}
+			if reflect.DeepEqual(got, tt.want) == false {
+				t.Errorf("UserStatsQuery.Buckets() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrganizationKind_CanParent(t *testing.T) {
+	tests := []struct {
+		name    string
+		kind    OrganizationKind
+		parent  OrganizationKind
+		wantErr bool
+	}{
+		{name: "company root", kind: OrganizationCompany},
+		{name: "company within company", kind: OrganizationCompany, parent: OrganizationCompany, wantErr: true},
+		{name: "department root", kind: OrganizationDepartment, wantErr: true},
+		{name: "department within company", kind: OrganizationDepartment, parent: OrganizationCompany},
+		{name: "department within department", kind: OrganizationDepartment, parent: OrganizationDepartment},
+		{name: "department within team", kind: OrganizationDepartment, parent: OrganizationTeam, wantErr: true},
+		{name: "team root", kind: OrganizationTeam, wantErr: true},
+		{name: "team within company", kind: OrganizationTeam, parent: OrganizationCompany, wantErr: true},
+		{name: "team within department", kind: OrganizationTeam, parent: OrganizationDepartment},
+		{name: "team within team", kind: OrganizationTeam, parent: OrganizationTeam},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.kind.CanParent(tt.parent)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("OrganizationKind.CanParent() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr && errors.Is(err, errorx.ErrInvalidOrganization) == false {
+				t.Errorf("OrganizationKind.CanParent() error = %v, want %v", err, errorx.ErrInvalidOrganization)
+			}
+		})
+	}
+}
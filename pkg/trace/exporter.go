@@ -0,0 +1,63 @@
+package trace
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// Exporter receives the finished spans
+type Exporter interface {
+	Export(span *SpanData)
+}
+
+// InMemoryExporter keeps the finished spans, useful for tests
+type InMemoryExporter struct {
+	mu    sync.Mutex
+	spans []*SpanData
+}
+
+// Export will store the span
+func (e *InMemoryExporter) Export(span *SpanData) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, span)
+}
+
+// Spans returns the spans in the order they finished
+func (e *InMemoryExporter) Spans() []*SpanData {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]*SpanData{}, e.spans...)
+}
+
+// Reset will remove all of the stored spans
+func (e *InMemoryExporter) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = nil
+}
+
+// StdoutExporter writes each finished span as a json line
+type StdoutExporter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewStdoutExporter creates an exporter writing to w
+func NewStdoutExporter(w io.Writer) *StdoutExporter {
+	return &StdoutExporter{
+		w: w,
+	}
+}
+
+// Export will write the span
+func (e *StdoutExporter) Export(span *SpanData) {
+	enc, err := json.Marshal(span)
+	if err != nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, _ = e.w.Write(append(enc, '\n'))
+}
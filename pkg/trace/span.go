@@ -0,0 +1,135 @@
+package trace
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Span is a timed operation within a trace
+type Span struct {
+	tracer *Tracer
+
+	mu         sync.Mutex
+	name       string
+	sc         SpanContext
+	parent     SpanID
+	start      time.Time
+	end        time.Time
+	attributes map[string]interface{}
+	err        error
+	ended      bool
+}
+
+// SpanData is the finished span handed to the exporter
+type SpanData struct {
+	Name       string                 `json:"name"`
+	TraceID    string                 `json:"trace_id"`
+	SpanID     string                 `json:"span_id"`
+	ParentID   string                 `json:"parent_id,omitempty"`
+	Start      time.Time              `json:"start"`
+	End        time.Time              `json:"end"`
+	Duration   time.Duration          `json:"duration_ns"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+}
+
+// SpanContext returns the identity of the span, a nil span has none
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.sc
+}
+
+// SetName will rename the span
+func (s *Span) SetName(name string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.name = name
+}
+
+// SetAttribute will add the key value to the span
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attributes[key] = value
+}
+
+// SetError will mark the span as failed
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+// End will finish the span and export it, only the first call has any effect
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.end = time.Now()
+	data := s.data()
+	s.mu.Unlock()
+
+	if s.sc.Sampled {
+		s.tracer.exporter.Export(data)
+	}
+}
+
+func (s *Span) data() *SpanData {
+	d := &SpanData{
+		Name:       s.name,
+		TraceID:    s.sc.TraceID.String(),
+		SpanID:     s.sc.SpanID.String(),
+		Start:      s.start,
+		End:        s.end,
+		Duration:   s.end.Sub(s.start),
+		Attributes: make(map[string]interface{}, len(s.attributes)),
+	}
+	if s.parent.IsValid() {
+		d.ParentID = s.parent.String()
+	}
+	for k, v := range s.attributes {
+		d.Attributes[k] = v
+	}
+	if s.err != nil {
+		d.Error = s.err.Error()
+	}
+	return d
+}
+
+type spanKey struct{}
+
+type remoteKey struct{}
+
+// SpanFromContext returns the current span, nil when there is none
+func SpanFromContext(ctx context.Context) *Span {
+	s, _ := ctx.Value(spanKey{}).(*Span)
+	return s
+}
+
+// ContextWithRemoteParent returns a context whose next span continues the remote trace
+func ContextWithRemoteParent(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, remoteKey{}, sc)
+}
+
+func remoteParent(ctx context.Context) (SpanContext, bool) {
+	sc, ok := ctx.Value(remoteKey{}).(SpanContext)
+	return sc, ok && sc.IsValid()
+}
@@ -0,0 +1,143 @@
+package trace
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		want    string
+		sampled bool
+		wantErr bool
+	}{
+		{
+			name:    "sampled",
+			header:  "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			want:    "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			sampled: true,
+		},
+		{
+			name:   "not sampled",
+			header: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00",
+			want:   "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00",
+		},
+		{
+			name:    "future version with extra fields",
+			header:  "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+			want:    "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			sampled: true,
+		},
+		{
+			name:    "zero trace id",
+			header:  "00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+			wantErr: true,
+		},
+		{
+			name:    "upper case",
+			header:  "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+			wantErr: true,
+		},
+		{
+			name:    "invalid version",
+			header:  "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			wantErr: true,
+		},
+		{
+			name:    "empty",
+			header:  "",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTraceparent(tt.header)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseTraceparent() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				if errors.Is(err, ErrTraceparent) == false {
+					t.Errorf("ParseTraceparent() error = %v, want %v", err, ErrTraceparent)
+				}
+				return
+			}
+			if got.Traceparent() != tt.want {
+				t.Errorf("ParseTraceparent() = %v, want %v", got.Traceparent(), tt.want)
+			}
+			if got.Sampled != tt.sampled {
+				t.Errorf("ParseTraceparent() sampled = %v, want %v", got.Sampled, tt.sampled)
+			}
+		})
+	}
+}
+
+func TestTracer_Start(t *testing.T) {
+	exporter := &InMemoryExporter{}
+	tracer := NewTracer(exporter)
+
+	remote, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := ContextWithRemoteParent(context.Background(), remote)
+
+	ctx, parent := tracer.Start(ctx, "parent")
+	_, child := Start(ctx, "child")
+	child.SetAttribute("db.statement", "SELECT 1")
+	child.SetError(errors.New("oops"))
+	child.End()
+	parent.End()
+	parent.End()
+
+	spans := exporter.Spans()
+	if len(spans) != 2 {
+		t.Errorf("Tracer.Start() spans = %d, want 2", len(spans))
+		return
+	}
+	got, root := spans[0], spans[1]
+	switch {
+	case root.TraceID != remote.TraceID.String():
+		t.Errorf("Tracer.Start() root trace id = %v, want %v", root.TraceID, remote.TraceID)
+	case root.ParentID != remote.SpanID.String():
+		t.Errorf("Tracer.Start() root parent id = %v, want %v", root.ParentID, remote.SpanID)
+	case got.TraceID != root.TraceID:
+		t.Errorf("Tracer.Start() child trace id = %v, want %v", got.TraceID, root.TraceID)
+	case got.ParentID != root.SpanID:
+		t.Errorf("Tracer.Start() child parent id = %v, want %v", got.ParentID, root.SpanID)
+	case got.Attributes["db.statement"] != "SELECT 1":
+		t.Errorf("Tracer.Start() child attributes = %v", got.Attributes)
+	case got.Error != "oops":
+		t.Errorf("Tracer.Start() child error = %v, want oops", got.Error)
+	default:
+	}
+}
+
+func TestStart_NoTracer(t *testing.T) {
+	ctx, span := Start(context.Background(), "nothing")
+	span.SetAttribute("a", "b")
+	span.End()
+
+	if span != nil || SpanFromContext(ctx) != nil {
+		t.Errorf("Start() without a tracer should not create a span")
+	}
+}
+
+func TestStdoutExporter_Export(t *testing.T) {
+	buf := &bytes.Buffer{}
+	tracer := NewTracer(NewStdoutExporter(buf))
+
+	_, span := tracer.Start(context.Background(), "write")
+	span.End()
+
+	got := &SpanData{}
+	if err := json.Unmarshal(buf.Bytes(), got); err != nil {
+		t.Errorf("StdoutExporter.Export() decode error %v", err)
+		return
+	}
+	if got.Name != "write" {
+		t.Errorf("StdoutExporter.Export() name = %v, want write", got.Name)
+	}
+}
@@ -0,0 +1,121 @@
+package trace
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// TraceID identifies a trace
+type TraceID [16]byte
+
+// SpanID identifies a span within a trace
+type SpanID [8]byte
+
+// IsValid returns if the trace id is not all zeros
+func (t TraceID) IsValid() bool {
+	return t != TraceID{}
+}
+
+func (t TraceID) String() string {
+	return hex.EncodeToString(t[:])
+}
+
+// IsValid returns if the span id is not all zeros
+func (s SpanID) IsValid() bool {
+	return s != SpanID{}
+}
+
+func (s SpanID) String() string {
+	return hex.EncodeToString(s[:])
+}
+
+// SpanContext is the propagated identity of a span
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+// IsValid returns if both of the ids are present
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// TraceparentHeader is the W3C trace context header
+const TraceparentHeader = "traceparent"
+
+const traceparentVersion = "00"
+
+// Traceparent renders the span context as a W3C traceparent header value
+func (sc SpanContext) Traceparent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return fmt.Sprintf("%s-%s-%s-%s", traceparentVersion, sc.TraceID, sc.SpanID, flags)
+}
+
+// ErrTraceparent is returned when a traceparent header can not be parsed
+var ErrTraceparent = errors.New("invalid traceparent")
+
+// ParseTraceparent parses a W3C traceparent header value
+func ParseTraceparent(header string) (SpanContext, error) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 {
+		return SpanContext{}, fmt.Errorf("%w: %d parts", ErrTraceparent, len(parts))
+	}
+	version, traceID, spanID, flags := parts[0], parts[1], parts[2], parts[3]
+
+	switch {
+	case len(version) != 2 || version == "ff":
+		return SpanContext{}, fmt.Errorf("%w: version %s", ErrTraceparent, version)
+	case version == traceparentVersion && len(parts) != 4:
+		return SpanContext{}, fmt.Errorf("%w: %d parts", ErrTraceparent, len(parts))
+	case len(traceID) != 32 || len(spanID) != 16 || len(flags) != 2:
+		return SpanContext{}, fmt.Errorf("%w: field length", ErrTraceparent)
+	default:
+	}
+
+	sc := SpanContext{}
+	if err := decodeHex(sc.TraceID[:], traceID); err != nil {
+		return SpanContext{}, err
+	}
+	if err := decodeHex(sc.SpanID[:], spanID); err != nil {
+		return SpanContext{}, err
+	}
+	f := make([]byte, 1)
+	if err := decodeHex(f, flags); err != nil {
+		return SpanContext{}, err
+	}
+	sc.Sampled = f[0]&0x01 == 0x01
+
+	if sc.IsValid() == false {
+		return SpanContext{}, fmt.Errorf("%w: zero id", ErrTraceparent)
+	}
+	return sc, nil
+}
+
+func decodeHex(dst []byte, s string) error {
+	if strings.ToLower(s) != s {
+		return fmt.Errorf("%w: upper case hex %s", ErrTraceparent, s)
+	}
+	if _, err := hex.Decode(dst, []byte(s)); err != nil {
+		return fmt.Errorf("%w: %s", ErrTraceparent, err.Error())
+	}
+	return nil
+}
+
+func newTraceID() TraceID {
+	id := TraceID{}
+	_, _ = rand.Read(id[:])
+	return id
+}
+
+func newSpanID() SpanID {
+	id := SpanID{}
+	_, _ = rand.Read(id[:])
+	return id
+}
@@ -0,0 +1,67 @@
+package trace
+
+import (
+	"context"
+	"time"
+)
+
+// Tracer starts spans and hands the finished spans to the exporter
+type Tracer struct {
+	exporter Exporter
+}
+
+// NewTracer creates a tracer that exports to the exporter
+func NewTracer(exporter Exporter) *Tracer {
+	return &Tracer{
+		exporter: exporter,
+	}
+}
+
+// Start creates a span that is a child of the span in the context
+func (t *Tracer) Start(ctx context.Context, name string) (context.Context, *Span) {
+	if t == nil {
+		return ctx, nil
+	}
+
+	s := &Span{
+		tracer:     t,
+		name:       name,
+		start:      time.Now(),
+		attributes: map[string]interface{}{},
+	}
+
+	switch parent := SpanFromContext(ctx); {
+	case parent != nil:
+		s.sc = SpanContext{TraceID: parent.sc.TraceID, Sampled: parent.sc.Sampled}
+		s.parent = parent.sc.SpanID
+	default:
+		if remote, ok := remoteParent(ctx); ok {
+			s.sc = SpanContext{TraceID: remote.TraceID, Sampled: remote.Sampled}
+			s.parent = remote.SpanID
+		} else {
+			s.sc = SpanContext{TraceID: newTraceID(), Sampled: true}
+		}
+	}
+	s.sc.SpanID = newSpanID()
+
+	ctx = context.WithValue(ctx, tracerKey{}, t)
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+type tracerKey struct{}
+
+// NewContext returns a context carrying the tracer
+func NewContext(ctx context.Context, t *Tracer) context.Context {
+	return context.WithValue(ctx, tracerKey{}, t)
+}
+
+// FromContext returns the tracer in the context, nil when there is none
+func FromContext(ctx context.Context) *Tracer {
+	t, _ := ctx.Value(tracerKey{}).(*Tracer)
+	return t
+}
+
+// Start creates a span with the tracer in the context, the span is nil when there is no tracer
+func Start(ctx context.Context, name string) (context.Context, *Span) {
+	return FromContext(ctx).Start(ctx, name)
+}